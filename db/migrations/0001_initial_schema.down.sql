@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS on_exercise_delete;
+DROP TABLE IF EXISTS "workout_sets";
+DROP TABLE IF EXISTS "workouts";
+DROP TABLE IF EXISTS "exercises";
+DROP TABLE IF EXISTS "images";
+DROP TABLE IF EXISTS "splits";
+DROP TABLE IF EXISTS "users";
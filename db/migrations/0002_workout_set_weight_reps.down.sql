@@ -0,0 +1,2 @@
+ALTER TABLE "workout_sets" DROP COLUMN [Reps];
+ALTER TABLE "workout_sets" DROP COLUMN [Weight];
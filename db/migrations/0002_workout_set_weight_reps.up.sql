@@ -0,0 +1,2 @@
+ALTER TABLE "workout_sets" ADD COLUMN [Weight] FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE "workout_sets" ADD COLUMN [Reps] INTEGER NOT NULL DEFAULT 0;
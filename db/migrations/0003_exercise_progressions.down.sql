@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "exercise_progressions";
+ALTER TABLE "users" DROP COLUMN [AutoProgression];
@@ -0,0 +1,14 @@
+ALTER TABLE "users" ADD COLUMN [AutoProgression] INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS "exercise_progressions" (
+   [ID] INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+   [ExerciseID] INTEGER NOT NULL REFERENCES [exercises]([ID]) ON DELETE CASCADE,
+   [WorkoutID] INTEGER NOT NULL REFERENCES [workouts]([ID]) ON DELETE CASCADE,
+   [WeightFrom] FLOAT NOT NULL DEFAULT 0,
+   [WeightTo] FLOAT NOT NULL DEFAULT 0,
+   [RepsFrom] INTEGER NOT NULL DEFAULT 0,
+   [RepsTo] INTEGER NOT NULL DEFAULT 0,
+   [Reason] TEXT NOT NULL,
+   [Status] TEXT NOT NULL DEFAULT "pending",
+   [CreatedAt] TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   [DecidedAt] TIMESTAMP
+);
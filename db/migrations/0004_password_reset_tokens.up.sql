@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+   [ID] INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+   [UserID] INTEGER NOT NULL REFERENCES [users]([ID]) ON DELETE CASCADE,
+   [TokenHash] TEXT NOT NULL UNIQUE,
+   [CreatedAt] TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   [ExpiresAt] TIMESTAMP NOT NULL,
+   [UsedAt] TIMESTAMP
+);
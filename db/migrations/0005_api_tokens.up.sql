@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS "api_tokens" (
+   [ID] INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+   [UserID] INTEGER NOT NULL REFERENCES [users]([ID]) ON DELETE CASCADE,
+   [Name] TEXT NOT NULL,
+   [TokenHash] TEXT NOT NULL UNIQUE,
+   [Scope] TEXT NOT NULL DEFAULT 'write',
+   [CreatedAt] TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   [LastUsedAt] TIMESTAMP
+);
@@ -0,0 +1,30 @@
+-- Every library exercise goes back to the first split it is used in, with the targets it has there.
+-- Exercises used in more than one split lose their place in the other splits.
+CREATE TABLE "exercises_split" (
+   [ID] INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+   [SplitID] INTEGER REFERENCES [splits]([ID]) ON DELETE CASCADE,
+   [Name] TEXT NOT NULL,
+   [Description] TEXT NOT NULL,
+   [ImageID] INTEGER DEFAULT 0 REFERENCES [images]([ID]) ON DELETE SET DEFAULT,
+   [WeightFrom] FLOAT NOT NULL DEFAULT 0,
+   [WeightTo] FLOAT NOT NULL DEFAULT 0,
+   [RepsFrom] INTEGER NOT NULL DEFAULT 0,
+   [RepsTo] INTEGER NOT NULL DEFAULT 0,
+   [Sets] INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO exercises_split (ID, SplitID, Name, Description, ImageID, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets)
+SELECT e.ID, se.SplitID, e.Name, e.Description, e.ImageID,
+   COALESCE(se.WeightFrom, 0), COALESCE(se.WeightTo, 0), COALESCE(se.RepsFrom, 0), COALESCE(se.RepsTo, 0), COALESCE(se.Sets, 0)
+FROM exercises e
+LEFT JOIN split_exercises se ON se.ID = (
+   SELECT ID FROM split_exercises WHERE ExerciseID = e.ID ORDER BY Position, ID LIMIT 1
+);
+
+DROP TABLE split_exercises;
+DROP TABLE exercises;
+ALTER TABLE exercises_split RENAME TO exercises;
+
+CREATE TRIGGER IF NOT EXISTS on_exercise_delete AFTER DELETE ON exercises BEGIN
+  DELETE FROM images WHERE ID = old.ImageID;
+END;
@@ -0,0 +1,103 @@
+-- Exercises become a library per user, splits reference them through split_exercises which holds the
+-- targets and order the exercise has in that split.
+CREATE TABLE IF NOT EXISTS "split_exercises" (
+   [ID] INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+   [SplitID] INTEGER NOT NULL REFERENCES [splits]([ID]) ON DELETE CASCADE,
+   [ExerciseID] INTEGER NOT NULL REFERENCES [exercises]([ID]) ON DELETE CASCADE,
+   [Position] INTEGER NOT NULL DEFAULT 0,
+   [WeightFrom] FLOAT NOT NULL DEFAULT 0,
+   [WeightTo] FLOAT NOT NULL DEFAULT 0,
+   [RepsFrom] INTEGER NOT NULL DEFAULT 0,
+   [RepsTo] INTEGER NOT NULL DEFAULT 0,
+   [Sets] INTEGER NOT NULL DEFAULT 0,
+   UNIQUE (SplitID, ExerciseID)
+);
+
+-- The user of every exercise, an exercise without a split belongs to the user who logged sets of it.
+CREATE TEMP TABLE "exercise_users" AS
+SELECT e.ID AS ExerciseID, COALESCE(s.UserID, (
+   SELECT w.UserID FROM workout_sets ws
+   INNER JOIN workouts w ON w.ID = ws.WorkoutID
+   WHERE ws.ExerciseID = e.ID
+   LIMIT 1
+)) AS UserID
+FROM exercises e
+LEFT JOIN splits s ON s.ID = e.SplitID;
+
+-- Exercises with the same name are merged into the oldest one of the user.
+CREATE TEMP TABLE "exercise_merges" AS
+SELECT e.ExerciseID AS OldID, (
+   SELECT MIN(c.ExerciseID) FROM exercise_users c
+   INNER JOIN exercises ce ON ce.ID = c.ExerciseID
+   WHERE c.UserID = e.UserID AND lower(trim(ce.Name)) = lower(trim(x.Name))
+) AS NewID
+FROM exercise_users e
+INNER JOIN exercises x ON x.ID = e.ExerciseID
+WHERE e.UserID IS NOT NULL;
+
+INSERT OR IGNORE INTO split_exercises (SplitID, ExerciseID, Position, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets)
+SELECT e.SplitID, m.NewID, e.ID, e.WeightFrom, e.WeightTo, e.RepsFrom, e.RepsTo, e.Sets
+FROM exercises e
+INNER JOIN exercise_merges m ON m.OldID = e.ID
+WHERE e.SplitID IS NOT NULL
+ORDER BY e.ID;
+
+-- A workout can have sets of several of the merged exercises, their sets are numbered after the sets of the
+-- exercises merged before them. The numbers go through negative ones so no two sets have the same number
+-- while they are updated.
+CREATE TEMP TABLE "merged_set_counts" AS
+SELECT ws.WorkoutID, ws.ExerciseID, m.NewID, MAX(ws.SetNumber) AS LastSet
+FROM workout_sets ws
+INNER JOIN exercise_merges m ON m.OldID = ws.ExerciseID
+GROUP BY ws.WorkoutID, ws.ExerciseID;
+
+UPDATE workout_sets
+SET SetNumber = -(SetNumber + (
+   SELECT COALESCE(SUM(p.LastSet), 0) FROM merged_set_counts p
+   INNER JOIN merged_set_counts c ON c.WorkoutID = p.WorkoutID AND c.NewID = p.NewID
+   WHERE c.WorkoutID = workout_sets.WorkoutID AND c.ExerciseID = workout_sets.ExerciseID AND p.ExerciseID < c.ExerciseID
+))
+WHERE ExerciseID IN (SELECT OldID FROM exercise_merges WHERE OldID <> NewID);
+
+UPDATE workout_sets
+SET SetNumber = -SetNumber,
+   ExerciseID = (SELECT NewID FROM exercise_merges WHERE OldID = ExerciseID)
+WHERE SetNumber < 0;
+
+UPDATE exercise_progressions
+SET ExerciseID = (SELECT NewID FROM exercise_merges WHERE OldID = ExerciseID)
+WHERE ExerciseID IN (SELECT OldID FROM exercise_merges WHERE OldID <> NewID);
+
+CREATE TABLE "exercises_library" (
+   [ID] INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+   [UserID] INTEGER NOT NULL REFERENCES [users]([ID]) ON DELETE CASCADE,
+   [Name] TEXT NOT NULL,
+   [Description] TEXT NOT NULL,
+   [ImageID] INTEGER DEFAULT 0 REFERENCES [images]([ID]) ON DELETE SET DEFAULT,
+   [MuscleGroups] TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO exercises_library (ID, UserID, Name, Description, ImageID)
+SELECT e.ID, u.UserID, e.Name, e.Description, e.ImageID
+FROM exercises e
+INNER JOIN exercise_users u ON u.ExerciseID = e.ID
+WHERE e.ID IN (SELECT NewID FROM exercise_merges);
+
+-- Exercises without a split that were never logged belong to no one and are left out with their progressions
+-- and images, as are the images of the merged exercises.
+DELETE FROM exercise_progressions WHERE ExerciseID NOT IN (SELECT ID FROM exercises_library);
+DELETE FROM images WHERE ID IN (
+   SELECT ImageID FROM exercises WHERE ID NOT IN (SELECT ID FROM exercises_library)
+);
+
+DROP TABLE exercises;
+ALTER TABLE exercises_library RENAME TO exercises;
+DROP TABLE exercise_users;
+DROP TABLE exercise_merges;
+DROP TABLE merged_set_counts;
+
+CREATE TRIGGER IF NOT EXISTS on_exercise_delete AFTER DELETE ON exercises BEGIN
+  DELETE FROM images WHERE ID = old.ImageID;
+END;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "exercises_user_name" ON "exercises" (UserID, lower(trim(Name)));
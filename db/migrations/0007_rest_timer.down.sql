@@ -0,0 +1,3 @@
+ALTER TABLE "workout_sets" DROP COLUMN [RestSeconds];
+ALTER TABLE "workout_sets" DROP COLUMN [RestEndsAt];
+ALTER TABLE "split_exercises" DROP COLUMN [RestSeconds];
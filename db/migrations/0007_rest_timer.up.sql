@@ -0,0 +1,7 @@
+-- The rest between sets of an exercise in a split, 0 turns the rest timer off.
+ALTER TABLE "split_exercises" ADD COLUMN [RestSeconds] INTEGER NOT NULL DEFAULT 90;
+
+-- RestEndsAt is when the rest before the set is over, RestSeconds is how long the rest actually was
+-- and stays NULL until the rest is over.
+ALTER TABLE "workout_sets" ADD COLUMN [RestEndsAt] TIMESTAMP;
+ALTER TABLE "workout_sets" ADD COLUMN [RestSeconds] INTEGER;
@@ -0,0 +1 @@
+ALTER TABLE "split_exercises" DROP COLUMN [GroupNumber];
@@ -0,0 +1,3 @@
+-- Exercises of a split with the same non-zero GroupNumber are a superset, or a circuit when there are
+-- more than two of them, and take turns set by set in a workout.
+ALTER TABLE "split_exercises" ADD COLUMN [GroupNumber] INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE "workout_sets" DROP COLUMN [SetType];
@@ -0,0 +1,2 @@
+-- Warm-up and drop sets are logged on top of the Sets of the exercise, the other types count against them.
+ALTER TABLE "workout_sets" ADD COLUMN [SetType] TEXT NOT NULL DEFAULT "working";
@@ -0,0 +1,7 @@
+ALTER TABLE "workout_sets" DROP COLUMN [Distance];
+ALTER TABLE "workout_sets" DROP COLUMN [Seconds];
+ALTER TABLE "split_exercises" DROP COLUMN [DistanceTo];
+ALTER TABLE "split_exercises" DROP COLUMN [DistanceFrom];
+ALTER TABLE "split_exercises" DROP COLUMN [SecondsTo];
+ALTER TABLE "split_exercises" DROP COLUMN [SecondsFrom];
+ALTER TABLE "exercises" DROP COLUMN [Measurement];
@@ -0,0 +1,11 @@
+-- How sets of the exercise are measured: weight_reps, bodyweight_reps, duration or distance.
+ALTER TABLE "exercises" ADD COLUMN [Measurement] TEXT NOT NULL DEFAULT "weight_reps";
+
+-- Targets of timed exercises in seconds and of distance exercises in meters.
+ALTER TABLE "split_exercises" ADD COLUMN [SecondsFrom] INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "split_exercises" ADD COLUMN [SecondsTo] INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "split_exercises" ADD COLUMN [DistanceFrom] FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE "split_exercises" ADD COLUMN [DistanceTo] FLOAT NOT NULL DEFAULT 0;
+
+ALTER TABLE "workout_sets" ADD COLUMN [Seconds] INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "workout_sets" ADD COLUMN [Distance] FLOAT NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- The remaining sets of an exercise that was skipped in a workout are not done, the exercise counts as done.
+CREATE TABLE IF NOT EXISTS "skipped_exercises" (
+   [WorkoutID] INTEGER NOT NULL REFERENCES [workouts]([ID]) ON DELETE CASCADE,
+   [ExerciseID] INTEGER NOT NULL REFERENCES [exercises]([ID]) ON DELETE CASCADE,
+   [SkippedAt] TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   PRIMARY KEY (WorkoutID, ExerciseID)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "personal_records_workout";
+DROP INDEX IF EXISTS "personal_records_exercise";
+DROP TABLE IF EXISTS "personal_records";
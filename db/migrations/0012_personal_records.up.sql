@@ -0,0 +1,17 @@
+-- Every time a set or a session beat the best of its exercise. PreviousValue is the best it beat, it is
+-- NULL for the first record of a kind. SetNumber is NULL for session volume records.
+CREATE TABLE IF NOT EXISTS "personal_records" (
+   [ID] INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+   [UserID] INTEGER NOT NULL REFERENCES [users]([ID]) ON DELETE CASCADE,
+   [ExerciseID] INTEGER NOT NULL REFERENCES [exercises]([ID]) ON DELETE CASCADE,
+   [WorkoutID] INTEGER NOT NULL REFERENCES [workouts]([ID]) ON DELETE CASCADE,
+   [SetNumber] INTEGER,
+   [RecordType] TEXT NOT NULL,
+   [Weight] FLOAT NOT NULL DEFAULT 0,
+   [Reps] INTEGER NOT NULL DEFAULT 0,
+   [Value] FLOAT NOT NULL,
+   [PreviousValue] FLOAT,
+   [AchievedAt] TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS "personal_records_exercise" ON "personal_records" ([UserID], [ExerciseID]);
+CREATE INDEX IF NOT EXISTS "personal_records_workout" ON "personal_records" ([WorkoutID]);
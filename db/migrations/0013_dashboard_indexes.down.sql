@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "workout_sets_exercise";
+DROP INDEX IF EXISTS "workout_sets_workout";
+DROP INDEX IF EXISTS "workouts_user_started";
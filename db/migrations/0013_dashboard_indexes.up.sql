@@ -0,0 +1,5 @@
+-- Workouts are looked up by user and ordered by when they started, sets by workout and by exercise. The
+-- primary key of workout_sets starts with SetNumber so it is no help for either.
+CREATE INDEX IF NOT EXISTS "workouts_user_started" ON "workouts" ([UserID], [StartedAt]);
+CREATE INDEX IF NOT EXISTS "workout_sets_workout" ON "workout_sets" ([WorkoutID]);
+CREATE INDEX IF NOT EXISTS "workout_sets_exercise" ON "workout_sets" ([ExerciseID], [SetType], [SetRating]);
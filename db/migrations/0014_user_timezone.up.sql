@@ -0,0 +1,2 @@
+-- IANA name of the time zone dates are shown and grouped in, empty until the browser reports it on login.
+ALTER TABLE "users" ADD COLUMN [Timezone] TEXT NOT NULL DEFAULT '';
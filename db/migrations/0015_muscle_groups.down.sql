@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "muscle_group_targets";
+DROP TABLE IF EXISTS "exercise_muscle_groups";
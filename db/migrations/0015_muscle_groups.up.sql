@@ -0,0 +1,56 @@
+-- The muscle groups an exercise trains, from the taxonomy in dto.MuscleGroups. Role is primary or
+-- secondary, sets count half towards secondary muscle groups.
+CREATE TABLE IF NOT EXISTS "exercise_muscle_groups" (
+   [ExerciseID] INTEGER NOT NULL REFERENCES [exercises]([ID]) ON DELETE CASCADE,
+   [MuscleGroup] TEXT NOT NULL,
+   [Role] TEXT NOT NULL,
+   PRIMARY KEY ([ExerciseID], [MuscleGroup])
+);
+
+-- The range of working sets a week the user aims for per muscle group, muscle groups without a row use
+-- the default range of the taxonomy.
+CREATE TABLE IF NOT EXISTS "muscle_group_targets" (
+   [UserID] INTEGER NOT NULL REFERENCES [users]([ID]) ON DELETE CASCADE,
+   [MuscleGroup] TEXT NOT NULL,
+   [SetsFrom] INTEGER NOT NULL,
+   [SetsTo] INTEGER NOT NULL,
+   PRIMARY KEY ([UserID], [MuscleGroup])
+);
+
+-- Tag the exercises from their comma separated muscle groups text, the first known muscle group is the
+-- primary one. Parts that are not in the taxonomy are left out.
+WITH RECURSIVE parts(ExerciseID, Position, Part, Rest) AS (
+   SELECT ID, 0, '', MuscleGroups || ',' FROM exercises WHERE trim(MuscleGroups) <> ''
+   UNION ALL
+   SELECT ExerciseID, Position + 1, lower(trim(substr(Rest, 1, instr(Rest, ',') - 1))), substr(Rest, instr(Rest, ',') + 1)
+   FROM parts
+   WHERE Rest <> ''
+),
+names(Name, MuscleGroup) AS (
+   VALUES
+   ('chest', 'chest'), ('pecs', 'chest'),
+   ('back', 'back'), ('lats', 'back'), ('upper back', 'back'),
+   ('traps', 'traps'), ('trapezius', 'traps'),
+   ('front delts', 'front_delts'), ('shoulders', 'front_delts'),
+   ('side delts', 'side_delts'),
+   ('rear delts', 'rear_delts'),
+   ('biceps', 'biceps'),
+   ('triceps', 'triceps'),
+   ('forearms', 'forearms'),
+   ('abs', 'abs'), ('core', 'abs'),
+   ('glutes', 'glutes'),
+   ('quads', 'quads'), ('quadriceps', 'quads'),
+   ('hamstrings', 'hamstrings'),
+   ('calves', 'calves')
+),
+matches(ExerciseID, Position, MuscleGroup) AS (
+   SELECT p.ExerciseID, p.Position, n.MuscleGroup
+   FROM parts p
+   INNER JOIN names n ON n.Name = p.Part
+)
+INSERT OR IGNORE INTO exercise_muscle_groups (ExerciseID, MuscleGroup, Role)
+SELECT ExerciseID, MuscleGroup, CASE WHEN Position = (
+   SELECT MIN(first.Position) FROM matches first WHERE first.ExerciseID = matches.ExerciseID
+) THEN 'primary' ELSE 'secondary' END
+FROM matches
+ORDER BY ExerciseID, Position;
@@ -0,0 +1,11 @@
+// Package migrations holds the numbered SQL files that build up the database schema.
+//
+// Every version has an up file and a down file named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Versions are applied in ascending order and recorded
+// in the schema_migrations table.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -2,15 +2,17 @@ module dumbbell
 
 go 1.21.6
 
-require github.com/google/uuid v1.5.0
+require (
+	github.com/google/uuid v1.5.0
+	github.com/gorilla/websocket v1.5.1
+	github.com/mattn/go-sqlite3 v1.14.19
+	github.com/michaeljs1990/sqlitestore v0.0.0-20210507162135-8585425bc864
+	golang.org/x/crypto v0.18.0
+)
 
 require (
 	github.com/gorilla/securecookie v1.1.2 // indirect
 	github.com/gorilla/sessions v1.2.2 // indirect
-	github.com/gorilla/websocket v1.5.1 // indirect
-	github.com/mattn/go-sqlite3 v1.14.19 // indirect
-	github.com/michaeljs1990/sqlitestore v0.0.0-20210507162135-8585425bc864 // indirect
-	golang.org/x/crypto v0.18.0 // indirect
 	golang.org/x/net v0.20.0 // indirect
 	golang.org/x/sys v0.16.0 // indirect
 	golang.org/x/term v0.16.0 // indirect
@@ -2,17 +2,34 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 const file string = "file:db/database.db?_foreign_keys=on"
 
+// Open opens the database without touching its schema.
+func Open() (*sql.DB, error) {
+	return sql.Open("sqlite3", file)
+}
+
+// NewDB opens the database and applies any pending migrations.
 func NewDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", file)
+	db, err := Open()
 	if err != nil {
 		return nil, err
 	}
 
+	if _, err = MigrateUp(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
+
+// OpenFile opens the database at path instead of the one of the server, for tools that need a database
+// of their own.
+func OpenFile(path string) (*sql.DB, error) {
+	return sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on", path))
+}
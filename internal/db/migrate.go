@@ -0,0 +1,270 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"dumbbell/db/migrations"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt sql.NullTime
+}
+
+var ErrorNoMigrationsApplied = errors.New("No migrations applied")
+
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS "schema_migrations" (
+		[Version] INTEGER NOT NULL PRIMARY KEY,
+		[Name] TEXT NOT NULL,
+		[AppliedAt] TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)
+	`)
+	return err
+}
+
+// LoadMigrations reads the embedded migration files ordered by version.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrations.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		if strings.HasSuffix(fileName, ".up.sql") {
+			direction = "up"
+		} else if strings.HasSuffix(fileName, ".down.sql") {
+			direction = "down"
+		} else {
+			continue
+		}
+
+		versionString, name, found := strings.Cut(strings.TrimSuffix(fileName, "."+direction+".sql"), "_")
+		if !found {
+			return nil, fmt.Errorf("Invalid migration file name: %s", fileName)
+		}
+
+		version, err := strconv.ParseInt(versionString, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid migration version: %s", fileName)
+		}
+
+		content, err := fs.ReadFile(migrations.FS, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	result := []Migration{}
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("Migration %04d_%s is missing an up or down file", migration.Version, migration.Name)
+		}
+		result = append(result, *migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+func getAppliedMigrations(db *sql.DB) (map[int64]sql.NullTime, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT Version, AppliedAt FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]sql.NullTime{}
+	for rows.Next() {
+		var version int64
+		var appliedAt sql.NullTime
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// MigrateUp applies every pending migration in order, each in its own transaction.
+func MigrateUp(db *sql.DB) ([]Migration, error) {
+	all, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := []Migration{}
+	for _, migration := range all {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := runMigration(db, migration, migration.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO schema_migrations (Version, Name) VALUES (?, ?)", migration.Version, migration.Name)
+			return err
+		}); err != nil {
+			return migrated, err
+		}
+
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+		migrated = append(migrated, migration)
+	}
+
+	return migrated, nil
+}
+
+// MigrateDown rolls back the most recently applied migration.
+func MigrateDown(db *sql.DB) (Migration, error) {
+	all, err := LoadMigrations()
+	if err != nil {
+		return Migration{}, err
+	}
+
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	for i := len(all) - 1; i >= 0; i-- {
+		migration := all[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := runMigration(db, migration, migration.Down, func(tx *sql.Tx) error {
+			_, err := tx.Exec("DELETE FROM schema_migrations WHERE Version=?", migration.Version)
+			return err
+		}); err != nil {
+			return Migration{}, err
+		}
+
+		log.Printf("Rolled back migration %04d_%s", migration.Version, migration.Name)
+		return migration, nil
+	}
+
+	return Migration{}, ErrorNoMigrationsApplied
+}
+
+func GetMigrationStatus(db *sql.DB) ([]MigrationStatus, error) {
+	all, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	for _, migration := range all {
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			AppliedAt: applied[migration.Version],
+		})
+	}
+
+	return statuses, nil
+}
+
+// runMigration runs the statements with foreign keys turned off so tables can be rebuilt without their
+// rows cascading, the foreign keys are checked before the migration is committed.
+func runMigration(db *sql.DB, migration Migration, statements string, record func(tx *sql.Tx) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The pragma is a no-op inside a transaction, it has to be set on the connection first.
+	if _, err = conn.ExecContext(ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys=ON")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(statements); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if err = checkForeignKeys(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if err = record(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowId sql.NullInt64
+		var index int64
+		if err = rows.Scan(&table, &rowId, &parent, &index); err != nil {
+			return err
+		}
+		return fmt.Errorf("Row %d in %s references a missing row in %s", rowId.Int64, table, parent)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// migrateTo applies the migrations up to and including the version, like MigrateUp does.
+func migrateTo(t *testing.T, database *sql.DB, version int64) {
+	t.Helper()
+
+	all, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ensureMigrationsTable(database); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, migration := range all {
+		if migration.Version > version {
+			break
+		}
+		if err = runMigration(database, migration, migration.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO schema_migrations (Version, Name) VALUES (?, ?)", migration.Version, migration.Name)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExerciseLibraryMigrationKeepsSets(t *testing.T) {
+	database, err := OpenFile(filepath.Join(t.TempDir(), "database.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	migrateTo(t, database, 5)
+
+	// Two splits with a bench press each, both logged in the same workout, and curls that were taken out of
+	// their split but were logged.
+	_, err = database.Exec(`
+	INSERT INTO users (ID, Email, PasswordHash) VALUES (1, 'own@example.com', '');
+	INSERT INTO splits (ID, UserID, Name, Description) VALUES (1, 1, 'Push', ''), (2, 1, 'Chest', '');
+	INSERT INTO images (ID, Content, ContentType) VALUES (1, '', 'png'), (2, '', 'png'), (3, '', 'png');
+	INSERT INTO exercises (ID, SplitID, Name, Description, ImageID, Sets) VALUES
+		(1, 1, 'Bench press', '', 1, 3),
+		(2, 2, 'bench press ', '', 2, 2),
+		(3, NULL, 'Curls', '', 3, 1);
+	INSERT INTO workouts (ID, UserID, SplitID, StartedAt, CompletedAt) VALUES (1, 1, 1, '2024-03-04 18:00:00', '2024-03-04 19:00:00');
+	INSERT INTO workout_sets (SetNumber, WorkoutID, ExerciseID, SetRating, Weight, Reps) VALUES
+		(1, 1, 1, 'good', 100, 5),
+		(2, 1, 1, 'good', 100, 5),
+		(3, 1, 1, 'good', 100, 5),
+		(1, 1, 2, 'good', 80, 8),
+		(2, 1, 2, 'good', 80, 8),
+		(1, 1, 3, 'good', 20, 10);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = MigrateUp(database); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := database.Query(`
+	SELECT e.Name, e.UserID, ws.SetNumber, ws.Weight
+	FROM workout_sets ws
+	INNER JOIN exercises e ON e.ID = ws.ExerciseID
+	WHERE ws.WorkoutID = 1
+	ORDER BY e.ID, ws.SetNumber
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	type loggedSet struct {
+		name      string
+		userId    int64
+		setNumber int64
+		weight    float64
+	}
+	got := []loggedSet{}
+	for rows.Next() {
+		set := loggedSet{}
+		if err = rows.Scan(&set.name, &set.userId, &set.setNumber, &set.weight); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, set)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []loggedSet{
+		{"Bench press", 1, 1, 100},
+		{"Bench press", 1, 2, 100},
+		{"Bench press", 1, 3, 100},
+		{"Bench press", 1, 4, 80},
+		{"Bench press", 1, 5, 80},
+		{"Curls", 1, 1, 20},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got sets %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("set %d: got %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+
+	var splitExercises int64
+	if err = database.QueryRow("SELECT COUNT(*) FROM split_exercises WHERE ExerciseID = 1").Scan(&splitExercises); err != nil {
+		t.Fatal(err)
+	}
+	if splitExercises != 2 {
+		t.Errorf("the merged bench press is in %d splits, want 2", splitExercises)
+	}
+}
@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+type ApiTokenScope string
+
+const (
+	ApiTokenRead  ApiTokenScope = "read"
+	ApiTokenWrite ApiTokenScope = "write"
+)
+
+type ApiToken struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	Scope      ApiTokenScope
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+}
+
+// CreateApiToken stores the hash of an API token, the token itself is only shown to the user once.
+func CreateApiToken(userId int64, name string, tokenHash string, scope ApiTokenScope, db *sql.DB) (ApiToken, error) {
+	row := db.QueryRow(`
+	INSERT INTO api_tokens (UserID, Name, TokenHash, Scope)
+	VALUES (?, ?, ?, ?)
+	RETURNING ID, UserID, Name, Scope, CreatedAt, LastUsedAt
+	`, userId, name, tokenHash, scope)
+
+	apiToken := ApiToken{}
+	if err := row.Scan(&apiToken.ID, &apiToken.UserID, &apiToken.Name, &apiToken.Scope, &apiToken.CreatedAt, &apiToken.LastUsedAt); err != nil {
+		log.Printf("CreateApiToken Error: %s", err.Error())
+		return ApiToken{}, err
+	}
+
+	return apiToken, nil
+}
+
+func GetApiTokens(userId int64, db *sql.DB) ([]ApiToken, error) {
+	rows, err := db.Query(`
+	SELECT ID, UserID, Name, Scope, CreatedAt, LastUsedAt
+	FROM api_tokens
+	WHERE UserID=?
+	ORDER BY CreatedAt DESC, ID DESC
+	`, userId)
+
+	if err != nil {
+		log.Printf("GetApiTokens Error: %s", err.Error())
+		return nil, err
+	}
+
+	apiTokens := []ApiToken{}
+	for rows.Next() {
+		apiToken := ApiToken{}
+		if err = rows.Scan(&apiToken.ID, &apiToken.UserID, &apiToken.Name, &apiToken.Scope, &apiToken.CreatedAt, &apiToken.LastUsedAt); err != nil {
+			log.Printf("GetApiTokens Error: %s", err.Error())
+			break
+		}
+		apiTokens = append(apiTokens, apiToken)
+	}
+
+	return apiTokens, err
+}
+
+// UseApiToken looks up the token with the given hash and records that it was used.
+func UseApiToken(tokenHash string, db *sql.DB) (ApiToken, error) {
+	row := db.QueryRow(`
+	UPDATE api_tokens
+	SET LastUsedAt=CURRENT_TIMESTAMP
+	WHERE TokenHash=?
+	RETURNING ID, UserID, Name, Scope, CreatedAt, LastUsedAt
+	`, tokenHash)
+
+	apiToken := ApiToken{}
+	if err := row.Scan(&apiToken.ID, &apiToken.UserID, &apiToken.Name, &apiToken.Scope, &apiToken.CreatedAt, &apiToken.LastUsedAt); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("UseApiToken Error: %s", err.Error())
+		}
+		return ApiToken{}, err
+	}
+
+	return apiToken, nil
+}
+
+func DeleteApiToken(userId int64, tokenId int64, db *sql.DB) error {
+	deleteApiTokenResult, err := db.Exec(`
+	DELETE FROM api_tokens
+	WHERE ID=? AND UserID=?
+	`, tokenId, userId)
+
+	if err != nil {
+		log.Printf("DeleteApiToken Error: %s", err.Error())
+		return err
+	}
+
+	rows, err := deleteApiTokenResult.RowsAffected()
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return err
+}
@@ -5,36 +5,147 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 )
 
+// Exercise is an exercise in the user's library. Exercises read through a split also carry the split
+// and the targets, position and group the exercise has in it, library reads leave those zero.
 type Exercise struct {
-	ID            int64
-	SplitID       int64
-	Name          string
-	Description   string
-	WeightFrom    float64
-	WeightTo      float64
-	RepsFrom      float64
-	RepsTo        float64
-	Sets          int64
+	ID           int64
+	UserID       int64
+	SplitID      int64
+	Name         string
+	Description  string
+	MuscleGroups string
+	Measurement  Measurement
+	Position     int64
+	WeightFrom   float64
+	WeightTo     float64
+	RepsFrom     float64
+	RepsTo       float64
+	// Targets of timed exercises in seconds and of distance exercises in meters.
+	SecondsFrom  int64
+	SecondsTo    int64
+	DistanceFrom float64
+	DistanceTo   float64
+	Sets         int64
+	RestSeconds  int64
+	// Exercises of a split with the same non-zero GroupNumber are a superset or circuit.
+	GroupNumber int64
+	// HasWorkoutSet is true when the workout has sets of the exercise, or of its superset or circuit, or
+	// when the exercise was skipped in it.
 	HasWorkoutSet bool
 }
 
+// Measurement is what is logged for the sets of an exercise.
+type Measurement string
+
+const (
+	MeasurementWeightReps     Measurement = "weight_reps"
+	MeasurementBodyweightReps Measurement = "bodyweight_reps"
+	// Timed sets like planks, only the time the set was held is logged.
+	MeasurementDuration Measurement = "duration"
+	// Sets like rowing or running log the distance and the time it took.
+	MeasurementDistance Measurement = "distance"
+)
+
+var Measurements = []Measurement{MeasurementWeightReps, MeasurementBodyweightReps, MeasurementDuration, MeasurementDistance}
+
+func (m Measurement) IsValid() bool {
+	for _, measurement := range Measurements {
+		if m == measurement {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Measurement) Name() string {
+	switch m {
+	case MeasurementBodyweightReps:
+		return "Bodyweight × reps"
+	case MeasurementDuration:
+		return "Duration"
+	case MeasurementDistance:
+		return "Distance and duration"
+	}
+	return "Weight × reps"
+}
+
+func (m Measurement) UsesWeight() bool {
+	return m == MeasurementWeightReps
+}
+
+func (m Measurement) UsesReps() bool {
+	return m == MeasurementWeightReps || m == MeasurementBodyweightReps
+}
+
+func (m Measurement) UsesSeconds() bool {
+	return m == MeasurementDuration || m == MeasurementDistance
+}
+
+func (m Measurement) UsesDistance() bool {
+	return m == MeasurementDistance
+}
+
+// DefaultRestSeconds is the rest between sets an exercise gets when it is added to a split.
+const DefaultRestSeconds = 90
+
+var ErrorExerciseNameTaken = errors.New("There is already an exercise with that name in the library")
+var ErrorExerciseInSplit = errors.New("The exercise is already part of the split")
+var ErrorExerciseOrderMismatch = errors.New("The order has to list every exercise of the split once")
+var ErrorExerciseGroupTooSmall = errors.New("A superset or circuit needs at least two exercises of the split")
+
 func (e *Exercise) GetImageURL() string {
 	return fmt.Sprintf("/exercise/image/%d", e.ID)
 }
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+const libraryExerciseColumns = "e.ID, e.UserID, e.Name, e.Description, e.MuscleGroups, e.Measurement"
+
+// RETURNING can not use the table alias.
+const returningLibraryExerciseColumns = "ID, UserID, Name, Description, MuscleGroups, Measurement"
+
+func scanLibraryExercise(row rowScanner, exercise *Exercise, extra ...any) error {
+	return row.Scan(append([]any{&exercise.ID, &exercise.UserID, &exercise.Name, &exercise.Description, &exercise.MuscleGroups, &exercise.Measurement}, extra...)...)
+}
+
+const splitExerciseColumns = "e.ID, e.UserID, se.SplitID, e.Name, e.Description, e.MuscleGroups, e.Measurement, se.Position, se.WeightFrom, se.WeightTo, se.RepsFrom, se.RepsTo, se.SecondsFrom, se.SecondsTo, se.DistanceFrom, se.DistanceTo, se.Sets, se.RestSeconds, se.GroupNumber"
+
+func scanSplitExercise(row rowScanner, exercise *Exercise, extra ...any) error {
+	return row.Scan(append([]any{&exercise.ID, &exercise.UserID, &exercise.SplitID, &exercise.Name, &exercise.Description, &exercise.MuscleGroups, &exercise.Measurement, &exercise.Position, &exercise.WeightFrom, &exercise.WeightTo, &exercise.RepsFrom, &exercise.RepsTo, &exercise.SecondsFrom, &exercise.SecondsTo, &exercise.DistanceFrom, &exercise.DistanceTo, &exercise.Sets, &exercise.RestSeconds, &exercise.GroupNumber}, extra...)...)
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func isUniqueError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// GetAllExercises returns the exercises of a split in their order.
 func GetAllExercises(splitId int64, db *sql.DB) ([]Exercise, error) {
-	rows, err := db.Query("SELECT ID, SplitID, Name, Description, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets FROM exercises WHERE SplitID=?", splitId)
+	rows, err := db.Query(`
+	SELECT `+splitExerciseColumns+`
+	FROM split_exercises se
+	INNER JOIN exercises e ON e.ID = se.ExerciseID
+	WHERE se.SplitID=?
+	ORDER BY se.Position, se.ID
+	`, splitId)
 	if err != nil {
 		log.Printf("GetAllExercises Error: %s", err.Error())
 		return nil, err
 	}
+	defer rows.Close()
 
 	exercises := []Exercise{}
 	for rows.Next() {
 		exercise := Exercise{}
-		if err = rows.Scan(&exercise.ID, &exercise.SplitID, &exercise.Name, &exercise.Description, &exercise.WeightFrom, &exercise.WeightTo, &exercise.RepsFrom, &exercise.RepsTo, &exercise.Sets); err != nil {
+		if err = scanSplitExercise(rows, &exercise); err != nil {
 			log.Printf("GetAllExercises Error: %s", err.Error())
 			break
 		}
@@ -44,109 +155,301 @@ func GetAllExercises(splitId int64, db *sql.DB) ([]Exercise, error) {
 	return exercises, err
 }
 
-func GetExercise(exerciseId int64, db *sql.DB) (Exercise, error) {
-	row := db.QueryRow("SELECT ID, SplitID, Name, Description, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets FROM exercises WHERE ID=?", exerciseId)
+// GetLibraryExercises returns every exercise in the user's library ordered by name.
+func GetLibraryExercises(userId int64, db *sql.DB) ([]Exercise, error) {
+	rows, err := db.Query(`
+	SELECT `+libraryExerciseColumns+`
+	FROM exercises e
+	WHERE e.UserID=?
+	ORDER BY e.Name COLLATE NOCASE, e.ID
+	`, userId)
+	if err != nil {
+		log.Printf("GetLibraryExercises Error: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	exercises := []Exercise{}
+	for rows.Next() {
+		exercise := Exercise{}
+		if err = scanLibraryExercise(rows, &exercise); err != nil {
+			log.Printf("GetLibraryExercises Error: %s", err.Error())
+			break
+		}
+		exercises = append(exercises, exercise)
+	}
+
+	return exercises, err
+}
+
+// GetExercise returns the library exercise only when it belongs to the user,
+// otherwise sql.ErrNoRows is returned.
+func GetExercise(userId int64, exerciseId int64, db *sql.DB) (Exercise, error) {
+	row := db.QueryRow(`
+	SELECT `+libraryExerciseColumns+`
+	FROM exercises e
+	WHERE e.ID=? AND e.UserID=?
+	`, exerciseId, userId)
 
 	exercise := Exercise{}
-	var err error
-	if err = row.Scan(&exercise.ID, &exercise.SplitID, &exercise.Name, &exercise.Description, &exercise.WeightFrom, &exercise.WeightTo, &exercise.RepsFrom, &exercise.RepsTo, &exercise.Sets); err == sql.ErrNoRows {
-		log.Printf("GetExercise Error: %s", err.Error())
+	if err := scanLibraryExercise(row, &exercise); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("GetExercise Error: %s", err.Error())
+		}
+		return Exercise{}, err
+	}
+
+	return exercise, nil
+}
+
+// GetExerciseByName looks up a library exercise by name, ignoring case.
+func GetExerciseByName(userId int64, name string, db *sql.DB) (Exercise, error) {
+	row := db.QueryRow(`
+	SELECT `+libraryExerciseColumns+`
+	FROM exercises e
+	WHERE e.UserID=? AND lower(trim(e.Name))=lower(trim(?))
+	`, userId, name)
+
+	exercise := Exercise{}
+	if err := scanLibraryExercise(row, &exercise); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("GetExerciseByName Error: %s", err.Error())
+		}
+		return Exercise{}, err
+	}
+
+	return exercise, nil
+}
+
+// GetSplitExercise returns the exercise with its targets in the split, sql.ErrNoRows is returned when
+// the split is not the user's or the exercise is not part of it.
+func GetSplitExercise(userId int64, splitId int64, exerciseId int64, db *sql.DB) (Exercise, error) {
+	row := db.QueryRow(`
+	SELECT `+splitExerciseColumns+`
+	FROM split_exercises se
+	INNER JOIN exercises e ON e.ID = se.ExerciseID
+	WHERE se.SplitID=? AND se.ExerciseID=? AND e.UserID=?
+	`, splitId, exerciseId, userId)
+
+	exercise := Exercise{}
+	if err := scanSplitExercise(row, &exercise); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("GetSplitExercise Error: %s", err.Error())
+		}
 		return Exercise{}, err
 	}
 
 	return exercise, nil
 }
 
+// GetExerciseSplits returns the user's splits the exercise is used in.
+func GetExerciseSplits(userId int64, exerciseId int64, db *sql.DB) ([]Split, error) {
+	rows, err := db.Query(`
+	SELECT s.ID, s.UserID, s.Name, s.Description
+	FROM splits s
+	INNER JOIN split_exercises se ON se.SplitID = s.ID
+	WHERE se.ExerciseID=? AND s.UserID=?
+	ORDER BY s.ID
+	`, exerciseId, userId)
+	if err != nil {
+		log.Printf("GetExerciseSplits Error: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	splits := []Split{}
+	for rows.Next() {
+		split := Split{}
+		if err = rows.Scan(&split.ID, &split.UserID, &split.Name, &split.Description); err != nil {
+			log.Printf("GetExerciseSplits Error: %s", err.Error())
+			break
+		}
+		splits = append(splits, split)
+	}
+
+	return splits, err
+}
+
+// GetWorkoutExercise looks up an exercise with the targets of the workout's split, without an ownership
+// check, for callers that already reached the workout through the user.
+func GetWorkoutExercise(workoutId int64, exerciseId int64, db *sql.DB) (Exercise, error) {
+	row := db.QueryRow(`
+	SELECT `+splitExerciseColumns+`
+	FROM workouts w
+	INNER JOIN split_exercises se ON se.SplitID = w.SplitID
+	INNER JOIN exercises e ON e.ID = se.ExerciseID
+	WHERE w.ID=? AND se.ExerciseID=?
+	`, workoutId, exerciseId)
+
+	exercise := Exercise{}
+	err := scanSplitExercise(row, &exercise)
+	if err != nil {
+		log.Printf("GetWorkoutExercise Error: %s", err.Error())
+		return Exercise{}, err
+	}
+
+	return exercise, nil
+}
+
+// UpdateExercise changes the library exercise, which changes it in every split it is used in.
 func UpdateExercise(
+	userId int64,
 	id int64,
 	name string,
 	imageId *int64,
 	description string,
+	muscleGroups string,
+	measurement Measurement,
+	db *sql.DB) (Exercise, error) {
+
+	row := db.QueryRow(`
+	UPDATE exercises
+	SET Name=?,
+	Description=?,
+	MuscleGroups=?,
+	Measurement=?
+	WHERE ID=? AND UserID=?
+	RETURNING `+returningLibraryExerciseColumns+`
+	`, name, description, muscleGroups, measurement, id, userId)
+
+	exercise := Exercise{}
+	var err error
+	if err = scanLibraryExercise(row, &exercise); err != nil {
+		if isUniqueError(err) {
+			return Exercise{}, ErrorExerciseNameTaken
+		}
+		if err != sql.ErrNoRows {
+			log.Printf("UpdateExercise Error: %s", err.Error())
+		}
+		return Exercise{}, err
+	}
+
+	if imageId != nil {
+		if err = ReplaceExerciseImage(id, *imageId, db); err != nil {
+			log.Printf("UpdateExercise Error: %s", err.Error())
+			return Exercise{}, err
+		}
+	}
+
+	return exercise, nil
+}
+
+// UpdateSplitExercise changes the targets and rest the exercise has in the split.
+func UpdateSplitExercise(
+	userId int64,
+	splitId int64,
+	exerciseId int64,
 	weightFrom float64,
 	weightTo float64,
 	repsFrom int64,
 	repsTo int64,
-	sets int64, db *sql.DB) (Exercise, error) {
+	secondsFrom int64,
+	secondsTo int64,
+	distanceFrom float64,
+	distanceTo float64,
+	sets int64,
+	restSeconds int64,
+	db *sql.DB) (Exercise, error) {
 
-	var row *sql.Row
-	var err error
+	result, err := db.Exec(`
+	UPDATE split_exercises
+	SET WeightFrom=?,
+	WeightTo=?,
+	RepsFrom=?,
+	RepsTo=?,
+	SecondsFrom=?,
+	SecondsTo=?,
+	DistanceFrom=?,
+	DistanceTo=?,
+	Sets=?,
+	RestSeconds=?
+	WHERE SplitID=? AND ExerciseID=? AND SplitID IN (
+		SELECT ID FROM splits WHERE UserID=?
+	)
+	`, weightFrom, weightTo, repsFrom, repsTo, secondsFrom, secondsTo, distanceFrom, distanceTo, sets, restSeconds, splitId, exerciseId, userId)
+	if err != nil {
+		log.Printf("UpdateSplitExercise Error: %s", err.Error())
+		return Exercise{}, err
+	}
 
-	if imageId == nil {
-		row = db.QueryRow(`
-		UPDATE exercises 
-		SET Name=?,
-		Description=?,
-		WeightFrom=?,
-		WeightTo=?,
-		RepsFrom=?,
-		RepsTo=?,
-		Sets=?
-		WHERE ID=?
-		RETURNING ID, SplitID, Name, Description, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets
-		`, name, description, weightFrom, weightTo, repsFrom, repsTo, sets, id)
-	} else {
-		row = db.QueryRow(`
-		UPDATE exercises 
-		SET Name=?,
-		Description=?,
-		WeightFrom=?,
-		WeightTo=?,
-		RepsFrom=?,
-		RepsTo=?,
-		Sets=?
-		WHERE ID=?
-		RETURNING ID, SplitID, Name, Description, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets
-		`, name, description, weightFrom, weightTo, repsFrom, repsTo, sets, id)
-
-		err = ReplaceExerciseImage(id, *imageId, db)
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return Exercise{}, sql.ErrNoRows
 	}
 
+	return GetSplitExercise(userId, splitId, exerciseId, db)
+}
+
+// UpdateExerciseTargets sets new targets for the exercise in the split, used by the progression engine.
+func UpdateExerciseTargets(
+	splitId int64,
+	exerciseId int64,
+	weightFrom float64,
+	weightTo float64,
+	repsFrom float64,
+	repsTo float64,
+	db *sql.DB) (Exercise, error) {
+
+	result, err := db.Exec(`
+	UPDATE split_exercises
+	SET WeightFrom=?,
+	WeightTo=?,
+	RepsFrom=?,
+	RepsTo=?
+	WHERE SplitID=? AND ExerciseID=?
+	`, weightFrom, weightTo, repsFrom, repsTo, splitId, exerciseId)
 	if err != nil {
-		log.Printf("UpdateExercise Error: %s", err.Error())
+		log.Printf("UpdateExerciseTargets Error: %s", err.Error())
 		return Exercise{}, err
 	}
 
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return Exercise{}, sql.ErrNoRows
+	}
+
+	row := db.QueryRow(`
+	SELECT `+splitExerciseColumns+`
+	FROM split_exercises se
+	INNER JOIN exercises e ON e.ID = se.ExerciseID
+	WHERE se.SplitID=? AND se.ExerciseID=?
+	`, splitId, exerciseId)
+
 	exercise := Exercise{}
-	if err = row.Scan(&exercise.ID, &exercise.SplitID, &exercise.Name, &exercise.Description, &exercise.WeightFrom, &exercise.WeightTo, &exercise.RepsFrom, &exercise.RepsTo, &exercise.Sets); err == sql.ErrNoRows {
-		log.Printf("UpdateExercise Error: %s", err.Error())
+	if err = scanSplitExercise(row, &exercise); err != nil {
+		log.Printf("UpdateExerciseTargets Error: %s", err.Error())
 		return Exercise{}, err
 	}
 
-	return exercise, err
+	return exercise, nil
 }
 
+// CreateExercise adds an exercise to the user's library, it is not part of any split until it is added to one.
 func CreateExercise(
-	splitId int64,
+	userId int64,
 	imageId *int64,
 	name string,
 	description string,
-	weightFrom float64,
-	weightTo float64,
-	repsFrom int64,
-	repsTo int64,
-	sets int64,
+	muscleGroups string,
+	measurement Measurement,
 	db *sql.DB) (Exercise, error) {
 
 	if imageId == nil {
 		return Exercise{}, errors.New("No image provided")
 	}
 
-	row := db.QueryRow(`INSERT INTO exercises (SplitID, Name, Description, ImageID, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	RETURNING ID, SplitID, Name, Description, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets
-	`, splitId,
-		name,
-		description,
-		imageId,
-		weightFrom,
-		weightTo,
-		repsFrom,
-		repsTo,
-		sets)
+	row := db.QueryRow(`
+	INSERT INTO exercises (UserID, Name, Description, ImageID, MuscleGroups, Measurement)
+	VALUES (?, ?, ?, ?, ?, ?)
+	RETURNING `+returningLibraryExerciseColumns+`
+	`, userId, name, description, imageId, muscleGroups, measurement)
 
 	exercise := Exercise{}
 	var err error
-	if err = row.Scan(&exercise.ID, &exercise.SplitID, &exercise.Name, &exercise.Description, &exercise.WeightFrom, &exercise.WeightTo, &exercise.RepsFrom, &exercise.RepsTo, &exercise.Sets); err != nil {
+	if err = scanLibraryExercise(row, &exercise); err != nil {
+		if isUniqueError(err) {
+			// The image was stored for this exercise and would be left without one.
+			DeleteImage(*imageId, db)
+			return Exercise{}, ErrorExerciseNameTaken
+		}
 		log.Printf("CreateExercise Error: %s", err.Error())
 		return Exercise{}, err
 	}
@@ -154,13 +457,284 @@ func CreateExercise(
 	return exercise, err
 }
 
-func DeleteExercise(exerciseId int64, db *sql.DB) error {
-	var err error
+// AddSplitExercise adds a library exercise last in the split with the given targets and rest.
+func AddSplitExercise(
+	userId int64,
+	splitId int64,
+	exerciseId int64,
+	weightFrom float64,
+	weightTo float64,
+	repsFrom int64,
+	repsTo int64,
+	secondsFrom int64,
+	secondsTo int64,
+	distanceFrom float64,
+	distanceTo float64,
+	sets int64,
+	restSeconds int64,
+	db *sql.DB) (Exercise, error) {
 
+	result, err := db.Exec(`
+	INSERT INTO split_exercises (SplitID, ExerciseID, Position, WeightFrom, WeightTo, RepsFrom, RepsTo, SecondsFrom, SecondsTo, DistanceFrom, DistanceTo, Sets, RestSeconds)
+	SELECT s.ID, e.ID, (SELECT COALESCE(MAX(Position), 0) + 1 FROM split_exercises WHERE SplitID = s.ID), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+	FROM splits s
+	INNER JOIN exercises e ON e.UserID = s.UserID
+	WHERE s.ID=? AND e.ID=? AND s.UserID=?
+	`, weightFrom, weightTo, repsFrom, repsTo, secondsFrom, secondsTo, distanceFrom, distanceTo, sets, restSeconds, splitId, exerciseId, userId)
+	if isUniqueError(err) {
+		return Exercise{}, ErrorExerciseInSplit
+	}
+	if err != nil {
+		log.Printf("AddSplitExercise Error: %s", err.Error())
+		return Exercise{}, err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return Exercise{}, sql.ErrNoRows
+	}
+
+	return GetSplitExercise(userId, splitId, exerciseId, db)
+}
+
+// ReorderSplitExercises gives the exercises of the split the positions they have in exerciseIds, which has
+// to list every exercise of the split exactly once.
+func ReorderSplitExercises(userId int64, splitId int64, exerciseIds []int64, db *sql.DB) error {
+	exercises, err := GetAllExercises(splitId, db)
+	if err != nil {
+		return err
+	}
+
+	inSplit := map[int64]bool{}
+	for _, exercise := range exercises {
+		inSplit[exercise.ID] = true
+	}
+
+	if len(exerciseIds) != len(exercises) {
+		return ErrorExerciseOrderMismatch
+	}
+	for _, exerciseId := range exerciseIds {
+		if !inSplit[exerciseId] {
+			return ErrorExerciseOrderMismatch
+		}
+		delete(inSplit, exerciseId)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("ReorderSplitExercises Error: %s", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, exerciseId := range exerciseIds {
+		result, err := tx.Exec(`
+		UPDATE split_exercises
+		SET Position=?
+		WHERE SplitID=? AND ExerciseID=? AND SplitID IN (
+			SELECT ID FROM splits WHERE UserID=?
+		)
+		`, i+1, splitId, exerciseId, userId)
+		if err != nil {
+			log.Printf("ReorderSplitExercises Error: %s", err.Error())
+			return err
+		}
+
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return sql.ErrNoRows
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("ReorderSplitExercises Error: %s", err.Error())
+	}
+	return err
+}
+
+// GroupSplitExercises makes a new superset or circuit of the exercises, which are taken out of the groups
+// they were in. It returns the number of the new group.
+func GroupSplitExercises(userId int64, splitId int64, exerciseIds []int64, db *sql.DB) (int64, error) {
+	exercises, err := GetAllExercises(splitId, db)
+	if err != nil {
+		return 0, err
+	}
+
+	inSplit := map[int64]bool{}
+	for _, exercise := range exercises {
+		inSplit[exercise.ID] = true
+	}
+
+	grouped := map[int64]bool{}
+	for _, exerciseId := range exerciseIds {
+		if !inSplit[exerciseId] {
+			return 0, ErrorExerciseGroupTooSmall
+		}
+		grouped[exerciseId] = true
+	}
+	if len(grouped) < 2 {
+		return 0, ErrorExerciseGroupTooSmall
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("GroupSplitExercises Error: %s", err.Error())
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var groupNumber int64
+	err = tx.QueryRow(`
+	SELECT COALESCE(MAX(se.GroupNumber), 0) + 1
+	FROM split_exercises se
+	INNER JOIN splits s ON s.ID = se.SplitID
+	WHERE se.SplitID=? AND s.UserID=?
+	`, splitId, userId).Scan(&groupNumber)
+	if err != nil {
+		log.Printf("GroupSplitExercises Error: %s", err.Error())
+		return 0, err
+	}
+
+	for exerciseId := range grouped {
+		result, err := tx.Exec(`
+		UPDATE split_exercises
+		SET GroupNumber=?
+		WHERE SplitID=? AND ExerciseID=? AND SplitID IN (
+			SELECT ID FROM splits WHERE UserID=?
+		)
+		`, groupNumber, splitId, exerciseId, userId)
+		if err != nil {
+			log.Printf("GroupSplitExercises Error: %s", err.Error())
+			return 0, err
+		}
+
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return 0, sql.ErrNoRows
+		}
+	}
+
+	if err = ungroupSingleExercises(splitId, tx); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("GroupSplitExercises Error: %s", err.Error())
+	}
+	return groupNumber, err
+}
+
+// UngroupSplitExercises splits up the superset or circuit, its exercises go back to one at a time.
+func UngroupSplitExercises(userId int64, splitId int64, groupNumber int64, db *sql.DB) error {
+	result, err := db.Exec(`
+	UPDATE split_exercises
+	SET GroupNumber=0
+	WHERE SplitID=? AND GroupNumber=? AND GroupNumber<>0 AND SplitID IN (
+		SELECT ID FROM splits WHERE UserID=?
+	)
+	`, splitId, groupNumber, userId)
+	if err != nil {
+		log.Printf("UngroupSplitExercises Error: %s", err.Error())
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ungroupSingleExercises dissolves the groups of the split that are left with a single exercise.
+func ungroupSingleExercises(splitId int64, db execer) error {
+	_, err := db.Exec(`
+	UPDATE split_exercises
+	SET GroupNumber=0
+	WHERE SplitID=? AND GroupNumber IN (
+		SELECT GroupNumber FROM split_exercises
+		WHERE SplitID=? AND GroupNumber<>0
+		GROUP BY GroupNumber
+		HAVING COUNT(*) = 1
+	)
+	`, splitId, splitId)
+	if err != nil {
+		log.Printf("ungroupSingleExercises Error: %s", err.Error())
+	}
+	return err
+}
+
+// GroupExercises returns the exercises that take turns with the exercise, in their split order. An exercise
+// that is not in a group only takes turns with itself.
+func GroupExercises(exercises []Exercise, exercise Exercise) []Exercise {
+	if exercise.GroupNumber == 0 {
+		return []Exercise{exercise}
+	}
+
+	group := []Exercise{}
+	for _, member := range exercises {
+		if member.GroupNumber == exercise.GroupNumber {
+			group = append(group, member)
+		}
+	}
+	return group
+}
+
+// GroupTurnOrder rotates the group so the exercise the workout started the group with goes first, each
+// round of a superset or circuit starts with that exercise.
+func GroupTurnOrder(group []Exercise, workoutSets []WorkoutSet) []Exercise {
+	for _, workoutSet := range workoutSets {
+		for i, member := range group {
+			if member.ID == workoutSet.ExerciseID {
+				return append(append([]Exercise{}, group[i:]...), group[:i]...)
+			}
+		}
+	}
+	return group
+}
+
+// RemoveSplitExercise takes the exercise out of the split, the exercise and its history stay in the library.
+func RemoveSplitExercise(userId int64, splitId int64, exerciseId int64, db *sql.DB) error {
+	result, err := db.Exec(`
+	DELETE FROM split_exercises
+	WHERE SplitID=? AND ExerciseID=? AND SplitID IN (
+		SELECT ID FROM splits WHERE UserID=?
+	)
+	`, splitId, exerciseId, userId)
+
+	if err != nil {
+		log.Printf("RemoveSplitExercise Error: %s", err.Error())
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err = ungroupSingleExercises(splitId, db); err != nil {
+		return err
+	}
+
+	// A set that was started in the split has no targets to continue with.
+	_, err = db.Exec(`
+	DELETE FROM workout_sets
+	WHERE ExerciseID=? AND CompletedAt IS NULL AND WorkoutID IN (
+		SELECT ID FROM workouts WHERE SplitID=? AND CompletedAt IS NULL
+	)
+	`, exerciseId, splitId)
+	if err != nil {
+		log.Printf("RemoveSplitExercise Error: %s", err.Error())
+	}
+
+	return err
+}
+
+// DeleteExercise removes the exercise from the library, every split and the workout history.
+func DeleteExercise(userId int64, exerciseId int64, db *sql.DB) error {
 	result, err := db.Exec(`
 	DELETE FROM exercises
-		WHERE ID=?
-	`, exerciseId)
+	WHERE ID=? AND UserID=?
+	`, exerciseId, userId)
+
+	if err != nil {
+		log.Printf("DeleteExercise Error: %s", err.Error())
+		return err
+	}
 
 	rows, err := result.RowsAffected()
 
@@ -173,23 +747,29 @@ func DeleteExercise(exerciseId int64, db *sql.DB) error {
 
 func GetRemainingWorkoutExercises(splitId int64, workoutId int64, db *sql.DB) ([]Exercise, error) {
 	rows, err := db.Query(`
-	SELECT ID, SplitID, Name, Description, WeightFrom, WeightTo, RepsFrom, RepsTo, Sets FROM exercises 
-	WHERE ID NOT IN (
-		SELECT DISTINCT ExerciseID 
-		FROM workout_sets 
-		WHERE WorkoutID=?
-	) 
-	AND SplitID=?
-`, workoutId, splitId)
+	SELECT `+splitExerciseColumns+`
+	FROM split_exercises se
+	INNER JOIN exercises e ON e.ID = se.ExerciseID
+	WHERE NOT EXISTS (
+		SELECT 1
+		FROM workout_sets ws
+		INNER JOIN split_exercises g ON g.ExerciseID = ws.ExerciseID AND g.SplitID = se.SplitID
+		WHERE ws.WorkoutID=? AND (g.ExerciseID = se.ExerciseID OR (se.GroupNumber <> 0 AND g.GroupNumber = se.GroupNumber))
+	)
+	AND NOT EXISTS (SELECT 1 FROM skipped_exercises sk WHERE sk.WorkoutID=? AND sk.ExerciseID = se.ExerciseID)
+	AND se.SplitID=?
+	ORDER BY se.Position, se.ID
+`, workoutId, workoutId, splitId)
 	if err != nil {
 		log.Printf("GetRemainingWorkoutExercises Error: %s", err.Error())
 		return nil, err
 	}
+	defer rows.Close()
 
 	exercises := []Exercise{}
 	for rows.Next() {
 		exercise := Exercise{}
-		if err = rows.Scan(&exercise.ID, &exercise.SplitID, &exercise.Name, &exercise.Description, &exercise.WeightFrom, &exercise.WeightTo, &exercise.RepsFrom, &exercise.RepsTo, &exercise.Sets); err != nil {
+		if err = scanSplitExercise(rows, &exercise); err != nil {
 			log.Printf("GetRemainingWorkoutExercises Error: %s", err.Error())
 			break
 		}
@@ -201,21 +781,30 @@ func GetRemainingWorkoutExercises(splitId int64, workoutId int64, db *sql.DB) ([
 
 func GetWorkoutExercises(splitId int64, workoutId int64, db *sql.DB) ([]Exercise, error) {
 	rows, err := db.Query(`
-    SELECT DISTINCT e.ID, e.SplitID, e.Name, e.Description, e.WeightFrom, e.WeightTo, e.RepsFrom, e.RepsTo, e.Sets,
-        CASE WHEN ws.ExerciseID IS NULL THEN 0 ELSE 1 END AS HasWorkoutSet
-    FROM exercises e
-    LEFT JOIN workout_sets ws ON e.ID = ws.ExerciseID AND ws.WorkoutID = ?
-    WHERE e.SplitID = ?
-`, workoutId, splitId)
+    SELECT `+splitExerciseColumns+`,
+        EXISTS (
+            SELECT 1
+            FROM workout_sets ws
+            INNER JOIN split_exercises g ON g.ExerciseID = ws.ExerciseID AND g.SplitID = se.SplitID
+            WHERE ws.WorkoutID = ? AND (g.ExerciseID = se.ExerciseID OR (se.GroupNumber <> 0 AND g.GroupNumber = se.GroupNumber))
+        ) OR EXISTS (
+            SELECT 1 FROM skipped_exercises sk WHERE sk.WorkoutID = ? AND sk.ExerciseID = se.ExerciseID
+        ) AS HasWorkoutSet
+    FROM split_exercises se
+    INNER JOIN exercises e ON e.ID = se.ExerciseID
+    WHERE se.SplitID = ?
+    ORDER BY se.Position, se.ID
+`, workoutId, workoutId, splitId)
 	if err != nil {
 		log.Printf("GetWorkoutExercises Error: %s", err.Error())
 		return nil, err
 	}
+	defer rows.Close()
 
 	exercises := []Exercise{}
 	for rows.Next() {
 		exercise := Exercise{}
-		if err = rows.Scan(&exercise.ID, &exercise.SplitID, &exercise.Name, &exercise.Description, &exercise.WeightFrom, &exercise.WeightTo, &exercise.RepsFrom, &exercise.RepsTo, &exercise.Sets, &exercise.HasWorkoutSet); err != nil {
+		if err = scanSplitExercise(rows, &exercise, &exercise.HasWorkoutSet); err != nil {
 			log.Printf("GetWorkoutExercises Error: %s", err.Error())
 			break
 		}
@@ -261,14 +850,14 @@ func ReplaceExerciseImage(exerciseId int64, newImageId int64, db *sql.DB) error
 	return err
 }
 
-func GetExerciseImage(exerciseId int64, db *sql.DB) (Image, error) {
+func GetExerciseImage(userId int64, exerciseId int64, db *sql.DB) (Image, error) {
 	row := db.QueryRow(`
 		SELECT Content, ContentType FROM images
 		WHERE ID=(
 			SELECT ImageID FROM exercises
-			WHERE ID=?
+			WHERE ID=? AND UserID=?
 		)
-	`, exerciseId)
+	`, exerciseId, userId)
 
 	image := Image{}
 	err := row.Scan(&image.Content, &image.ContentType)
@@ -0,0 +1,462 @@
+package dto
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// UserData is everything a user has logged. The IDs are the ones of the rows it was read from and are
+// only used to link the rows to each other, ImportUserData always creates new rows.
+type UserData struct {
+	Splits []Split
+	// Every exercise of the library, also the ones that are not in any split.
+	Exercises []Exercise
+	// The exercises of every split with their targets, by the IDs in Exercises. An exercise used in several
+	// splits is listed once per split.
+	SplitExercises []Exercise
+	// Exercise images keyed by exercise ID. GetUserData leaves Content empty, use GetExerciseImage to
+	// read the images one at a time.
+	Images map[int64]Image
+	// The muscle groups of the exercises, by the IDs in Exercises.
+	MuscleGroups []ExerciseMuscleGroup
+	Workouts     []Workout
+	WorkoutSets  []WorkoutSet
+}
+
+var ErrorInvalidImport = errors.New("Import references a split, exercise or workout that is not part of it")
+
+func GetUserData(userId int64, db *sql.DB) (UserData, error) {
+	data := UserData{
+		Splits:         []Split{},
+		Exercises:      []Exercise{},
+		SplitExercises: []Exercise{},
+		Images:         map[int64]Image{},
+		MuscleGroups:   []ExerciseMuscleGroup{},
+		Workouts:       []Workout{},
+		WorkoutSets:    []WorkoutSet{},
+	}
+
+	splits, err := GetSplits(userId, db)
+	if err != nil {
+		return UserData{}, err
+	}
+	data.Splits = splits
+
+	exerciseRows, err := db.Query(`
+	SELECT `+libraryExerciseColumns+`, i.ID, i.ContentType
+	FROM exercises e
+	LEFT JOIN images i ON i.ID = e.ImageID
+	WHERE e.UserID=?
+	ORDER BY e.Name COLLATE NOCASE, e.ID
+	`, userId)
+	if err != nil {
+		log.Printf("GetUserData Error: %s", err.Error())
+		return UserData{}, err
+	}
+	defer exerciseRows.Close()
+
+	for exerciseRows.Next() {
+		exercise := Exercise{}
+		var imageId sql.NullInt64
+		var imageType sql.NullString
+		if err = scanLibraryExercise(exerciseRows, &exercise, &imageId, &imageType); err != nil {
+			log.Printf("GetUserData Error: %s", err.Error())
+			return UserData{}, err
+		}
+		data.Exercises = append(data.Exercises, exercise)
+		if imageId.Valid {
+			data.Images[exercise.ID] = Image{ID: imageId.Int64, ContentType: ImageType(imageType.String)}
+		}
+	}
+
+	splitExerciseRows, err := db.Query(`
+	SELECT `+splitExerciseColumns+`
+	FROM split_exercises se
+	INNER JOIN exercises e ON e.ID = se.ExerciseID
+	WHERE e.UserID=?
+	ORDER BY se.SplitID, se.Position
+	`, userId)
+	if err != nil {
+		log.Printf("GetUserData Error: %s", err.Error())
+		return UserData{}, err
+	}
+	defer splitExerciseRows.Close()
+
+	for splitExerciseRows.Next() {
+		exercise := Exercise{}
+		if err = scanSplitExercise(splitExerciseRows, &exercise); err != nil {
+			log.Printf("GetUserData Error: %s", err.Error())
+			return UserData{}, err
+		}
+		data.SplitExercises = append(data.SplitExercises, exercise)
+	}
+
+	if data.MuscleGroups, err = GetUserExerciseMuscleGroups(userId, db); err != nil {
+		return UserData{}, err
+	}
+
+	workoutRows, err := db.Query(`
+	SELECT ID, UserID, SplitID, StartedAt, CompletedAt
+	FROM workouts
+	WHERE UserID=?
+	ORDER BY StartedAt ASC, ID ASC
+	`, userId)
+	if err != nil {
+		log.Printf("GetUserData Error: %s", err.Error())
+		return UserData{}, err
+	}
+	defer workoutRows.Close()
+
+	for workoutRows.Next() {
+		workout := Workout{}
+		if err = workoutRows.Scan(&workout.ID, &workout.UserID, &workout.SplitID, &workout.StartedAt, &workout.CompletedAt); err != nil {
+			log.Printf("GetUserData Error: %s", err.Error())
+			return UserData{}, err
+		}
+		data.Workouts = append(data.Workouts, workout)
+	}
+
+	setRows, err := db.Query(`
+	SELECT ws.SetNumber, ws.WorkoutID, ws.ExerciseID, ws.StartedAt, ws.CompletedAt, ws.SetRating, ws.SetType, ws.WeightFrom, ws.WeightTo, ws.RepsFrom, ws.RepsTo, ws.Weight, ws.Reps, ws.Seconds, ws.Distance, ws.RestSeconds
+	FROM workout_sets ws
+	INNER JOIN workouts w ON w.ID = ws.WorkoutID
+	WHERE w.UserID=?
+	ORDER BY w.StartedAt ASC, w.ID ASC, ws.StartedAt ASC, ws.rowid ASC
+	`, userId)
+	if err != nil {
+		log.Printf("GetUserData Error: %s", err.Error())
+		return UserData{}, err
+	}
+	defer setRows.Close()
+
+	for setRows.Next() {
+		workoutSet := WorkoutSet{}
+		if err = setRows.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.SetType, &workoutSet.WeightFrom, &workoutSet.WeightTo, &workoutSet.RepsFrom, &workoutSet.RepsTo, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.RestSeconds); err != nil {
+			log.Printf("GetUserData Error: %s", err.Error())
+			return UserData{}, err
+		}
+		data.WorkoutSets = append(data.WorkoutSets, workoutSet)
+	}
+
+	return data, nil
+}
+
+func formatNullTime(value sql.NullTime) any {
+	if !value.Valid {
+		return nil
+	}
+	return value.Time.UTC().Format(time.DateTime)
+}
+
+// ImportUserData adds the data to the user in a single transaction, nothing is imported when any row fails.
+// Every exercise needs an image in data.Images. Exercises are added to the library once, an exercise with
+// the name of one already in the library is merged into it and keeps the existing image.
+func ImportUserData(userId int64, data UserData, db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("ImportUserData Error: %s", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	splitIds := map[int64]int64{}
+	for _, split := range data.Splits {
+		row := tx.QueryRow(`
+		INSERT INTO splits (UserID, Name, Description)
+		VALUES (?, ?, ?)
+		RETURNING ID
+		`, userId, split.Name, split.Description)
+
+		var splitId int64
+		if err = row.Scan(&splitId); err != nil {
+			log.Printf("ImportUserData Error: %s", err.Error())
+			return err
+		}
+		splitIds[split.ID] = splitId
+	}
+
+	exerciseIds := map[int64]int64{}
+	for _, exercise := range data.Exercises {
+		if _, ok := exerciseIds[exercise.ID]; ok {
+			continue
+		}
+		exerciseId, err := importLibraryExercise(tx, userId, exercise, data.Images, data.MuscleGroups)
+		if err != nil {
+			return err
+		}
+		exerciseIds[exercise.ID] = exerciseId
+	}
+
+	for _, exercise := range data.SplitExercises {
+		splitId, hasSplit := splitIds[exercise.SplitID]
+		exerciseId, hasExercise := exerciseIds[exercise.ID]
+		if !hasSplit || !hasExercise {
+			return ErrorInvalidImport
+		}
+
+		_, err = tx.Exec(`
+		INSERT OR IGNORE INTO split_exercises (SplitID, ExerciseID, Position, WeightFrom, WeightTo, RepsFrom, RepsTo, SecondsFrom, SecondsTo, DistanceFrom, DistanceTo, Sets, RestSeconds, GroupNumber)
+		VALUES (?, ?, (SELECT COALESCE(MAX(Position), 0) + 1 FROM split_exercises WHERE SplitID = ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, splitId, exerciseId, splitId, exercise.WeightFrom, exercise.WeightTo, exercise.RepsFrom, exercise.RepsTo, exercise.SecondsFrom, exercise.SecondsTo, exercise.DistanceFrom, exercise.DistanceTo, exercise.Sets, exercise.RestSeconds, exercise.GroupNumber)
+		if err != nil {
+			log.Printf("ImportUserData Error: %s", err.Error())
+			return err
+		}
+	}
+
+	workoutIds := map[int64]int64{}
+	for _, workout := range data.Workouts {
+		splitId, ok := splitIds[workout.SplitID]
+		if !ok {
+			return ErrorInvalidImport
+		}
+
+		workoutId, err := insertWorkout(tx, userId, splitId, workout)
+		if err != nil {
+			log.Printf("ImportUserData Error: %s", err.Error())
+			return err
+		}
+		workoutIds[workout.ID] = workoutId
+	}
+
+	for _, workoutSet := range data.WorkoutSets {
+		workoutId, hasWorkout := workoutIds[workoutSet.WorkoutID]
+		exerciseId, hasExercise := exerciseIds[workoutSet.ExerciseID]
+		if !hasWorkout || !hasExercise {
+			return ErrorInvalidImport
+		}
+
+		if err = insertWorkoutSet(tx, workoutId, exerciseId, workoutSet); err != nil {
+			log.Printf("ImportUserData Error: %s", err.Error())
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("ImportUserData Error: %s", err.Error())
+	}
+	return err
+}
+
+// importLibraryExercise returns the library exercise with the name of the imported one, creating it with
+// its image and muscle groups when the library does not have it yet.
+func importLibraryExercise(tx *sql.Tx, userId int64, exercise Exercise, images map[int64]Image, muscleGroups []ExerciseMuscleGroup) (int64, error) {
+	var exerciseId int64
+	err := tx.QueryRow(`
+	SELECT ID FROM exercises
+	WHERE UserID=? AND lower(trim(Name))=lower(trim(?))
+	`, userId, exercise.Name).Scan(&exerciseId)
+	if err == nil {
+		return exerciseId, nil
+	}
+	if err != sql.ErrNoRows {
+		log.Printf("importLibraryExercise Error: %s", err.Error())
+		return 0, err
+	}
+
+	image, ok := images[exercise.ID]
+	if !ok {
+		return 0, ErrorInvalidImport
+	}
+
+	var imageId int64
+	err = tx.QueryRow(`
+	INSERT INTO images (ContentType, Content)
+	VALUES (?, ?)
+	RETURNING ID
+	`, image.ContentType, image.Content).Scan(&imageId)
+	if err != nil {
+		log.Printf("importLibraryExercise Error: %s", err.Error())
+		return 0, err
+	}
+
+	err = tx.QueryRow(`
+	INSERT INTO exercises (UserID, Name, Description, ImageID, MuscleGroups, Measurement)
+	VALUES (?, ?, ?, ?, ?, ?)
+	RETURNING ID
+	`, userId, exercise.Name, exercise.Description, imageId, exercise.MuscleGroups, exercise.Measurement).Scan(&exerciseId)
+	if err != nil {
+		log.Printf("importLibraryExercise Error: %s", err.Error())
+		return 0, err
+	}
+
+	for _, muscleGroup := range muscleGroups {
+		if muscleGroup.ExerciseID != exercise.ID {
+			continue
+		}
+		_, err = tx.Exec(`
+		INSERT OR IGNORE INTO exercise_muscle_groups (ExerciseID, MuscleGroup, Role)
+		VALUES (?, ?, ?)
+		`, exerciseId, muscleGroup.MuscleGroup, muscleGroup.Role)
+		if err != nil {
+			log.Printf("importLibraryExercise Error: %s", err.Error())
+			return 0, err
+		}
+	}
+
+	return exerciseId, nil
+}
+
+func insertWorkout(tx *sql.Tx, userId int64, splitId int64, workout Workout) (int64, error) {
+	row := tx.QueryRow(`
+	INSERT INTO workouts (UserID, SplitID, StartedAt, CompletedAt)
+	VALUES (?, ?, ?, ?)
+	RETURNING ID
+	`, userId, splitId, workout.StartedAt.UTC().Format(time.DateTime), formatNullTime(workout.CompletedAt))
+
+	var workoutId int64
+	err := row.Scan(&workoutId)
+	return workoutId, err
+}
+
+func insertWorkoutSet(tx *sql.Tx, workoutId int64, exerciseId int64, workoutSet WorkoutSet) error {
+	_, err := tx.Exec(`
+	INSERT INTO workout_sets (SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, SetType, WeightFrom, WeightTo, RepsFrom, RepsTo, Weight, Reps, Seconds, Distance, RestSeconds)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, workoutSet.SetNumber, workoutId, exerciseId, workoutSet.StartedAt.UTC().Format(time.DateTime), formatNullTime(workoutSet.CompletedAt), workoutSet.SetRating, workoutSet.SetType, workoutSet.WeightFrom, workoutSet.WeightTo, workoutSet.RepsFrom, workoutSet.RepsTo, workoutSet.Weight, workoutSet.Reps, workoutSet.Seconds, workoutSet.Distance, workoutSet.RestSeconds)
+	return err
+}
+
+// ImportedWorkout is a completed workout logged somewhere else, its split and the exercises of its sets
+// either exist already or are part of the ImportedRows it is imported with.
+type ImportedWorkout struct {
+	Workout
+	Sets []WorkoutSet
+}
+
+// ImportedRows are the splits and exercises that imported workouts need and the user does not have yet.
+// The new rows have negative IDs that the workouts and sets refer to, positive IDs are of the user's rows.
+type ImportedRows struct {
+	Splits []Split
+	// The exercises to add to the split in SplitID with their targets. An exercise with a negative ID is
+	// created in the library, unless it has one with its name, and one ID can be added to several splits.
+	Exercises []Exercise
+	// The images of the exercises to create, keyed by their negative IDs.
+	Images map[int64]Image
+}
+
+// ImportWorkouts adds the rows and then the workouts to the user in a single transaction, so a failed
+// import leaves nothing behind.
+func ImportWorkouts(userId int64, rows ImportedRows, workouts []ImportedWorkout, db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("ImportWorkouts Error: %s", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	// The IDs of the created rows by the negative IDs they were imported with.
+	createdIds := map[int64]int64{}
+	rowId := func(id int64) (int64, error) {
+		if id >= 0 {
+			return id, nil
+		}
+		createdId, ok := createdIds[id]
+		if !ok {
+			return 0, ErrorInvalidImport
+		}
+		return createdId, nil
+	}
+
+	for _, split := range rows.Splits {
+		row := tx.QueryRow(`
+		INSERT INTO splits (UserID, Name, Description)
+		VALUES (?, ?, ?)
+		RETURNING ID
+		`, userId, split.Name, split.Description)
+
+		var splitId int64
+		if err = row.Scan(&splitId); err != nil {
+			log.Printf("ImportWorkouts Error: %s", err.Error())
+			return err
+		}
+		createdIds[split.ID] = splitId
+	}
+
+	for _, exercise := range rows.Exercises {
+		splitId, err := rowId(exercise.SplitID)
+		if err != nil {
+			return err
+		}
+
+		exerciseId := exercise.ID
+		if exercise.ID < 0 {
+			var created bool
+			if exerciseId, created = createdIds[exercise.ID]; !created {
+				if exerciseId, err = importLibraryExercise(tx, userId, exercise, rows.Images, nil); err != nil {
+					return err
+				}
+				createdIds[exercise.ID] = exerciseId
+			}
+		}
+
+		result, err := tx.Exec(`
+		INSERT INTO split_exercises (SplitID, ExerciseID, Position, WeightFrom, WeightTo, RepsFrom, RepsTo, SecondsFrom, SecondsTo, DistanceFrom, DistanceTo, Sets, RestSeconds)
+		SELECT s.ID, e.ID, (SELECT COALESCE(MAX(Position), 0) + 1 FROM split_exercises WHERE SplitID = s.ID), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		FROM splits s
+		INNER JOIN exercises e ON e.UserID = s.UserID
+		WHERE s.ID=? AND e.ID=? AND s.UserID=?
+		`, exercise.WeightFrom, exercise.WeightTo, exercise.RepsFrom, exercise.RepsTo, exercise.SecondsFrom, exercise.SecondsTo, exercise.DistanceFrom, exercise.DistanceTo, exercise.Sets, exercise.RestSeconds, splitId, exerciseId, userId)
+		if err != nil {
+			log.Printf("ImportWorkouts Error: %s", err.Error())
+			return err
+		}
+		if added, _ := result.RowsAffected(); added == 0 {
+			return ErrorInvalidImport
+		}
+	}
+
+	for _, workout := range workouts {
+		splitId, err := rowId(workout.SplitID)
+		if err != nil {
+			return err
+		}
+
+		workoutId, err := insertWorkout(tx, userId, splitId, workout.Workout)
+		if err != nil {
+			log.Printf("ImportWorkouts Error: %s", err.Error())
+			return err
+		}
+
+		for _, workoutSet := range workout.Sets {
+			exerciseId, err := rowId(workoutSet.ExerciseID)
+			if err != nil {
+				return err
+			}
+
+			if err = insertWorkoutSet(tx, workoutId, exerciseId, workoutSet); err != nil {
+				log.Printf("ImportWorkouts Error: %s", err.Error())
+				return err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("ImportWorkouts Error: %s", err.Error())
+	}
+	return err
+}
+
+// GetWorkoutStartTimes returns when each of the user's workouts started, used to find workouts that
+// were already imported.
+func GetWorkoutStartTimes(userId int64, db *sql.DB) ([]time.Time, error) {
+	rows, err := db.Query("SELECT StartedAt FROM workouts WHERE UserID=?", userId)
+	if err != nil {
+		log.Printf("GetWorkoutStartTimes Error: %s", err.Error())
+		return nil, err
+	}
+
+	startTimes := []time.Time{}
+	for rows.Next() {
+		var startedAt time.Time
+		if err = rows.Scan(&startedAt); err != nil {
+			log.Printf("GetWorkoutStartTimes Error: %s", err.Error())
+			break
+		}
+		startTimes = append(startTimes, startedAt)
+	}
+
+	return startTimes, err
+}
@@ -0,0 +1,346 @@
+package dto
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+)
+
+// MuscleGroup is a muscle group of the built-in taxonomy exercises are tagged with.
+type MuscleGroup string
+
+const (
+	MuscleChest      MuscleGroup = "chest"
+	MuscleBack       MuscleGroup = "back"
+	MuscleTraps      MuscleGroup = "traps"
+	MuscleFrontDelts MuscleGroup = "front_delts"
+	MuscleSideDelts  MuscleGroup = "side_delts"
+	MuscleRearDelts  MuscleGroup = "rear_delts"
+	MuscleBiceps     MuscleGroup = "biceps"
+	MuscleTriceps    MuscleGroup = "triceps"
+	MuscleForearms   MuscleGroup = "forearms"
+	MuscleAbs        MuscleGroup = "abs"
+	MuscleGlutes     MuscleGroup = "glutes"
+	MuscleQuads      MuscleGroup = "quads"
+	MuscleHamstrings MuscleGroup = "hamstrings"
+	MuscleCalves     MuscleGroup = "calves"
+)
+
+// MuscleGroups lists the taxonomy in the order it is shown.
+var MuscleGroups = []MuscleGroup{
+	MuscleChest,
+	MuscleBack,
+	MuscleTraps,
+	MuscleFrontDelts,
+	MuscleSideDelts,
+	MuscleRearDelts,
+	MuscleBiceps,
+	MuscleTriceps,
+	MuscleForearms,
+	MuscleAbs,
+	MuscleGlutes,
+	MuscleQuads,
+	MuscleHamstrings,
+	MuscleCalves,
+}
+
+func (m MuscleGroup) IsValid() bool {
+	for _, muscleGroup := range MuscleGroups {
+		if m == muscleGroup {
+			return true
+		}
+	}
+	return false
+}
+
+func (m MuscleGroup) Name() string {
+	switch m {
+	case MuscleChest:
+		return "Chest"
+	case MuscleBack:
+		return "Back"
+	case MuscleTraps:
+		return "Traps"
+	case MuscleFrontDelts:
+		return "Front delts"
+	case MuscleSideDelts:
+		return "Side delts"
+	case MuscleRearDelts:
+		return "Rear delts"
+	case MuscleBiceps:
+		return "Biceps"
+	case MuscleTriceps:
+		return "Triceps"
+	case MuscleForearms:
+		return "Forearms"
+	case MuscleAbs:
+		return "Abs"
+	case MuscleGlutes:
+		return "Glutes"
+	case MuscleQuads:
+		return "Quads"
+	case MuscleHamstrings:
+		return "Hamstrings"
+	case MuscleCalves:
+		return "Calves"
+	}
+	return string(m)
+}
+
+// DefaultTarget is the range of working sets a week used until the user sets one. Muscle groups that get
+// enough work from the exercises of other muscle groups start at 0 and are never under-trained.
+func (m MuscleGroup) DefaultTarget() (int64, int64) {
+	switch m {
+	case MuscleTraps, MuscleFrontDelts, MuscleForearms, MuscleAbs, MuscleGlutes:
+		return 0, 12
+	case MuscleRearDelts, MuscleHamstrings, MuscleCalves, MuscleTriceps:
+		return 6, 16
+	}
+	return 10, 20
+}
+
+// MuscleRole is how much an exercise trains a muscle group, a set counts as half a set for a secondary one.
+type MuscleRole string
+
+const (
+	MusclePrimary   MuscleRole = "primary"
+	MuscleSecondary MuscleRole = "secondary"
+)
+
+// ExerciseMuscleGroup tags an exercise with a muscle group it trains.
+type ExerciseMuscleGroup struct {
+	ExerciseID  int64
+	MuscleGroup MuscleGroup
+	Role        MuscleRole
+}
+
+// FormatMuscleGroups names the muscle groups, primary ones first, like "Chest, Triceps, Front delts". It
+// is what the MuscleGroups text of a tagged exercise is kept as.
+func FormatMuscleGroups(primary []MuscleGroup, secondary []MuscleGroup) string {
+	names := []string{}
+	for _, muscleGroup := range append(append([]MuscleGroup{}, primary...), secondary...) {
+		names = append(names, muscleGroup.Name())
+	}
+	return strings.Join(names, ", ")
+}
+
+// GetExerciseMuscleGroups returns the muscle groups the exercise is tagged with, primary ones first.
+func GetExerciseMuscleGroups(exerciseId int64, db *sql.DB) ([]ExerciseMuscleGroup, error) {
+	rows, err := db.Query(`
+	SELECT ExerciseID, MuscleGroup, Role
+	FROM exercise_muscle_groups
+	WHERE ExerciseID=?
+	ORDER BY Role, rowid
+	`, exerciseId)
+	if err != nil {
+		log.Printf("GetExerciseMuscleGroups Error: %s", err.Error())
+		return nil, err
+	}
+
+	return scanExerciseMuscleGroups(rows, "GetExerciseMuscleGroups")
+}
+
+// GetUserExerciseMuscleGroups returns the muscle groups of every exercise in the user's library, primary
+// ones first.
+func GetUserExerciseMuscleGroups(userId int64, db *sql.DB) ([]ExerciseMuscleGroup, error) {
+	rows, err := db.Query(`
+	SELECT emg.ExerciseID, emg.MuscleGroup, emg.Role
+	FROM exercise_muscle_groups emg
+	INNER JOIN exercises e ON e.ID = emg.ExerciseID
+	WHERE e.UserID=?
+	ORDER BY emg.ExerciseID, emg.Role, emg.rowid
+	`, userId)
+	if err != nil {
+		log.Printf("GetUserExerciseMuscleGroups Error: %s", err.Error())
+		return nil, err
+	}
+
+	return scanExerciseMuscleGroups(rows, "GetUserExerciseMuscleGroups")
+}
+
+// HasExerciseMuscleGroups reports whether any exercise in the user's library is tagged with a muscle group.
+func HasExerciseMuscleGroups(userId int64, db *sql.DB) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`
+	SELECT EXISTS (
+		SELECT 1
+		FROM exercise_muscle_groups emg
+		INNER JOIN exercises e ON e.ID = emg.ExerciseID
+		WHERE e.UserID=?
+	)
+	`, userId).Scan(&exists)
+	if err != nil {
+		log.Printf("HasExerciseMuscleGroups Error: %s", err.Error())
+	}
+	return exists, err
+}
+
+func scanExerciseMuscleGroups(rows *sql.Rows, context string) ([]ExerciseMuscleGroup, error) {
+	defer rows.Close()
+
+	var err error
+	muscleGroups := []ExerciseMuscleGroup{}
+	for rows.Next() {
+		muscleGroup := ExerciseMuscleGroup{}
+		if err = rows.Scan(&muscleGroup.ExerciseID, &muscleGroup.MuscleGroup, &muscleGroup.Role); err != nil {
+			log.Printf("%s Error: %s", context, err.Error())
+			break
+		}
+		muscleGroups = append(muscleGroups, muscleGroup)
+	}
+
+	return muscleGroups, err
+}
+
+// SetExerciseMuscleGroups replaces the muscle groups of the user's exercise and keeps its MuscleGroups text
+// in step with them. A muscle group both primary and secondary is primary.
+func SetExerciseMuscleGroups(userId int64, exerciseId int64, primary []MuscleGroup, secondary []MuscleGroup, db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+	UPDATE exercises
+	SET MuscleGroups=?
+	WHERE ID=? AND UserID=?
+	`, FormatMuscleGroups(primary, secondary), exerciseId, userId)
+	if err != nil {
+		log.Printf("SetExerciseMuscleGroups Error: %s", err.Error())
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err = tx.Exec("DELETE FROM exercise_muscle_groups WHERE ExerciseID=?", exerciseId); err != nil {
+		log.Printf("SetExerciseMuscleGroups Error: %s", err.Error())
+		return err
+	}
+
+	roles := map[MuscleRole][]MuscleGroup{MusclePrimary: primary, MuscleSecondary: secondary}
+	for _, role := range []MuscleRole{MusclePrimary, MuscleSecondary} {
+		for _, muscleGroup := range roles[role] {
+			_, err = tx.Exec(`
+			INSERT OR IGNORE INTO exercise_muscle_groups (ExerciseID, MuscleGroup, Role)
+			VALUES (?, ?, ?)
+			`, exerciseId, muscleGroup, role)
+			if err != nil {
+				log.Printf("SetExerciseMuscleGroups Error: %s", err.Error())
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// MuscleGroupTarget is the range of working sets a week the user aims for in a muscle group.
+type MuscleGroupTarget struct {
+	MuscleGroup MuscleGroup
+	SetsFrom    int64
+	SetsTo      int64
+}
+
+// GetMuscleGroupTargets returns a target for every muscle group of the taxonomy, the default one where
+// the user has not set their own.
+func GetMuscleGroupTargets(userId int64, db *sql.DB) ([]MuscleGroupTarget, error) {
+	rows, err := db.Query(`
+	SELECT MuscleGroup, SetsFrom, SetsTo
+	FROM muscle_group_targets
+	WHERE UserID=?
+	`, userId)
+	if err != nil {
+		log.Printf("GetMuscleGroupTargets Error: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	saved := map[MuscleGroup]MuscleGroupTarget{}
+	for rows.Next() {
+		target := MuscleGroupTarget{}
+		if err = rows.Scan(&target.MuscleGroup, &target.SetsFrom, &target.SetsTo); err != nil {
+			log.Printf("GetMuscleGroupTargets Error: %s", err.Error())
+			return nil, err
+		}
+		saved[target.MuscleGroup] = target
+	}
+
+	targets := []MuscleGroupTarget{}
+	for _, muscleGroup := range MuscleGroups {
+		target, ok := saved[muscleGroup]
+		if !ok {
+			target.MuscleGroup = muscleGroup
+			target.SetsFrom, target.SetsTo = muscleGroup.DefaultTarget()
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// SaveMuscleGroupTargets stores the targets of the user in one transaction.
+func SaveMuscleGroupTargets(userId int64, targets []MuscleGroupTarget, db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, target := range targets {
+		_, err = tx.Exec(`
+		INSERT INTO muscle_group_targets (UserID, MuscleGroup, SetsFrom, SetsTo)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (UserID, MuscleGroup) DO UPDATE SET SetsFrom=excluded.SetsFrom, SetsTo=excluded.SetsTo
+		`, userId, target.MuscleGroup, target.SetsFrom, target.SetsTo)
+		if err != nil {
+			log.Printf("SaveMuscleGroupTargets Error: %s", err.Error())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// MuscleGroupVolume adds up the completed sets, warm-ups left out, of the exercises tagged with a muscle
+// group in a role. Volume is the weight times the reps of the exercises measured in weight and reps.
+type MuscleGroupVolume struct {
+	MuscleGroup MuscleGroup
+	Role        MuscleRole
+	Sets        int64
+	Volume      float64
+}
+
+// GetMuscleGroupVolumes returns the sets and volume per muscle group and role of the workouts of the user
+// started from from until to.
+func GetMuscleGroupVolumes(userId int64, from time.Time, to time.Time, db *sql.DB) ([]MuscleGroupVolume, error) {
+	rows, err := db.Query(`
+	SELECT emg.MuscleGroup, emg.Role, COUNT(*), COALESCE(SUM(CASE WHEN e.Measurement=? THEN ws.Weight * ws.Reps ELSE 0 END), 0)
+	FROM workouts w
+	INNER JOIN workout_sets ws ON ws.WorkoutID = w.ID
+	INNER JOIN exercises e ON e.ID = ws.ExerciseID
+	INNER JOIN exercise_muscle_groups emg ON emg.ExerciseID = ws.ExerciseID
+	WHERE w.UserID=? AND w.StartedAt >= ? AND w.StartedAt < ?
+	AND ws.SetType<>? AND ws.SetRating IN (?, ?)
+	GROUP BY emg.MuscleGroup, emg.Role
+	`, MeasurementWeightReps, userId, from.UTC().Format(time.DateTime), to.UTC().Format(time.DateTime), SetWarmup, SetGood, SetBad)
+	if err != nil {
+		log.Printf("GetMuscleGroupVolumes Error: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	volumes := []MuscleGroupVolume{}
+	for rows.Next() {
+		volume := MuscleGroupVolume{}
+		if err = rows.Scan(&volume.MuscleGroup, &volume.Role, &volume.Sets, &volume.Volume); err != nil {
+			log.Printf("GetMuscleGroupVolumes Error: %s", err.Error())
+			break
+		}
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, err
+}
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CreatePasswordResetToken stores the hash of a reset token, the token itself is only ever sent to the user.
+func CreatePasswordResetToken(userId int64, tokenHash string, expiresAt time.Time, db *sql.DB) error {
+	_, err := db.Exec(`
+	INSERT INTO password_reset_tokens (UserID, TokenHash, ExpiresAt)
+	VALUES (?, ?, ?)
+	`, userId, tokenHash, expiresAt.UTC().Format(time.DateTime))
+
+	if err != nil {
+		log.Printf("CreatePasswordResetToken Error: %s", err.Error())
+	}
+	return err
+}
+
+// ResetUserPassword sets a new password for the user of an unused, unexpired reset token. The token, and
+// every other token of the user, is only used up when the new password is saved.
+func ResetUserPassword(tokenHash string, password string, db *sql.DB) error {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("ResetUserPassword Error: %s", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var userId int64
+	err = tx.QueryRow(`
+	UPDATE password_reset_tokens
+	SET UsedAt=CURRENT_TIMESTAMP
+	WHERE TokenHash=? AND UsedAt IS NULL AND datetime(ExpiresAt) > datetime('now')
+	RETURNING UserID
+	`, tokenHash).Scan(&userId)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("ResetUserPassword Error: %s", err.Error())
+		}
+		return err
+	}
+
+	result, err := tx.Exec(`
+	UPDATE users
+	SET PasswordHash=?
+	WHERE ID=?
+	`, passwordHash, userId)
+	if err != nil {
+		log.Printf("ResetUserPassword Error: %s", err.Error())
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err = tx.Exec("DELETE FROM password_reset_tokens WHERE UserID=?", userId); err != nil {
+		log.Printf("ResetUserPassword Error: %s", err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("ResetUserPassword Error: %s", err.Error())
+	}
+	return err
+}
+
+func IsPasswordResetTokenValid(tokenHash string, db *sql.DB) bool {
+	row := db.QueryRow(`
+	SELECT COUNT(*) FROM password_reset_tokens
+	WHERE TokenHash=? AND UsedAt IS NULL AND datetime(ExpiresAt) > datetime('now')
+	`, tokenHash)
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		log.Printf("IsPasswordResetTokenValid Error: %s", err.Error())
+		return false
+	}
+
+	return count > 0
+}
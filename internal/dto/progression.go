@@ -0,0 +1,124 @@
+package dto
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+type ProgressionStatus string
+
+const (
+	ProgressionPending  ProgressionStatus = "pending"
+	ProgressionAccepted ProgressionStatus = "accepted"
+	ProgressionDeclined ProgressionStatus = "declined"
+	ProgressionApplied  ProgressionStatus = "applied"
+)
+
+type Progression struct {
+	ID         int64
+	ExerciseID int64
+	WorkoutID  int64
+	WeightFrom float64
+	WeightTo   float64
+	RepsFrom   float64
+	RepsTo     float64
+	Reason     string
+	Status     ProgressionStatus
+	CreatedAt  time.Time
+	DecidedAt  sql.NullTime
+}
+
+// CreateProgression stores a new target suggestion for an exercise in the split of the workout, replacing any
+// suggestion still pending for it. Suggestions created with ProgressionApplied are considered decided right away.
+func CreateProgression(exerciseId int64, workoutId int64, weightFrom float64, weightTo float64, repsFrom float64, repsTo float64, reason string, status ProgressionStatus, db *sql.DB) (Progression, error) {
+	_, err := db.Exec(`
+	DELETE FROM exercise_progressions
+	WHERE ExerciseID=? AND Status=? AND WorkoutID IN (
+		SELECT ID FROM workouts WHERE SplitID=(SELECT SplitID FROM workouts WHERE ID=?)
+	)
+	`, exerciseId, ProgressionPending, workoutId)
+	if err != nil {
+		log.Printf("CreateProgression Error: %s", err.Error())
+		return Progression{}, err
+	}
+
+	row := db.QueryRow(`
+	INSERT INTO exercise_progressions (ExerciseID, WorkoutID, WeightFrom, WeightTo, RepsFrom, RepsTo, Reason, Status, DecidedAt)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN ? = 'pending' THEN NULL ELSE CURRENT_TIMESTAMP END)
+	RETURNING ID, ExerciseID, WorkoutID, WeightFrom, WeightTo, RepsFrom, RepsTo, Reason, Status, CreatedAt, DecidedAt
+	`, exerciseId, workoutId, weightFrom, weightTo, repsFrom, repsTo, reason, status, status)
+
+	progression := Progression{}
+	if err = row.Scan(&progression.ID, &progression.ExerciseID, &progression.WorkoutID, &progression.WeightFrom, &progression.WeightTo, &progression.RepsFrom, &progression.RepsTo, &progression.Reason, &progression.Status, &progression.CreatedAt, &progression.DecidedAt); err != nil {
+		log.Printf("CreateProgression Error: %s", err.Error())
+		return Progression{}, err
+	}
+
+	return progression, nil
+}
+
+func GetPendingProgression(splitId int64, exerciseId int64, db *sql.DB) (Progression, error) {
+	row := db.QueryRow(`
+	SELECT ID, ExerciseID, WorkoutID, WeightFrom, WeightTo, RepsFrom, RepsTo, Reason, Status, CreatedAt, DecidedAt
+	FROM exercise_progressions
+	WHERE ExerciseID=? AND Status=? AND WorkoutID IN (
+		SELECT ID FROM workouts WHERE SplitID=?
+	)
+	ORDER BY CreatedAt DESC
+	LIMIT 1
+	`, exerciseId, ProgressionPending, splitId)
+
+	progression := Progression{}
+	err := row.Scan(&progression.ID, &progression.ExerciseID, &progression.WorkoutID, &progression.WeightFrom, &progression.WeightTo, &progression.RepsFrom, &progression.RepsTo, &progression.Reason, &progression.Status, &progression.CreatedAt, &progression.DecidedAt)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("GetPendingProgression Error: %s", err.Error())
+	}
+
+	return progression, err
+}
+
+// GetLastProgressionDecision returns when the targets of an exercise in the split were last accepted, declined
+// or applied. The zero time is returned when no decision has been made yet.
+func GetLastProgressionDecision(splitId int64, exerciseId int64, db *sql.DB) (time.Time, error) {
+	row := db.QueryRow(`
+	SELECT DecidedAt FROM exercise_progressions
+	WHERE ExerciseID=? AND DecidedAt IS NOT NULL AND WorkoutID IN (
+		SELECT ID FROM workouts WHERE SplitID=?
+	)
+	ORDER BY DecidedAt DESC
+	LIMIT 1
+	`, exerciseId, splitId)
+
+	var decidedAt time.Time
+	if err := row.Scan(&decidedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		log.Printf("GetLastProgressionDecision Error: %s", err.Error())
+		return time.Time{}, err
+	}
+
+	return decidedAt, nil
+}
+
+func DecideProgression(userId int64, progressionId int64, status ProgressionStatus, db *sql.DB) (Progression, error) {
+	row := db.QueryRow(`
+	UPDATE exercise_progressions
+	SET Status=?, DecidedAt=CURRENT_TIMESTAMP
+	WHERE ID=? AND Status=? AND ExerciseID IN (
+		SELECT ID FROM exercises WHERE UserID=?
+	)
+	RETURNING ID, ExerciseID, WorkoutID, WeightFrom, WeightTo, RepsFrom, RepsTo, Reason, Status, CreatedAt, DecidedAt
+	`, status, progressionId, ProgressionPending, userId)
+
+	progression := Progression{}
+	if err := row.Scan(&progression.ID, &progression.ExerciseID, &progression.WorkoutID, &progression.WeightFrom, &progression.WeightTo, &progression.RepsFrom, &progression.RepsTo, &progression.Reason, &progression.Status, &progression.CreatedAt, &progression.DecidedAt); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("DecideProgression Error: %s", err.Error())
+		}
+		return Progression{}, err
+	}
+
+	return progression, nil
+}
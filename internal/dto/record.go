@@ -0,0 +1,178 @@
+package dto
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+type RecordType string
+
+const (
+	RecordHeaviestWeight RecordType = "heaviest_weight"
+	RecordRepsAtWeight   RecordType = "reps_at_weight"
+	RecordEstimatedOneRM RecordType = "estimated_1rm"
+	RecordSessionVolume  RecordType = "session_volume"
+)
+
+// RecordTypes lists the kinds of records in the order they are shown.
+var RecordTypes = []RecordType{RecordHeaviestWeight, RecordEstimatedOneRM, RecordSessionVolume, RecordRepsAtWeight}
+
+func (t RecordType) Name() string {
+	switch t {
+	case RecordHeaviestWeight:
+		return "Heaviest weight"
+	case RecordRepsAtWeight:
+		return "Most reps at a weight"
+	case RecordEstimatedOneRM:
+		return "Estimated 1RM"
+	case RecordSessionVolume:
+		return "Best session volume"
+	}
+	return string(t)
+}
+
+// PersonalRecord is a set, or for session volume a whole workout, that beat the best of its exercise. Value
+// is what was beaten: the weight, the reps at Weight, the estimated one rep max or the volume.
+type PersonalRecord struct {
+	ID            int64
+	UserID        int64
+	ExerciseID    int64
+	WorkoutID     int64
+	SetNumber     sql.NullInt64
+	Type          RecordType
+	Weight        float64
+	Reps          int64
+	Value         float64
+	PreviousValue sql.NullFloat64
+	AchievedAt    time.Time
+}
+
+const personalRecordColumns = "ID, UserID, ExerciseID, WorkoutID, SetNumber, RecordType, Weight, Reps, Value, PreviousValue, AchievedAt"
+
+func scanPersonalRecords(rows *sql.Rows, context string) ([]PersonalRecord, error) {
+	defer rows.Close()
+
+	var err error
+	records := []PersonalRecord{}
+	for rows.Next() {
+		record := PersonalRecord{}
+		if err = rows.Scan(&record.ID, &record.UserID, &record.ExerciseID, &record.WorkoutID, &record.SetNumber, &record.Type, &record.Weight, &record.Reps, &record.Value, &record.PreviousValue, &record.AchievedAt); err != nil {
+			log.Printf("%s Error: %s", context, err.Error())
+			break
+		}
+		records = append(records, record)
+	}
+
+	return records, err
+}
+
+// ReplacePersonalRecords swaps the records of the exercise for the given ones in one transaction.
+func ReplacePersonalRecords(userId int64, exerciseId int64, records []PersonalRecord, db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM personal_records WHERE UserID=? AND ExerciseID=?", userId, exerciseId); err != nil {
+		log.Printf("ReplacePersonalRecords Error: %s", err.Error())
+		return err
+	}
+
+	for _, record := range records {
+		_, err = tx.Exec(`
+		INSERT INTO personal_records (UserID, ExerciseID, WorkoutID, SetNumber, RecordType, Weight, Reps, Value, PreviousValue, AchievedAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, userId, exerciseId, record.WorkoutID, record.SetNumber, record.Type, record.Weight, record.Reps, record.Value, record.PreviousValue, record.AchievedAt)
+		if err != nil {
+			log.Printf("ReplacePersonalRecords Error: %s", err.Error())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetExercisePersonalRecords returns the record timeline of the exercise, newest first.
+func GetExercisePersonalRecords(userId int64, exerciseId int64, db *sql.DB) ([]PersonalRecord, error) {
+	rows, err := db.Query(`
+	SELECT `+personalRecordColumns+`
+	FROM personal_records
+	WHERE UserID=? AND ExerciseID=?
+	ORDER BY AchievedAt DESC, ID DESC
+	`, userId, exerciseId)
+	if err != nil {
+		log.Printf("GetExercisePersonalRecords Error: %s", err.Error())
+		return nil, err
+	}
+
+	return scanPersonalRecords(rows, "GetExercisePersonalRecords")
+}
+
+// GetWorkoutPersonalRecords returns the records set in the workout in the order they were set.
+func GetWorkoutPersonalRecords(workoutId int64, db *sql.DB) ([]PersonalRecord, error) {
+	rows, err := db.Query(`
+	SELECT `+personalRecordColumns+`
+	FROM personal_records
+	WHERE WorkoutID=?
+	ORDER BY AchievedAt ASC, ID ASC
+	`, workoutId)
+	if err != nil {
+		log.Printf("GetWorkoutPersonalRecords Error: %s", err.Error())
+		return nil, err
+	}
+
+	return scanPersonalRecords(rows, "GetWorkoutPersonalRecords")
+}
+
+// GetWorkoutRecordExercises returns the exercises whose records depend on the workout, the ones with sets
+// in it and the ones that had records in it.
+func GetWorkoutRecordExercises(workoutId int64, db *sql.DB) ([]int64, error) {
+	rows, err := db.Query(`
+	SELECT ExerciseID FROM workout_sets WHERE WorkoutID=?
+	UNION
+	SELECT ExerciseID FROM personal_records WHERE WorkoutID=?
+	`, workoutId, workoutId)
+	if err != nil {
+		log.Printf("GetWorkoutRecordExercises Error: %s", err.Error())
+		return nil, err
+	}
+
+	return scanIds(rows, "GetWorkoutRecordExercises")
+}
+
+// GetRecordExercises returns the exercises the user has logged sets of or has records of.
+func GetRecordExercises(userId int64, db *sql.DB) ([]int64, error) {
+	rows, err := db.Query(`
+	SELECT ws.ExerciseID
+	FROM workout_sets ws
+	INNER JOIN workouts w ON w.ID = ws.WorkoutID
+	WHERE w.UserID=?
+	UNION
+	SELECT ExerciseID FROM personal_records WHERE UserID=?
+	`, userId, userId)
+	if err != nil {
+		log.Printf("GetRecordExercises Error: %s", err.Error())
+		return nil, err
+	}
+
+	return scanIds(rows, "GetRecordExercises")
+}
+
+func scanIds(rows *sql.Rows, context string) ([]int64, error) {
+	defer rows.Close()
+
+	var err error
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			log.Printf("%s Error: %s", context, err.Error())
+			break
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, err
+}
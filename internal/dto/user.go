@@ -3,26 +3,31 @@ package dto
 import (
 	"crypto/sha256"
 	"database/sql"
+	"dumbbell/internal/utils"
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	ID           int64
-	Email        string
-	PasswordHash []byte
+	ID              int64
+	Email           string
+	PasswordHash    []byte
+	AutoProgression bool
+	// Timezone is the IANA name of the time zone of the user, empty when it is not known yet.
+	Timezone string
 }
 
 func GetUserByEmail(email string, db *sql.DB) (User, error) {
 	row := db.QueryRow(`
-	SELECT ID, Email, PasswordHash FROM users WHERE Email=?
+	SELECT ID, Email, PasswordHash, Timezone FROM users WHERE Email=?
 	`, email)
 
 	user := User{}
-	if err := row.Scan(&user.ID, &user.Email, &user.PasswordHash); err != nil {
+	if err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Timezone); err != nil {
 		return User{}, err
 	}
 	return user, nil
@@ -30,11 +35,11 @@ func GetUserByEmail(email string, db *sql.DB) (User, error) {
 
 func GetUserById(id int64, db *sql.DB) (User, error) {
 	row := db.QueryRow(`
-	SELECT ID, Email FROM users WHERE ID=?
+	SELECT ID, Email, PasswordHash, AutoProgression, Timezone FROM users WHERE ID=?
 	`, id)
 
 	user := User{}
-	if err := row.Scan(&user.ID, &user.Email); err != nil {
+	if err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.AutoProgression, &user.Timezone); err != nil {
 		return User{}, err
 	}
 	return user, nil
@@ -62,6 +67,63 @@ func CreateUser(email, password string, db *sql.DB) (User, error) {
 	return user, nil
 }
 
+func UpdateUserAutoProgression(id int64, autoProgression bool, db *sql.DB) (User, error) {
+	row := db.QueryRow(`
+	UPDATE users
+	SET AutoProgression=?
+	WHERE ID=?
+	RETURNING ID, Email, AutoProgression
+	`, autoProgression, id)
+
+	user := User{}
+	if err := row.Scan(&user.ID, &user.Email, &user.AutoProgression); err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+func UpdateUserTimezone(id int64, timezone string, db *sql.DB) error {
+	result, err := db.Exec(`
+	UPDATE users
+	SET Timezone=?
+	WHERE ID=?
+	`, timezone, id)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func UpdateUserPassword(id int64, password string, db *sql.DB) error {
+	passwordHash, generatePasswordErr := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if generatePasswordErr != nil {
+		return generatePasswordErr
+	}
+
+	result, err := db.Exec(`
+	UPDATE users
+	SET PasswordHash=?
+	WHERE ID=?
+	`, passwordHash, id)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Location returns the time zone of the user, the one of the server until it is known.
+func (u *User) Location() *time.Location {
+	return utils.LoadLocation(u.Timezone)
+}
+
 func (u *User) GetImageURL() string {
 	normalizedEmail := strings.ToLower(strings.Trim(u.Email, " "))
 	sha256 := sha256.New()
@@ -16,6 +16,64 @@ const (
 	SetCurrent     SetStatus = "current"
 )
 
+type SetType string
+
+const (
+	SetWarmup  SetType = "warmup"
+	SetWorking SetType = "working"
+	// A drop set chains off the set before it, it is started right away with a lower weight.
+	SetDrop SetType = "drop"
+	// The reps of an AMRAP set are as many as could be done, the logged reps are the ones achieved.
+	SetAmrap   SetType = "amrap"
+	SetFailure SetType = "failure"
+)
+
+var SetTypes = []SetType{SetWarmup, SetWorking, SetDrop, SetAmrap, SetFailure}
+
+func (t SetType) IsValid() bool {
+	for _, setType := range SetTypes {
+		if t == setType {
+			return true
+		}
+	}
+	return false
+}
+
+// CountsAsSet is true for the types that use up one of the Sets of the exercise, warm-up and drop sets
+// are done on top of them.
+func (t SetType) CountsAsSet() bool {
+	return t != SetWarmup && t != SetDrop
+}
+
+func (t SetType) Name() string {
+	switch t {
+	case SetWarmup:
+		return "Warm-up"
+	case SetDrop:
+		return "Drop set"
+	case SetAmrap:
+		return "AMRAP"
+	case SetFailure:
+		return "Failure"
+	}
+	return "Working"
+}
+
+// Short marks the set in the stepper and the history, working sets are left unmarked.
+func (t SetType) Short() string {
+	switch t {
+	case SetWarmup:
+		return "W"
+	case SetDrop:
+		return "D"
+	case SetAmrap:
+		return "AMRAP"
+	case SetFailure:
+		return "F"
+	}
+	return ""
+}
+
 type Workout struct {
 	ID          int64
 	UserID      int64
@@ -31,15 +89,42 @@ type WorkoutSet struct {
 	StartedAt   time.Time
 	CompletedAt sql.NullTime
 	SetRating   SetStatus
+	SetType     SetType
 	WeightFrom  float64
 	WeightTo    float64
 	RepsFrom    float64
 	RepsTo      float64
-	Sets        int64
+	Weight      float64
+	Reps        int64
+	// Seconds is the time the set was held or took, Distance is in meters, both are 0 for exercises
+	// measured in weight and reps.
+	Seconds  int64
+	Distance float64
+	Sets     int64
+	// RestEndsAt is when the rest before the set is over, it is not valid for sets without a rest.
+	RestEndsAt sql.NullTime
+	// RestSeconds is how long the rest before the set actually was, it is not valid until the rest is over.
+	RestSeconds sql.NullInt64
+}
+
+// IsResting is true while the rest before the set is running.
+func (ws *WorkoutSet) IsResting() bool {
+	return ws.RestEndsAt.Valid && !ws.RestSeconds.Valid
 }
 
 var ErrorSetLimitReached = errors.New("Set limit reached")
 var ErrorWorkoutNotUpdated = errors.New("Workout not updated")
+var ErrorNotResting = errors.New("The active set has no rest running")
+var ErrorNothingToUndo = errors.New("No set has been logged in the workout")
+
+// endRestColumns ends a running rest at end, the set starts when the rest is over.
+func endRestColumns(end string) string {
+	return `
+		RestSeconds=CASE WHEN RestEndsAt IS NOT NULL AND RestSeconds IS NULL
+			THEN MAX(0, CAST(strftime('%s', ` + end + `) AS INTEGER) - CAST(strftime('%s', StartedAt) AS INTEGER))
+			ELSE RestSeconds END,
+		StartedAt=CASE WHEN RestEndsAt IS NOT NULL AND RestSeconds IS NULL THEN ` + end + ` ELSE StartedAt END`
+}
 
 func NewWorkout(splitId int64, userId int64, db *sql.DB) (Workout, error) {
 	row := db.QueryRow("INSERT INTO workouts (UserID, SplitID) VALUES (?,?) RETURNING ID, UserID, SplitID, StartedAt, CompletedAt", userId, splitId)
@@ -70,7 +155,7 @@ func DeleteWorkout(userId int64, workoutId int64, db *sql.DB) error {
 	}
 
 	if rows == 0 {
-		log.Printf("Delete workout, no rows to delete: %s", err.Error())
+		log.Printf("Delete workout, no rows to delete: workout=%d", workoutId)
 		return sql.ErrNoRows
 	}
 
@@ -100,15 +185,16 @@ func GetWorkout(userId int64, workoutId int64, db *sql.DB) (Workout, error) {
 	return workout, err
 }
 
-func GetAllCompletedWorkouts(userId int64, db *sql.DB) ([]Workout, error) {
+// GetCompletedWorkoutsSince returns the completed workouts of the user started from since on.
+func GetCompletedWorkoutsSince(userId int64, since time.Time, db *sql.DB) ([]Workout, error) {
 	rows, err := db.Query(`
 	SELECT ID, UserID, SplitID, StartedAt, CompletedAt FROM workouts
-	WHERE UserID=? AND CompletedAt IS NOT NULL
+	WHERE UserID=? AND StartedAt >= ? AND CompletedAt IS NOT NULL
 	ORDER BY StartedAt
-	`, userId)
+	`, userId, since.UTC().Format(time.DateTime))
 
 	if err != nil {
-		log.Printf("GetAllCompletedWorkouts error: %s", err.Error())
+		log.Printf("GetCompletedWorkoutsSince error: %s", err.Error())
 		return nil, err
 	}
 
@@ -125,6 +211,67 @@ func GetAllCompletedWorkouts(userId int64, db *sql.DB) ([]Workout, error) {
 	return workouts, err
 }
 
+// GetCompletedWorkoutStarts returns when the completed workouts of the user started, oldest first.
+func GetCompletedWorkoutStarts(userId int64, db *sql.DB) ([]time.Time, error) {
+	rows, err := db.Query(`
+	SELECT StartedAt FROM workouts
+	WHERE UserID=? AND CompletedAt IS NOT NULL
+	ORDER BY StartedAt
+	`, userId)
+	if err != nil {
+		log.Printf("GetCompletedWorkoutStarts Error: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	startedAts := []time.Time{}
+	for rows.Next() {
+		var startedAt time.Time
+		if err = rows.Scan(&startedAt); err != nil {
+			log.Printf("GetCompletedWorkoutStarts Error: %s", err.Error())
+			break
+		}
+		startedAts = append(startedAts, startedAt)
+	}
+
+	return startedAts, err
+}
+
+// SplitWorkout is a completed workout with the name of the split it was done in.
+type SplitWorkout struct {
+	Workout
+	SplitName string
+}
+
+// GetCompletedSplitWorkoutsBetween returns the completed workouts of the user started from from until to,
+// oldest first, with the name of their split.
+func GetCompletedSplitWorkoutsBetween(userId int64, from time.Time, to time.Time, db *sql.DB) ([]SplitWorkout, error) {
+	rows, err := db.Query(`
+	SELECT w.ID, w.UserID, w.SplitID, w.StartedAt, w.CompletedAt, s.Name
+	FROM workouts w
+	INNER JOIN splits s ON s.ID = w.SplitID
+	WHERE w.UserID=? AND w.StartedAt >= ? AND w.StartedAt < ? AND w.CompletedAt IS NOT NULL
+	ORDER BY w.StartedAt
+	`, userId, from.UTC().Format(time.DateTime), to.UTC().Format(time.DateTime))
+	if err != nil {
+		log.Printf("GetCompletedSplitWorkoutsBetween Error: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	workouts := []SplitWorkout{}
+	for rows.Next() {
+		workout := SplitWorkout{}
+		if err = rows.Scan(&workout.ID, &workout.UserID, &workout.SplitID, &workout.StartedAt, &workout.CompletedAt, &workout.SplitName); err != nil {
+			log.Printf("GetCompletedSplitWorkoutsBetween Error: %s", err.Error())
+			break
+		}
+		workouts = append(workouts, workout)
+	}
+
+	return workouts, err
+}
+
 func GetAllCompletedWorkoutsForSplit(userId int64, splitId int64, db *sql.DB) ([]Workout, error) {
 	rows, err := db.Query(`
 	SELECT ID, UserID, SplitID, StartedAt, CompletedAt FROM workouts
@@ -150,6 +297,40 @@ func GetAllCompletedWorkoutsForSplit(userId int64, splitId int64, db *sql.DB) ([
 	return workouts, err
 }
 
+// GetLatestCompletedWorkoutsForExercise returns, newest first, up to limit completed workouts of the split
+// started after since in which the exercise was performed.
+func GetLatestCompletedWorkoutsForExercise(splitId int64, exerciseId int64, since time.Time, limit int, db *sql.DB) ([]Workout, error) {
+	rows, err := db.Query(`
+	SELECT ID, UserID, SplitID, StartedAt, CompletedAt FROM workouts
+	WHERE CompletedAt IS NOT NULL AND SplitID=?
+	AND datetime(StartedAt) > datetime(?)
+	AND ID IN (
+		SELECT DISTINCT WorkoutID
+		FROM workout_sets
+		WHERE ExerciseID=?
+	)
+	ORDER BY StartedAt DESC
+	LIMIT ?
+	`, splitId, since.UTC().Format(time.DateTime), exerciseId, limit)
+
+	if err != nil {
+		log.Printf("GetLatestCompletedWorkoutsForExercise error: %s", err.Error())
+		return nil, err
+	}
+
+	workouts := []Workout{}
+	for rows.Next() {
+		workout := Workout{}
+		if err = rows.Scan(&workout.ID, &workout.UserID, &workout.SplitID, &workout.StartedAt, &workout.CompletedAt); err != nil {
+			break
+		}
+
+		workouts = append(workouts, workout)
+	}
+
+	return workouts, err
+}
+
 func CompleteWorkout(workoutId int64, db *sql.DB) error {
 	result, err := db.Exec(`
 	UPDATE workouts
@@ -170,7 +351,7 @@ func CreateNewSet(workoutId int64, exerciseId int64, db *sql.DB) (WorkoutSet, er
 		return WorkoutSet{}, getActiveWorkoutSetErr
 	}
 
-	exercise, err := GetExercise(exerciseId, db)
+	exercise, err := GetWorkoutExercise(workoutId, exerciseId, db)
 	if err != nil {
 		return WorkoutSet{}, err
 	}
@@ -200,40 +381,55 @@ func CreateNewSet(workoutId int64, exerciseId int64, db *sql.DB) (WorkoutSet, er
 	}
 
 	if err = row.Scan(&workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating); err != nil {
-		log.Printf("NewSet Error: %s", err.Error())
-		return WorkoutSet{}, nil
+		log.Printf("CreateNewSet Error: %s", err.Error())
+		return WorkoutSet{}, err
 	}
 
 	return workoutSet, nil
 }
 
-func CreateNextSet(workoutId int64, rating SetStatus, db *sql.DB) (WorkoutSet, error) {
-	activeWorkoutSet, err := UpdateActiveWorkoutSet(workoutId, rating, db)
+// CreateNextSet completes the active set and starts the next one. Exercises in a superset or circuit take
+// turns set by set, the rest only starts once every exercise of the group had its turn in the round.
+func CreateNextSet(workoutId int64, rating SetStatus, setType SetType, weight float64, reps int64, seconds int64, distance float64, db *sql.DB) (WorkoutSet, error) {
+	activeWorkoutSet, err := UpdateActiveWorkoutSet(workoutId, rating, setType, weight, reps, seconds, distance, db)
 	if err != nil {
 		return WorkoutSet{}, err
 	}
 
-	exercise, err := GetExercise(activeWorkoutSet.ExerciseID, db)
+	exercise, err := GetWorkoutExercise(workoutId, activeWorkoutSet.ExerciseID, db)
 	if err != nil {
 		return WorkoutSet{}, err
 	}
 
-	setNumber := activeWorkoutSet.SetNumber + 1
-	if setNumber > exercise.Sets {
-		log.Print("NextSet Error set limit reached")
-		return WorkoutSet{}, ErrorSetLimitReached
+	next, setNumber, roundDone, err := nextWorkoutExercise(workoutId, exercise, setType, db)
+	if err != nil {
+		if err == ErrorSetLimitReached {
+			log.Print("NextSet Error set limit reached")
+		}
+		return WorkoutSet{}, err
 	}
 
+	restSeconds := exercise.RestSeconds
+	if !roundDone {
+		restSeconds = 0
+	}
+
+	return insertNextSet(workoutId, next, setNumber, restSeconds, db)
+}
+
+// insertNextSet starts the set with the number of the exercise, the rest before it starts now and a rest
+// of 0 seconds leaves RestEndsAt NULL.
+func insertNextSet(workoutId int64, exercise Exercise, setNumber int64, restSeconds int64, db *sql.DB) (WorkoutSet, error) {
 	row := db.QueryRow(`
-		INSERT INTO workout_sets (SetNumber,WorkoutID,ExerciseID,WeightFrom,WeightTo,RepsFrom,RepsTo)
-		VALUES (?,?,?,?,?,?,?)
-		RETURNING StartedAt, StartedAt, SetRating
-	`, setNumber, workoutId, activeWorkoutSet.ExerciseID, exercise.WeightFrom, exercise.WeightTo, exercise.RepsFrom, exercise.RepsTo)
+		INSERT INTO workout_sets (SetNumber,WorkoutID,ExerciseID,WeightFrom,WeightTo,RepsFrom,RepsTo,RestEndsAt)
+		VALUES (?,?,?,?,?,?,?,CASE WHEN ? > 0 THEN datetime('now', '+' || ? || ' seconds') END)
+		RETURNING StartedAt, StartedAt, SetRating, RestEndsAt
+	`, setNumber, workoutId, exercise.ID, exercise.WeightFrom, exercise.WeightTo, exercise.RepsFrom, exercise.RepsTo, restSeconds, restSeconds)
 
 	workoutSet := WorkoutSet{
 		SetNumber:  setNumber,
 		WorkoutID:  workoutId,
-		ExerciseID: activeWorkoutSet.ExerciseID,
+		ExerciseID: exercise.ID,
 		WeightFrom: exercise.WeightFrom,
 		WeightTo:   exercise.WeightTo,
 		RepsFrom:   exercise.RepsFrom,
@@ -241,16 +437,299 @@ func CreateNextSet(workoutId int64, rating SetStatus, db *sql.DB) (WorkoutSet, e
 		Sets:       exercise.Sets,
 	}
 
-	if err = row.Scan(&workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating); err != nil {
-		log.Printf("NewSet Error: %s", err.Error())
-		return WorkoutSet{}, nil
+	if err := row.Scan(&workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.RestEndsAt); err != nil {
+		log.Printf("insertNextSet Error: %s", err.Error())
+		return WorkoutSet{}, err
 	}
 
 	return workoutSet, nil
 }
 
+// SkipExercise drops the active set and skips the remaining sets of its exercise, the sets that were
+// logged are kept. In a superset or circuit the turn goes to the next exercise of the group that has sets
+// left, ErrorSetLimitReached means there is none and the workout goes on with another exercise.
+func SkipExercise(workoutId int64, db *sql.DB) (WorkoutSet, error) {
+	activeWorkoutSet, err := GetActiveWorkoutSet(workoutId, db)
+	if err != nil {
+		return WorkoutSet{}, err
+	}
+
+	exercise, err := GetWorkoutExercise(workoutId, activeWorkoutSet.ExerciseID, db)
+	if err != nil {
+		return WorkoutSet{}, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return WorkoutSet{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM workout_sets WHERE WorkoutID=? AND CompletedAt IS NULL", workoutId); err != nil {
+		log.Printf("SkipExercise Error: %s", err.Error())
+		return WorkoutSet{}, err
+	}
+
+	if _, err = tx.Exec("INSERT OR REPLACE INTO skipped_exercises (WorkoutID, ExerciseID) VALUES (?, ?)", workoutId, exercise.ID); err != nil {
+		log.Printf("SkipExercise Error: %s", err.Error())
+		return WorkoutSet{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return WorkoutSet{}, err
+	}
+
+	next, setNumber, _, err := nextWorkoutExercise(workoutId, exercise, SetWorking, db)
+	if err != nil {
+		return WorkoutSet{}, err
+	}
+
+	return insertNextSet(workoutId, next, setNumber, 0, db)
+}
+
+// UndoLastSet takes back the last thing done in the workout. The set that was logged last is opened again
+// with what was logged in it, or the exercise that was skipped last gets a set again. The active set was
+// never logged and is dropped.
+func UndoLastSet(workoutId int64, db *sql.DB) (WorkoutSet, error) {
+	var lastSet WorkoutSet
+	lastSetRow := db.QueryRow(`
+		SELECT SetNumber, ExerciseID, CompletedAt
+		FROM workout_sets
+		WHERE WorkoutID=? AND CompletedAt IS NOT NULL
+		ORDER BY CompletedAt DESC, SetNumber DESC
+		LIMIT 1
+	`, workoutId)
+	lastSetErr := lastSetRow.Scan(&lastSet.SetNumber, &lastSet.ExerciseID, &lastSet.CompletedAt)
+	if lastSetErr != nil && lastSetErr != sql.ErrNoRows {
+		log.Printf("UndoLastSet Error: %s", lastSetErr.Error())
+		return WorkoutSet{}, lastSetErr
+	}
+
+	var skippedExerciseId int64
+	var skippedAt time.Time
+	skipRow := db.QueryRow("SELECT ExerciseID, SkippedAt FROM skipped_exercises WHERE WorkoutID=? ORDER BY SkippedAt DESC LIMIT 1", workoutId)
+	skipErr := skipRow.Scan(&skippedExerciseId, &skippedAt)
+	if skipErr != nil && skipErr != sql.ErrNoRows {
+		log.Printf("UndoLastSet Error: %s", skipErr.Error())
+		return WorkoutSet{}, skipErr
+	}
+
+	if lastSetErr == sql.ErrNoRows && skipErr == sql.ErrNoRows {
+		return WorkoutSet{}, ErrorNothingToUndo
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return WorkoutSet{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM workout_sets WHERE WorkoutID=? AND CompletedAt IS NULL", workoutId); err != nil {
+		log.Printf("UndoLastSet Error: %s", err.Error())
+		return WorkoutSet{}, err
+	}
+
+	// An exercise is skipped after its last set was logged, at the same second the skip is the later one.
+	undoSkip := skipErr == nil && (lastSetErr == sql.ErrNoRows || !skippedAt.Before(lastSet.CompletedAt.Time))
+	if undoSkip {
+		if _, err = tx.Exec("DELETE FROM skipped_exercises WHERE WorkoutID=? AND ExerciseID=?", workoutId, skippedExerciseId); err != nil {
+			log.Printf("UndoLastSet Error: %s", err.Error())
+			return WorkoutSet{}, err
+		}
+	} else {
+		if _, err = tx.Exec(`
+			UPDATE workout_sets
+			SET CompletedAt=NULL, SetRating=?
+			WHERE WorkoutID=? AND ExerciseID=? AND SetNumber=?
+		`, SetCurrent, workoutId, lastSet.ExerciseID, lastSet.SetNumber); err != nil {
+			log.Printf("UndoLastSet Error: %s", err.Error())
+			return WorkoutSet{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return WorkoutSet{}, err
+	}
+
+	if !undoSkip {
+		return GetActiveWorkoutSet(workoutId, db)
+	}
+
+	exercise, err := GetWorkoutExercise(workoutId, skippedExerciseId, db)
+	if err != nil {
+		return WorkoutSet{}, err
+	}
+
+	var setNumber int64
+	if err = db.QueryRow("SELECT COALESCE(MAX(SetNumber), 0) + 1 FROM workout_sets WHERE WorkoutID=? AND ExerciseID=?", workoutId, exercise.ID).Scan(&setNumber); err != nil {
+		log.Printf("UndoLastSet Error: %s", err.Error())
+		return WorkoutSet{}, err
+	}
+
+	return insertNextSet(workoutId, exercise, setNumber, 0, db)
+}
+
+// CanUndo is true when UndoLastSet has a logged set or a skipped exercise to take back.
+func CanUndo(workoutId int64, db *sql.DB) (bool, error) {
+	var canUndo bool
+	err := db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM workout_sets WHERE WorkoutID=? AND CompletedAt IS NOT NULL)
+			OR EXISTS (SELECT 1 FROM skipped_exercises WHERE WorkoutID=?)
+	`, workoutId, workoutId).Scan(&canUndo)
+	if err != nil {
+		log.Printf("CanUndo Error: %s", err.Error())
+	}
+	return canUndo, err
+}
+
+// GetSkippedExercises returns the IDs of the exercises whose remaining sets were skipped in the workout.
+func GetSkippedExercises(workoutId int64, db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.Query("SELECT ExerciseID FROM skipped_exercises WHERE WorkoutID=?", workoutId)
+	if err != nil {
+		log.Printf("GetSkippedExercises Error: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	skipped := map[int64]bool{}
+	for rows.Next() {
+		var exerciseId int64
+		if err = rows.Scan(&exerciseId); err != nil {
+			log.Printf("GetSkippedExercises Error: %s", err.Error())
+			break
+		}
+		skipped[exerciseId] = true
+	}
+
+	return skipped, err
+}
+
+// DeleteActiveWorkoutSet drops the set that was started but never logged.
+func DeleteActiveWorkoutSet(workoutId int64, db *sql.DB) error {
+	_, err := db.Exec("DELETE FROM workout_sets WHERE WorkoutID=? AND CompletedAt IS NULL", workoutId)
+	if err != nil {
+		log.Printf("DeleteActiveWorkoutSet Error: %s", err.Error())
+	}
+	return err
+}
+
+// CreateDropSet completes the active set and chains a drop set of the same exercise off it. The drop set
+// starts right away without a rest and does not use up one of the Sets of the exercise.
+func CreateDropSet(workoutId int64, rating SetStatus, setType SetType, weight float64, reps int64, seconds int64, distance float64, db *sql.DB) (WorkoutSet, error) {
+	activeWorkoutSet, err := UpdateActiveWorkoutSet(workoutId, rating, setType, weight, reps, seconds, distance, db)
+	if err != nil {
+		return WorkoutSet{}, err
+	}
+
+	exercise, err := GetWorkoutExercise(workoutId, activeWorkoutSet.ExerciseID, db)
+	if err != nil {
+		return WorkoutSet{}, err
+	}
+
+	row := db.QueryRow(`
+		INSERT INTO workout_sets (SetNumber,WorkoutID,ExerciseID,WeightFrom,WeightTo,RepsFrom,RepsTo,SetType)
+		SELECT MAX(SetNumber) + 1, WorkoutID, ExerciseID, ?, ?, ?, ?, ?
+		FROM workout_sets
+		WHERE WorkoutID=? AND ExerciseID=?
+		RETURNING SetNumber, StartedAt, StartedAt, SetRating, SetType
+	`, exercise.WeightFrom, exercise.WeightTo, exercise.RepsFrom, exercise.RepsTo, SetDrop, workoutId, exercise.ID)
+
+	workoutSet := WorkoutSet{
+		WorkoutID:  workoutId,
+		ExerciseID: exercise.ID,
+		WeightFrom: exercise.WeightFrom,
+		WeightTo:   exercise.WeightTo,
+		RepsFrom:   exercise.RepsFrom,
+		RepsTo:     exercise.RepsTo,
+		Sets:       exercise.Sets,
+	}
+
+	if err = row.Scan(&workoutSet.SetNumber, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.SetType); err != nil {
+		log.Printf("CreateDropSet Error: %s", err.Error())
+		return WorkoutSet{}, err
+	}
+
+	return workoutSet, nil
+}
+
+// nextWorkoutExercise picks the exercise and set number of the set that follows a set of the given type
+// of the exercise. The exercises of a group take turns in GroupTurnOrder, skipping the ones whose sets
+// are used up or skipped, and roundDone is true when the turn goes back to the start of the round. An
+// exercise outside of a group is its own round. Warm-up and drop sets don't use up a set, the exercise
+// goes again.
+func nextWorkoutExercise(workoutId int64, exercise Exercise, setType SetType, db *sql.DB) (Exercise, int64, bool, error) {
+	group := []Exercise{exercise}
+	if exercise.GroupNumber != 0 {
+		exercises, err := GetAllExercises(exercise.SplitID, db)
+		if err != nil {
+			return Exercise{}, 0, false, err
+		}
+		group = GroupExercises(exercises, exercise)
+	}
+
+	workoutSets, err := GetWorkoutSets(workoutId, db)
+	if err != nil {
+		return Exercise{}, 0, false, err
+	}
+	group = GroupTurnOrder(group, workoutSets)
+
+	skipped, err := GetSkippedExercises(workoutId, db)
+	if err != nil {
+		return Exercise{}, 0, false, err
+	}
+
+	// Set numbers go up with every set, only the sets that count are held against the Sets of the exercise.
+	setNumbers := map[int64]int64{}
+	setCounts := map[int64]int64{}
+	for _, workoutSet := range workoutSets {
+		setNumbers[workoutSet.ExerciseID] = max(setNumbers[workoutSet.ExerciseID], workoutSet.SetNumber)
+		if workoutSet.SetType.CountsAsSet() {
+			setCounts[workoutSet.ExerciseID]++
+		}
+	}
+
+	if !setType.CountsAsSet() && !skipped[exercise.ID] && setCounts[exercise.ID] < exercise.Sets {
+		return exercise, setNumbers[exercise.ID] + 1, true, nil
+	}
+
+	current := 0
+	for i, member := range group {
+		if member.ID == exercise.ID {
+			current = i
+		}
+	}
+
+	for turn := 1; turn <= len(group); turn++ {
+		i := (current + turn) % len(group)
+		if !skipped[group[i].ID] && setCounts[group[i].ID] < group[i].Sets {
+			return group[i], setNumbers[group[i].ID] + 1, i <= current, nil
+		}
+	}
+
+	return Exercise{}, 0, false, ErrorSetLimitReached
+}
+
+// EndRest ends the running rest before the active set of the workout now and records how long it was.
+func EndRest(workoutId int64, db *sql.DB) (WorkoutSet, error) {
+	result, err := db.Exec(`
+		UPDATE workout_sets
+		SET `+endRestColumns("CURRENT_TIMESTAMP")+`
+		WHERE WorkoutID=? AND CompletedAt IS NULL AND RestEndsAt IS NOT NULL AND RestSeconds IS NULL
+	`, workoutId)
+	if err != nil {
+		log.Printf("EndRest Error: %s", err.Error())
+		return WorkoutSet{}, err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return WorkoutSet{}, ErrorNotResting
+	}
+
+	return GetActiveWorkoutSet(workoutId, db)
+}
+
 func GetCompletedWorkoutSets(workoutId int64, exerciseId int64, db *sql.DB) ([]WorkoutSet, error) {
-	rows, err := db.Query("SELECT SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo FROM workout_sets WHERE WorkoutID=? AND ExerciseID=? AND CompletedAt IS NOT NULL ORDER BY SetNumber ASC", workoutId, exerciseId)
+	rows, err := db.Query("SELECT SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo, Weight, Reps, Seconds, Distance, SetType FROM workout_sets WHERE WorkoutID=? AND ExerciseID=? AND CompletedAt IS NOT NULL ORDER BY SetNumber ASC", workoutId, exerciseId)
 	if err != nil {
 		log.Printf("GetCompletedWorkoutSets Error: %s", err.Error())
 		return nil, err
@@ -259,7 +738,7 @@ func GetCompletedWorkoutSets(workoutId int64, exerciseId int64, db *sql.DB) ([]W
 	workoutSets := []WorkoutSet{}
 	for rows.Next() {
 		workoutSet := WorkoutSet{}
-		if err = rows.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightTo, &workoutSet.WeightFrom, &workoutSet.RepsFrom, &workoutSet.RepsTo); err != nil {
+		if err = rows.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightFrom, &workoutSet.WeightTo, &workoutSet.RepsFrom, &workoutSet.RepsTo, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.SetType); err != nil {
 			log.Printf("GetCompletedWorkoutSets Error: %s", err.Error())
 			break
 		}
@@ -270,16 +749,16 @@ func GetCompletedWorkoutSets(workoutId int64, exerciseId int64, db *sql.DB) ([]W
 }
 
 func GetActiveWorkoutSet(workoutId int64, db *sql.DB) (WorkoutSet, error) {
-	row := db.QueryRow("SELECT SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo FROM workout_sets WHERE WorkoutID=? AND CompletedAt IS NULL", workoutId)
+	row := db.QueryRow("SELECT SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo, Weight, Reps, Seconds, Distance, SetType, RestEndsAt, RestSeconds FROM workout_sets WHERE WorkoutID=? AND CompletedAt IS NULL", workoutId)
 
 	var err error
 	workoutSet := WorkoutSet{}
-	if err = row.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightTo, &workoutSet.WeightFrom, &workoutSet.RepsFrom, &workoutSet.RepsTo); err != nil {
+	if err = row.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightFrom, &workoutSet.WeightTo, &workoutSet.RepsFrom, &workoutSet.RepsTo, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.SetType, &workoutSet.RestEndsAt, &workoutSet.RestSeconds); err != nil {
 		log.Printf("GetActiveWorkoutSet Error: %s", err.Error())
 		return WorkoutSet{}, err
 	}
 
-	exercise, err := GetExercise(workoutSet.ExerciseID, db)
+	exercise, err := GetWorkoutExercise(workoutId, workoutSet.ExerciseID, db)
 	if err != nil {
 		return WorkoutSet{}, err
 	}
@@ -288,28 +767,43 @@ func GetActiveWorkoutSet(workoutId int64, db *sql.DB) (WorkoutSet, error) {
 	return workoutSet, err
 }
 
-func GetAllWorkoutSets(userId int64, limit int, db *sql.DB) ([]WorkoutSet, error) {
+// LatestWorkoutSet is a logged set with the names of the split and exercise it was logged in.
+type LatestWorkoutSet struct {
+	WorkoutSet
+	SplitName    string
+	ExerciseName string
+}
+
+// GetLatestWorkoutSets returns the most recently logged sets of the user, the active set first, with their
+// split and exercise joined in. Only the latest limit workouts are looked at, which keeps the query from
+// sorting the whole history.
+func GetLatestWorkoutSets(userId int64, limit int, db *sql.DB) ([]LatestWorkoutSet, error) {
 	rows, err := db.Query(`
-	SELECT SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo 
-	FROM workout_sets 
-	WHERE WorkoutID IN (
+	SELECT ws.SetNumber, ws.WorkoutID, ws.ExerciseID, ws.StartedAt, ws.CompletedAt, ws.SetRating, ws.Weight, ws.Reps, ws.Seconds, ws.Distance, ws.SetType, s.Name, e.Name
+	FROM workout_sets ws
+	INNER JOIN workouts w ON w.ID = ws.WorkoutID
+	INNER JOIN splits s ON s.ID = w.SplitID
+	INNER JOIN exercises e ON e.ID = ws.ExerciseID
+	WHERE ws.WorkoutID IN (
 		SELECT ID FROM workouts
 		WHERE UserID=?
+		ORDER BY StartedAt DESC
+		LIMIT ?
 	)
-	ORDER BY CompletedAt DESC NULLS FIRST
+	ORDER BY ws.CompletedAt DESC NULLS FIRST
 	LIMIT ?
-	`, userId, limit)
-
+	`, userId, limit, limit)
 	if err != nil {
-		log.Printf("GetAllWorkoutSets error: %s", err.Error())
+		log.Printf("GetLatestWorkoutSets Error: %s", err.Error())
 		return nil, err
 	}
+	defer rows.Close()
 
-	workoutSets := []WorkoutSet{}
+	workoutSets := []LatestWorkoutSet{}
 	for rows.Next() {
-		workoutSet := WorkoutSet{}
-		if err = rows.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightTo, &workoutSet.WeightFrom, &workoutSet.RepsFrom, &workoutSet.RepsTo); err != nil {
-			log.Printf("GetAllWorkoutSets Error: %s", err.Error())
+		workoutSet := LatestWorkoutSet{}
+		if err = rows.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.SetType, &workoutSet.SplitName, &workoutSet.ExerciseName); err != nil {
+			log.Printf("GetLatestWorkoutSets Error: %s", err.Error())
 			break
 		}
 		workoutSets = append(workoutSets, workoutSet)
@@ -318,24 +812,75 @@ func GetAllWorkoutSets(userId int64, limit int, db *sql.DB) ([]WorkoutSet, error
 	return workoutSets, err
 }
 
-func GetAllWorkoutSetsForExercise(exerciseId int64, db *sql.DB) ([]WorkoutSet, error) {
+// SplitExerciseRatings counts the good and bad sets ever logged of an exercise in a split. A split without
+// exercises has one row with ExerciseID 0.
+type SplitExerciseRatings struct {
+	SplitID      int64
+	SplitName    string
+	ExerciseID   int64
+	ExerciseName string
+	Good         int
+	Bad          int
+}
+
+// GetSplitExerciseRatings returns the rating counts of every exercise of the splits of the user, ordered by
+// split and position. Warm-up sets are not counted.
+func GetSplitExerciseRatings(userId int64, db *sql.DB) ([]SplitExerciseRatings, error) {
 	rows, err := db.Query(`
-	SELECT SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo 
-	FROM workout_sets 
-	WHERE ExerciseID=?
-	ORDER BY CompletedAt DESC NULLS FIRST
-	`, exerciseId)
+	SELECT s.ID, s.Name, COALESCE(e.ID, 0), COALESCE(e.Name, ''), COALESCE(r.Good, 0), COALESCE(r.Bad, 0)
+	FROM splits s
+	LEFT JOIN split_exercises se ON se.SplitID = s.ID
+	LEFT JOIN exercises e ON e.ID = se.ExerciseID
+	LEFT JOIN (
+		SELECT ExerciseID, SUM(SetRating = ?) AS Good, SUM(SetRating = ?) AS Bad
+		FROM workout_sets
+		WHERE ExerciseID IN (SELECT ID FROM exercises WHERE UserID=?) AND SetType != ?
+		GROUP BY ExerciseID
+	) r ON r.ExerciseID = e.ID
+	WHERE s.UserID=?
+	ORDER BY s.ID, se.Position, se.ID
+	`, SetGood, SetBad, userId, SetWarmup, userId)
+	if err != nil {
+		log.Printf("GetSplitExerciseRatings Error: %s", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
 
+	ratings := []SplitExerciseRatings{}
+	for rows.Next() {
+		rating := SplitExerciseRatings{}
+		if err = rows.Scan(&rating.SplitID, &rating.SplitName, &rating.ExerciseID, &rating.ExerciseName, &rating.Good, &rating.Bad); err != nil {
+			log.Printf("GetSplitExerciseRatings Error: %s", err.Error())
+			break
+		}
+		ratings = append(ratings, rating)
+	}
+
+	return ratings, err
+}
+
+// GetExerciseHistorySets returns the logged sets of the exercise in the order they were done, the sets its records
+// and progress are worked out from. Warm-up sets are left out, the sets of the active workout are in it as soon as
+// they are logged.
+func GetExerciseHistorySets(userId int64, exerciseId int64, db *sql.DB) ([]WorkoutSet, error) {
+	rows, err := db.Query(`
+	SELECT ws.SetNumber, ws.WorkoutID, ws.ExerciseID, ws.StartedAt, ws.CompletedAt, ws.SetRating, ws.Weight, ws.Reps, ws.Seconds, ws.Distance, ws.SetType
+	FROM workout_sets ws
+	INNER JOIN workouts w ON w.ID = ws.WorkoutID
+	WHERE w.UserID=? AND ws.ExerciseID=? AND ws.CompletedAt IS NOT NULL AND ws.SetType != ?
+	ORDER BY w.StartedAt ASC, w.ID ASC, ws.CompletedAt ASC, ws.SetNumber ASC
+	`, userId, exerciseId, SetWarmup)
 	if err != nil {
-		log.Printf("GetAllWorkoutSetsForExercise error: %s", err.Error())
+		log.Printf("GetExerciseHistorySets Error: %s", err.Error())
 		return nil, err
 	}
+	defer rows.Close()
 
 	workoutSets := []WorkoutSet{}
 	for rows.Next() {
 		workoutSet := WorkoutSet{}
-		if err = rows.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightTo, &workoutSet.WeightFrom, &workoutSet.RepsFrom, &workoutSet.RepsTo); err != nil {
-			log.Printf("GetAllWorkoutSetsForExercise Error: %s", err.Error())
+		if err = rows.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.SetType); err != nil {
+			log.Printf("GetExerciseHistorySets Error: %s", err.Error())
 			break
 		}
 		workoutSets = append(workoutSets, workoutSet)
@@ -344,20 +889,212 @@ func GetAllWorkoutSetsForExercise(exerciseId int64, db *sql.DB) ([]WorkoutSet, e
 	return workoutSets, err
 }
 
-func UpdateActiveWorkoutSet(workoutId int64, rating SetStatus, db *sql.DB) (WorkoutSet, error) {
+// UpdateActiveWorkoutSet logs the active set. A rest that was never ended is taken to have lasted until
+// the timer ran out, or until now when the set is logged before that.
+func UpdateActiveWorkoutSet(workoutId int64, rating SetStatus, setType SetType, weight float64, reps int64, seconds int64, distance float64, db *sql.DB) (WorkoutSet, error) {
 	row := db.QueryRow(`
 		UPDATE workout_sets
-		SET CompletedAt=CURRENT_TIMESTAMP, SetRating=?
+		SET CompletedAt=CURRENT_TIMESTAMP, SetRating=?, SetType=?, Weight=?, Reps=?, Seconds=?, Distance=?,`+endRestColumns("MIN(CURRENT_TIMESTAMP, RestEndsAt)")+`
 		WHERE WorkoutID=? AND CompletedAt IS NULL
-		RETURNING SetNumber, WorkoutID, ExerciseID
-		`, rating, workoutId)
+		RETURNING SetNumber, WorkoutID, ExerciseID, Weight, Reps, Seconds, Distance, SetType
+		`, rating, setType, weight, reps, seconds, distance, workoutId)
 
 	var err error
 	workoutSet := WorkoutSet{}
-	if err = row.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID); err != nil {
+	if err = row.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.SetType); err != nil {
 		if err != sql.ErrNoRows {
 			log.Printf("UpdateActiveWorkoutSet Error: %s", err.Error())
 		}
 	}
 	return workoutSet, err
 }
+
+type WorkoutSummary struct {
+	Workout
+	SplitName string
+	SetCount  int64
+	GoodCount int64
+	BadCount  int64
+}
+
+// GetCompletedWorkoutSummaries returns a page of completed workouts, newest first, with their
+// set counts, warm-up sets are left out. A splitId of 0 includes workouts of every split.
+func GetCompletedWorkoutSummaries(userId int64, splitId int64, limit int, offset int, db *sql.DB) ([]WorkoutSummary, error) {
+	rows, err := db.Query(`
+	SELECT w.ID, w.UserID, w.SplitID, w.StartedAt, w.CompletedAt, s.Name,
+		COUNT(ws.SetNumber),
+		COUNT(CASE WHEN ws.SetRating = 'good' THEN 1 END),
+		COUNT(CASE WHEN ws.SetRating = 'bad' THEN 1 END)
+	FROM workouts w
+	INNER JOIN splits s ON s.ID = w.SplitID
+	LEFT JOIN workout_sets ws ON ws.WorkoutID = w.ID AND ws.CompletedAt IS NOT NULL AND ws.SetType <> 'warmup'
+	WHERE w.UserID=? AND w.CompletedAt IS NOT NULL AND (?=0 OR w.SplitID=?)
+	GROUP BY w.ID
+	ORDER BY w.StartedAt DESC
+	LIMIT ? OFFSET ?
+	`, userId, splitId, splitId, limit, offset)
+
+	if err != nil {
+		log.Printf("GetCompletedWorkoutSummaries error: %s", err.Error())
+		return nil, err
+	}
+
+	summaries := []WorkoutSummary{}
+	for rows.Next() {
+		summary := WorkoutSummary{}
+		if err = rows.Scan(&summary.ID, &summary.UserID, &summary.SplitID, &summary.StartedAt, &summary.CompletedAt, &summary.SplitName, &summary.SetCount, &summary.GoodCount, &summary.BadCount); err != nil {
+			log.Printf("GetCompletedWorkoutSummaries error: %s", err.Error())
+			break
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, err
+}
+
+func CountCompletedWorkouts(userId int64, splitId int64, db *sql.DB) (int, error) {
+	row := db.QueryRow(`
+	SELECT COUNT(*) FROM workouts
+	WHERE UserID=? AND CompletedAt IS NOT NULL AND (?=0 OR SplitID=?)
+	`, userId, splitId, splitId)
+
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		log.Printf("CountCompletedWorkouts error: %s", err.Error())
+	}
+
+	return count, err
+}
+
+// GetWorkoutSets returns every set of a workout in the order they were started.
+func GetWorkoutSets(workoutId int64, db *sql.DB) ([]WorkoutSet, error) {
+	rows, err := db.Query(`
+	SELECT SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo, Weight, Reps, Seconds, Distance, SetType, RestEndsAt, RestSeconds
+	FROM workout_sets
+	WHERE WorkoutID=?
+	ORDER BY StartedAt ASC, rowid ASC
+	`, workoutId)
+
+	if err != nil {
+		log.Printf("GetWorkoutSets error: %s", err.Error())
+		return nil, err
+	}
+
+	workoutSets := []WorkoutSet{}
+	for rows.Next() {
+		workoutSet := WorkoutSet{}
+		if err = rows.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightFrom, &workoutSet.WeightTo, &workoutSet.RepsFrom, &workoutSet.RepsTo, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.SetType, &workoutSet.RestEndsAt, &workoutSet.RestSeconds); err != nil {
+			log.Printf("GetWorkoutSets Error: %s", err.Error())
+			break
+		}
+		workoutSets = append(workoutSets, workoutSet)
+	}
+
+	return workoutSets, err
+}
+
+// CreatePastWorkout logs a workout after the fact, it is created completed with the given start and end time.
+func CreatePastWorkout(userId int64, splitId int64, startedAt time.Time, completedAt time.Time, db *sql.DB) (Workout, error) {
+	row := db.QueryRow(`
+	INSERT INTO workouts (UserID, SplitID, StartedAt, CompletedAt)
+	SELECT UserID, ID, ?, ? FROM splits
+	WHERE ID=? AND UserID=?
+	RETURNING ID, UserID, SplitID, StartedAt, CompletedAt
+	`, startedAt.UTC().Format(time.DateTime), completedAt.UTC().Format(time.DateTime), splitId, userId)
+
+	workout := Workout{}
+	err := row.Scan(&workout.ID, &workout.UserID, &workout.SplitID, &workout.StartedAt, &workout.CompletedAt)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("CreatePastWorkout Error: %s", err.Error())
+	}
+
+	return workout, err
+}
+
+// AddWorkoutSet appends a rated set to a completed workout. The exercise has to belong to the split of the workout.
+func AddWorkoutSet(userId int64, workoutId int64, exerciseId int64, rating SetStatus, setType SetType, weight float64, reps int64, seconds int64, distance float64, db *sql.DB) (WorkoutSet, error) {
+	row := db.QueryRow(`
+	INSERT INTO workout_sets (SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, SetType, WeightFrom, WeightTo, RepsFrom, RepsTo, Weight, Reps, Seconds, Distance)
+	SELECT
+		(SELECT COALESCE(MAX(ws.SetNumber), 0) + 1 FROM workout_sets ws WHERE ws.WorkoutID = w.ID AND ws.ExerciseID = se.ExerciseID),
+		w.ID, se.ExerciseID, w.CompletedAt, w.CompletedAt, ?, ?, se.WeightFrom, se.WeightTo, se.RepsFrom, se.RepsTo, ?, ?, ?, ?
+	FROM workouts w
+	INNER JOIN split_exercises se ON se.SplitID = w.SplitID
+	WHERE w.ID=? AND w.UserID=? AND w.CompletedAt IS NOT NULL AND se.ExerciseID=?
+	RETURNING SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo, Weight, Reps, Seconds, Distance, SetType
+	`, rating, setType, weight, reps, seconds, distance, workoutId, userId, exerciseId)
+
+	workoutSet := WorkoutSet{}
+	err := row.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightFrom, &workoutSet.WeightTo, &workoutSet.RepsFrom, &workoutSet.RepsTo, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.SetType)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("AddWorkoutSet Error: %s", err.Error())
+	}
+
+	return workoutSet, err
+}
+
+// UpdateWorkoutSet corrects the rating, type and measurements of a set in a completed workout.
+func UpdateWorkoutSet(userId int64, workoutId int64, exerciseId int64, setNumber int64, rating SetStatus, setType SetType, weight float64, reps int64, seconds int64, distance float64, db *sql.DB) (WorkoutSet, error) {
+	row := db.QueryRow(`
+	UPDATE workout_sets
+	SET SetRating=?, SetType=?, Weight=?, Reps=?, Seconds=?, Distance=?, CompletedAt=COALESCE(CompletedAt, StartedAt)
+	WHERE WorkoutID=? AND ExerciseID=? AND SetNumber=? AND WorkoutID IN (
+		SELECT ID FROM workouts WHERE UserID=? AND CompletedAt IS NOT NULL
+	)
+	RETURNING SetNumber, WorkoutID, ExerciseID, StartedAt, CompletedAt, SetRating, WeightFrom, WeightTo, RepsFrom, RepsTo, Weight, Reps, Seconds, Distance, SetType
+	`, rating, setType, weight, reps, seconds, distance, workoutId, exerciseId, setNumber, userId)
+
+	workoutSet := WorkoutSet{}
+	err := row.Scan(&workoutSet.SetNumber, &workoutSet.WorkoutID, &workoutSet.ExerciseID, &workoutSet.StartedAt, &workoutSet.CompletedAt, &workoutSet.SetRating, &workoutSet.WeightFrom, &workoutSet.WeightTo, &workoutSet.RepsFrom, &workoutSet.RepsTo, &workoutSet.Weight, &workoutSet.Reps, &workoutSet.Seconds, &workoutSet.Distance, &workoutSet.SetType)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("UpdateWorkoutSet Error: %s", err.Error())
+	}
+
+	return workoutSet, err
+}
+
+// DeleteWorkoutSet removes a set from a completed workout and renumbers the sets that followed it.
+func DeleteWorkoutSet(userId int64, workoutId int64, exerciseId int64, setNumber int64, db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("DeleteWorkoutSet Error: %s", err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+	DELETE FROM workout_sets
+	WHERE WorkoutID=? AND ExerciseID=? AND SetNumber=? AND WorkoutID IN (
+		SELECT ID FROM workouts WHERE UserID=? AND CompletedAt IS NOT NULL
+	)
+	`, workoutId, exerciseId, setNumber, userId)
+	if err != nil {
+		log.Printf("DeleteWorkoutSet Error: %s", err.Error())
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	// Set numbers are part of the primary key, so they are moved out of the way before being shifted down.
+	if _, err = tx.Exec(`
+	UPDATE workout_sets SET SetNumber = -(SetNumber - 1)
+	WHERE WorkoutID=? AND ExerciseID=? AND SetNumber > ?
+	`, workoutId, exerciseId, setNumber); err != nil {
+		log.Printf("DeleteWorkoutSet Error: %s", err.Error())
+		return err
+	}
+
+	if _, err = tx.Exec(`
+	UPDATE workout_sets SET SetNumber = -SetNumber
+	WHERE WorkoutID=? AND ExerciseID=? AND SetNumber < 0
+	`, workoutId, exerciseId); err != nil {
+		log.Printf("DeleteWorkoutSet Error: %s", err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
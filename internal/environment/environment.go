@@ -3,6 +3,7 @@ package environment
 import (
 	"log"
 	"os"
+	"strings"
 )
 
 type Environment string
@@ -51,3 +52,46 @@ func GetServerPort() string {
 
 	return serverPort
 }
+
+func GetBaseURL() string {
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		return "http://localhost" + GetServerPort()
+	}
+
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+func GetSMTPHost() string {
+	return os.Getenv("SMTP_HOST")
+}
+
+func GetSMTPPort() string {
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		return "587"
+	}
+
+	return port
+}
+
+func GetSMTPUsername() string {
+	return os.Getenv("SMTP_USERNAME")
+}
+
+func GetSMTPPassword() string {
+	return os.Getenv("SMTP_PASSWORD")
+}
+
+func GetMailFrom() string {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		return "no-reply@dumbbell.local"
+	}
+
+	return from
+}
+
+func GetMailDirectory() string {
+	return os.Getenv("MAIL_DIR")
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"strings"
+	"time"
+)
+
+// Hevy exports one row per set:
+//
+//	title,start_time,end_time,description,exercise_title,superset_id,exercise_notes,set_index,set_type,weight_kg,reps,distance_km,duration_seconds,rpe
+//
+// Accounts that use pounds get weight_lbs instead of weight_kg.
+var hevyFormat = format{
+	name:     FormatHevy,
+	columns:  []string{"title", "start_time", "end_time", "exercise_title", "reps"},
+	parseRow: parseHevyRow,
+}
+
+// Hevy set types, normal sets are working sets.
+var hevySetTypes = map[string]string{
+	"warmup":  "warmup",
+	"dropset": "drop",
+	"failure": "failure",
+}
+
+var hevyDateLayouts = []string{
+	"2 Jan 2006, 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC3339,
+}
+
+func parseHevyRow(record columns, location *time.Location) (row, error) {
+	startedAt, err := record.time("start_time", hevyDateLayouts, location)
+	if err != nil {
+		return row{}, err
+	}
+
+	completedAt, err := record.time("end_time", hevyDateLayouts, location)
+	if err != nil {
+		return row{}, err
+	}
+
+	var weight float64
+	if record.has("weight_lbs") {
+		weight, err = record.float("weight_lbs")
+		weight *= kilogramsPerPound
+	} else {
+		weight, err = record.float("weight_kg")
+	}
+	if err != nil {
+		return row{}, err
+	}
+
+	reps, err := record.int("reps")
+	if err != nil {
+		return row{}, err
+	}
+
+	return row{
+		workoutName:  record.get("title"),
+		startedAt:    startedAt,
+		completedAt:  completedAt,
+		exerciseName: record.get("exercise_title"),
+		weight:       weight,
+		reps:         reps,
+		setType:      hevySetTypes[strings.ToLower(record.get("set_type"))],
+	}, nil
+}
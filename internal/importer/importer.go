@@ -0,0 +1,275 @@
+// Package importer reads the workout history CSV exports of other tracker apps.
+//
+// Every supported app has its own column layout, Parse recognises the layout from the
+// header and turns the rows into workouts. Rows that can not be read are reported with
+// their line number instead of failing the whole file.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatStrong Format = "Strong"
+	FormatHevy   Format = "Hevy"
+)
+
+const kilogramsPerPound = 0.45359237
+
+var ErrorUnknownFormat = errors.New("Unknown CSV format, expected a Strong or Hevy export")
+
+type Set struct {
+	Line   int
+	Weight float64
+	Reps   int64
+	// Type is warmup, drop or failure for the sets marked as such in the export, empty for working sets.
+	Type string
+}
+
+type Exercise struct {
+	Name string
+	Sets []Set
+}
+
+type Workout struct {
+	Name        string
+	StartedAt   time.Time
+	CompletedAt time.Time
+	Exercises   []Exercise
+}
+
+func (w *Workout) SetCount() int {
+	count := 0
+	for _, exercise := range w.Exercises {
+		count += len(exercise.Sets)
+	}
+	return count
+}
+
+type RowError struct {
+	Line    int
+	Message string
+}
+
+// row is a single set, as read by one of the formats.
+type row struct {
+	line         int
+	workoutName  string
+	startedAt    time.Time
+	completedAt  time.Time
+	exerciseName string
+	weight       float64
+	reps         int64
+	setType      string
+}
+
+// errorSkipRow marks rows that are not sets, like rest timers, and are left out without an error.
+var errorSkipRow = errors.New("Skip row")
+
+type format struct {
+	name Format
+	// Columns that have to be in the header for the format to match.
+	columns  []string
+	parseRow func(record columns, location *time.Location) (row, error)
+}
+
+var formats = []format{strongFormat, hevyFormat}
+
+// columns gives access to the fields of a record by header name.
+type columns struct {
+	index  map[string]int
+	record []string
+}
+
+func (c columns) has(name string) bool {
+	_, ok := c.index[name]
+	return ok
+}
+
+func (c columns) get(name string) string {
+	index, ok := c.index[name]
+	if !ok || index >= len(c.record) {
+		return ""
+	}
+	return strings.TrimSpace(c.record[index])
+}
+
+func (c columns) float(name string) (float64, error) {
+	value := c.get(name)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s %q", name, value)
+	}
+	return number, nil
+}
+
+func (c columns) int(name string) (int64, error) {
+	number, err := c.float(name)
+	if err != nil {
+		return 0, err
+	}
+	return int64(number), nil
+}
+
+func (c columns) time(name string, layouts []string, location *time.Location) (time.Time, error) {
+	value := c.get(name)
+	for _, layout := range layouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid %s %q", name, value)
+}
+
+// detectDelimiter picks the delimiter used in the header line, some exports use semicolons.
+func detectDelimiter(header string) rune {
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		return ';'
+	}
+	return ','
+}
+
+// Parse reads a CSV export, times without a zone are read in location. The workouts are
+// returned oldest first together with the rows that could not be read.
+func Parse(reader io.Reader, location *time.Location) (Format, []Workout, []RowError, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+
+	header, _, _ := strings.Cut(text, "\n")
+	csvReader := csv.NewReader(strings.NewReader(text))
+	csvReader.Comma = detectDelimiter(header)
+	csvReader.FieldsPerRecord = -1
+
+	headerRecord, err := csvReader.Read()
+	if err != nil {
+		return "", nil, nil, ErrorUnknownFormat
+	}
+
+	index := map[string]int{}
+	for i, name := range headerRecord {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var matched *format
+	for i := range formats {
+		matches := true
+		for _, column := range formats[i].columns {
+			if _, ok := index[column]; !ok {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			matched = &formats[i]
+			break
+		}
+	}
+	if matched == nil {
+		return "", nil, nil, ErrorUnknownFormat
+	}
+
+	rows := []row{}
+	rowErrors := []RowError{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			line := 0
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				line = parseErr.Line
+			}
+			rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		line, _ := csvReader.FieldPos(0)
+
+		parsed, err := matched.parseRow(columns{index: index, record: record}, location)
+		if err == errorSkipRow {
+			continue
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+		if parsed.exerciseName == "" {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: "Missing exercise name"})
+			continue
+		}
+		if parsed.weight < 0 || parsed.reps <= 0 {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: "Only sets with weight and reps can be imported"})
+			continue
+		}
+
+		parsed.line = line
+		rows = append(rows, parsed)
+	}
+
+	return matched.name, groupWorkouts(rows), rowErrors, nil
+}
+
+// groupWorkouts puts the rows with the same start time and name into one workout, keeping the order of the
+// exercises and sets from the file.
+func groupWorkouts(rows []row) []Workout {
+	workouts := []Workout{}
+	workoutIndex := map[string]int{}
+	for _, row := range rows {
+		key := fmt.Sprintf("%d|%s", row.startedAt.Unix(), row.workoutName)
+		index, ok := workoutIndex[key]
+		if !ok {
+			completedAt := row.completedAt
+			if completedAt.Before(row.startedAt) {
+				completedAt = row.startedAt
+			}
+
+			workouts = append(workouts, Workout{
+				Name:        row.workoutName,
+				StartedAt:   row.startedAt,
+				CompletedAt: completedAt,
+				Exercises:   []Exercise{},
+			})
+			index = len(workouts) - 1
+			workoutIndex[key] = index
+		}
+
+		workout := &workouts[index]
+		exerciseIndex := -1
+		for i, exercise := range workout.Exercises {
+			if strings.EqualFold(exercise.Name, row.exerciseName) {
+				exerciseIndex = i
+				break
+			}
+		}
+		if exerciseIndex == -1 {
+			workout.Exercises = append(workout.Exercises, Exercise{Name: row.exerciseName, Sets: []Set{}})
+			exerciseIndex = len(workout.Exercises) - 1
+		}
+
+		exercise := &workout.Exercises[exerciseIndex]
+		exercise.Sets = append(exercise.Sets, Set{Line: row.line, Weight: row.weight, Reps: row.reps, Type: row.setType})
+	}
+
+	sort.SliceStable(workouts, func(i, j int) bool {
+		return workouts[i].StartedAt.Before(workouts[j].StartedAt)
+	})
+
+	return workouts
+}
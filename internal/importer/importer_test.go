@@ -0,0 +1,223 @@
+package importer
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const strongFixture = `Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE
+2024-03-04 18:00:00,Push,1h 5m,Bench Press (Barbell),W,40,10,0,0,,,
+2024-03-04 18:00:00,Push,1h 5m,Bench Press (Barbell),1,100,5,0,0,,,
+2024-03-04 18:00:00,Push,1h 5m,Bench Press (Barbell),Rest Timer,0,0,0,90,,,
+2024-03-04 18:00:00,Push,1h 5m,Overhead Press (Barbell),1,50,8,0,0,,,
+2024-03-06 18:30:00,Pull,45m,Deadlift (Barbell),1,140,3,0,0,,,
+`
+
+const hevyFixture = `title,start_time,end_time,description,exercise_title,superset_id,exercise_notes,set_index,set_type,weight_kg,reps,distance_km,duration_seconds,rpe
+Legs,"5 Mar 2024, 07:00","5 Mar 2024, 08:00",,Squat (Barbell),,,0,warmup,60,8,,,
+Legs,"5 Mar 2024, 07:00","5 Mar 2024, 08:00",,Squat (Barbell),,,1,normal,120,5,,,
+Legs,"5 Mar 2024, 07:00","5 Mar 2024, 08:00",,Leg Press,,,0,dropset,200,12,,,
+`
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		csv      string
+		format   Format
+		workouts []Workout
+	}{
+		{
+			name:   "strong",
+			csv:    strongFixture,
+			format: FormatStrong,
+			workouts: []Workout{
+				{
+					Name:        "Push",
+					StartedAt:   time.Date(2024, time.March, 4, 18, 0, 0, 0, time.UTC),
+					CompletedAt: time.Date(2024, time.March, 4, 19, 5, 0, 0, time.UTC),
+					Exercises: []Exercise{
+						{Name: "Bench Press (Barbell)", Sets: []Set{{Line: 2, Weight: 40, Reps: 10, Type: "warmup"}, {Line: 3, Weight: 100, Reps: 5}}},
+						{Name: "Overhead Press (Barbell)", Sets: []Set{{Line: 5, Weight: 50, Reps: 8}}},
+					},
+				},
+				{
+					Name:        "Pull",
+					StartedAt:   time.Date(2024, time.March, 6, 18, 30, 0, 0, time.UTC),
+					CompletedAt: time.Date(2024, time.March, 6, 19, 15, 0, 0, time.UTC),
+					Exercises: []Exercise{
+						{Name: "Deadlift (Barbell)", Sets: []Set{{Line: 6, Weight: 140, Reps: 3}}},
+					},
+				},
+			},
+		},
+		{
+			name:   "hevy",
+			csv:    hevyFixture,
+			format: FormatHevy,
+			workouts: []Workout{
+				{
+					Name:        "Legs",
+					StartedAt:   time.Date(2024, time.March, 5, 7, 0, 0, 0, time.UTC),
+					CompletedAt: time.Date(2024, time.March, 5, 8, 0, 0, 0, time.UTC),
+					Exercises: []Exercise{
+						{Name: "Squat (Barbell)", Sets: []Set{{Line: 2, Weight: 60, Reps: 8, Type: "warmup"}, {Line: 3, Weight: 120, Reps: 5}}},
+						{Name: "Leg Press", Sets: []Set{{Line: 4, Weight: 200, Reps: 12, Type: "drop"}}},
+					},
+				},
+			},
+		},
+		{
+			name:   "header with byte order mark and other case",
+			csv:    "\ufeffDATE,WORKOUT NAME,EXERCISE NAME,SET ORDER,WEIGHT,REPS\n2024-03-04 18:00,Push,Dips,1,0,12\n",
+			format: FormatStrong,
+			workouts: []Workout{
+				{
+					Name:        "Push",
+					StartedAt:   time.Date(2024, time.March, 4, 18, 0, 0, 0, time.UTC),
+					CompletedAt: time.Date(2024, time.March, 4, 18, 0, 0, 0, time.UTC),
+					Exercises:   []Exercise{{Name: "Dips", Sets: []Set{{Line: 2, Weight: 0, Reps: 12}}}},
+				},
+			},
+		},
+		{
+			name:   "semicolon delimiter with decimal commas",
+			csv:    "Date;Workout Name;Exercise Name;Set Order;Weight;Reps\n2024-03-04 18:00:00;Push;Bench Press;1;102,5;5\n",
+			format: FormatStrong,
+			workouts: []Workout{
+				{
+					Name:        "Push",
+					StartedAt:   time.Date(2024, time.March, 4, 18, 0, 0, 0, time.UTC),
+					CompletedAt: time.Date(2024, time.March, 4, 18, 0, 0, 0, time.UTC),
+					Exercises:   []Exercise{{Name: "Bench Press", Sets: []Set{{Line: 2, Weight: 102.5, Reps: 5}}}},
+				},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			format, workouts, rowErrors, err := Parse(strings.NewReader(test.csv), time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != test.format {
+				t.Errorf("got format %q, want %q", format, test.format)
+			}
+			if len(rowErrors) != 0 {
+				t.Errorf("got row errors %v, want none", rowErrors)
+			}
+			assertWorkouts(t, workouts, test.workouts)
+		})
+	}
+}
+
+func assertWorkouts(t *testing.T, got []Workout, want []Workout) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d workouts, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || !got[i].StartedAt.Equal(want[i].StartedAt) || !got[i].CompletedAt.Equal(want[i].CompletedAt) {
+			t.Errorf("workout %d: got %q from %s to %s, want %q from %s to %s", i, got[i].Name, got[i].StartedAt, got[i].CompletedAt,
+				want[i].Name, want[i].StartedAt, want[i].CompletedAt)
+		}
+		if len(got[i].Exercises) != len(want[i].Exercises) {
+			t.Errorf("workout %d: got %d exercises, want %d", i, len(got[i].Exercises), len(want[i].Exercises))
+			continue
+		}
+		for j, exercise := range want[i].Exercises {
+			gotExercise := got[i].Exercises[j]
+			if gotExercise.Name != exercise.Name || len(gotExercise.Sets) != len(exercise.Sets) {
+				t.Errorf("workout %d exercise %d: got %q with %d sets, want %q with %d sets", i, j, gotExercise.Name, len(gotExercise.Sets),
+					exercise.Name, len(exercise.Sets))
+				continue
+			}
+			for k, set := range exercise.Sets {
+				if gotSet := gotExercise.Sets[k]; gotSet != set {
+					t.Errorf("%s set %d: got %+v, want %+v", exercise.Name, k+1, gotSet, set)
+				}
+			}
+		}
+	}
+}
+
+func TestParseConvertsPounds(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		csv  string
+	}{
+		{"strong", "Date,Workout Name,Exercise Name,Set Order,Weight,Weight Unit,Reps\n2024-03-04 18:00,Push,Bench Press,1,225,lbs,5\n"},
+		{"hevy", "title,start_time,end_time,exercise_title,set_type,weight_lbs,reps\nPush,2024-03-04 18:00,2024-03-04 19:00,Bench Press,normal,225,5\n"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, workouts, rowErrors, err := Parse(strings.NewReader(test.csv), time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rowErrors) != 0 || len(workouts) != 1 {
+				t.Fatalf("got %d workouts and row errors %v, want 1 workout", len(workouts), rowErrors)
+			}
+			if weight := workouts[0].Exercises[0].Sets[0].Weight; math.Abs(weight-102.06) > 0.01 {
+				t.Errorf("got %v kg, want 102.06", weight)
+			}
+		})
+	}
+}
+
+func TestParseReportsRowErrorsByLine(t *testing.T) {
+	csv := `Date,Workout Name,Exercise Name,Set Order,Weight,Reps
+2024-03-04 18:00,Push,Bench Press,1,100,5
+yesterday,Push,Bench Press,2,100,5
+2024-03-04 18:00,Push,,3,100,5
+2024-03-04 18:00,Push,Bench Press,4,heavy,5
+2024-03-04 18:00,Push,Bench Press,5,100,0
+2024-03-04 18:00,Push,Bench Press,6,100,5
+`
+	_, workouts, rowErrors, err := Parse(strings.NewReader(csv), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantLines := []int{3, 4, 5, 6}
+	if len(rowErrors) != len(wantLines) {
+		t.Fatalf("got row errors %v, want errors on lines %v", rowErrors, wantLines)
+	}
+	for i, line := range wantLines {
+		if rowErrors[i].Line != line || rowErrors[i].Message == "" {
+			t.Errorf("got error %q on line %d, want an error on line %d", rowErrors[i].Message, rowErrors[i].Line, line)
+		}
+	}
+
+	// The rows around the broken ones are still imported.
+	if len(workouts) != 1 || workouts[0].SetCount() != 2 {
+		t.Errorf("got %d workouts, want 1 with 2 sets", len(workouts))
+	}
+}
+
+func TestParseRejectsUnknownFormats(t *testing.T) {
+	for _, csv := range []string{
+		"",
+		"Date,Exercise,Weight,Reps\n2024-03-04,Bench Press,100,5\n",
+	} {
+		if _, _, _, err := Parse(strings.NewReader(csv), time.UTC); err != ErrorUnknownFormat {
+			t.Errorf("got error %v for %q, want %v", err, csv, ErrorUnknownFormat)
+		}
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	for _, test := range []struct {
+		header string
+		want   rune
+	}{
+		{"Date,Workout Name,Exercise Name", ','},
+		{"Date;Workout Name;Exercise Name", ';'},
+		{"Date;Workout Name, Push;Exercise Name", ';'},
+		{"title", ','},
+	} {
+		if got := detectDelimiter(test.header); got != test.want {
+			t.Errorf("detectDelimiter(%q) = %q, want %q", test.header, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package importer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Strong exports one row per set:
+//
+//	Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE
+//
+// Newer versions add a "Weight Unit" column, older ones are in the unit the app was set to
+// which is assumed to be kilograms.
+var strongFormat = format{
+	name:     FormatStrong,
+	columns:  []string{"date", "workout name", "exercise name", "set order", "weight", "reps"},
+	parseRow: parseStrongRow,
+}
+
+// Strong numbers working sets and marks the other sets with a letter in the set order.
+var strongSetTypes = map[string]string{
+	"W": "warmup",
+	"D": "drop",
+	"F": "failure",
+}
+
+var strongDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC3339,
+}
+
+var strongDurationPattern = regexp.MustCompile(`(\d+)\s*([hms])`)
+
+// parseStrongDuration reads durations like "1h 5m" or "45m".
+func parseStrongDuration(value string) time.Duration {
+	duration := time.Duration(0)
+	for _, match := range strongDurationPattern.FindAllStringSubmatch(value, -1) {
+		amount, _ := strconv.Atoi(match[1])
+		switch match[2] {
+		case "h":
+			duration += time.Duration(amount) * time.Hour
+		case "m":
+			duration += time.Duration(amount) * time.Minute
+		case "s":
+			duration += time.Duration(amount) * time.Second
+		}
+	}
+	return duration
+}
+
+func parseStrongRow(record columns, location *time.Location) (row, error) {
+	// Rest timers and notes are exported as rows of their own.
+	setType, marked := strongSetTypes[strings.ToUpper(record.get("set order"))]
+	if _, err := strconv.Atoi(record.get("set order")); err != nil && !marked {
+		return row{}, errorSkipRow
+	}
+
+	startedAt, err := record.time("date", strongDateLayouts, location)
+	if err != nil {
+		return row{}, err
+	}
+
+	weight, err := record.float("weight")
+	if err != nil {
+		return row{}, err
+	}
+	if unit := strings.ToLower(record.get("weight unit")); unit == "lbs" || unit == "lb" {
+		weight *= kilogramsPerPound
+	}
+
+	reps, err := record.int("reps")
+	if err != nil {
+		return row{}, err
+	}
+
+	return row{
+		workoutName:  record.get("workout name"),
+		startedAt:    startedAt,
+		completedAt:  startedAt.Add(parseStrongDuration(record.get("duration"))),
+		exerciseName: record.get("exercise name"),
+		weight:       weight,
+		reps:         reps,
+		setType:      setType,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogMailer logs mail instead of sending it. When Directory is set every mail is also written
+// there as a separate file. The body, with any reset link in it, is only logged with LogBody.
+type LogMailer struct {
+	Directory string
+	From      string
+	LogBody   bool
+}
+
+func (m *LogMailer) Send(mail Mail) error {
+	if m.LogBody {
+		log.Printf("Mail to=%s subject=%q\n%s", mail.To, mail.Subject, mail.Body)
+	} else {
+		log.Printf("Mail to=%s subject=%q", mail.To, mail.Subject)
+	}
+
+	if m.Directory == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Directory, 0o755); err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), mail.To)
+	return os.WriteFile(filepath.Join(m.Directory, filepath.Base(fileName)), formatMessage(m.From, mail), 0o644)
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"dumbbell/internal/environment"
+	"log"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(mail Mail) error
+}
+
+// NewMailer sends mail over SMTP when SMTP_HOST is set, otherwise mail is only logged and written to
+// MAIL_DIR. The body, with any reset link in it, is only logged in development.
+func NewMailer() Mailer {
+	if host := environment.GetSMTPHost(); host != "" {
+		return &SMTPMailer{
+			Host:     host,
+			Port:     environment.GetSMTPPort(),
+			Username: environment.GetSMTPUsername(),
+			Password: environment.GetSMTPPassword(),
+			From:     environment.GetMailFrom(),
+		}
+	}
+
+	isDevelopment := environment.GetEnvironment() == environment.Development
+	directory := environment.GetMailDirectory()
+	if !isDevelopment && directory == "" {
+		log.Println("Neither SMTP_HOST nor MAIL_DIR is set, mail is not sent and only its recipient is logged")
+	}
+
+	return &LogMailer{
+		Directory: directory,
+		From:      environment.GetMailFrom(),
+		LogBody:   isDevelopment,
+	}
+}
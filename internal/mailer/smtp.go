@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(mail Mail) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{mail.To}, formatMessage(m.From, mail))
+}
+
+func formatMessage(from string, mail Mail) []byte {
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mail.Subject)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+
+	return []byte(message.String())
+}
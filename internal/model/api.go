@@ -0,0 +1,218 @@
+package model
+
+import "time"
+
+type ApiError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+func (e *ApiError) Error() string {
+	return e.Message
+}
+
+type ApiErrorResponse struct {
+	Error ApiError `json:"error"`
+}
+
+type ApiSplit struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ApiSplitDetail struct {
+	ApiSplit
+	Exercises []ApiExercise `json:"exercises"`
+}
+
+type ApiSplitInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ApiExercise is an exercise as part of a split, with the targets and position it has there.
+type ApiExercise struct {
+	ID           int64   `json:"id"`
+	SplitID      int64   `json:"splitId"`
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	MuscleGroups string  `json:"muscleGroups"`
+	Measurement  string  `json:"measurement"`
+	Position     int64   `json:"position"`
+	WeightFrom   float64 `json:"weightFrom"`
+	WeightTo     float64 `json:"weightTo"`
+	RepsFrom     float64 `json:"repsFrom"`
+	RepsTo       float64 `json:"repsTo"`
+	SecondsFrom  int64   `json:"secondsFrom"`
+	SecondsTo    int64   `json:"secondsTo"`
+	DistanceFrom float64 `json:"distanceFrom"`
+	DistanceTo   float64 `json:"distanceTo"`
+	Sets         int64   `json:"sets"`
+	RestSeconds  int64   `json:"restSeconds"`
+	// Exercises of the split with the same non-zero group are a superset or circuit and take turns set by set.
+	Group    int64  `json:"group"`
+	ImageURL string `json:"imageUrl"`
+}
+
+// ApiExerciseOrderInput lists every exercise of a split in the new order.
+type ApiExerciseOrderInput struct {
+	ExerciseIDs []int64 `json:"exerciseIds"`
+}
+
+// ApiExerciseGroupInput lists the exercises of a split that make up a new superset or circuit.
+type ApiExerciseGroupInput struct {
+	ExerciseIDs []int64 `json:"exerciseIds"`
+}
+
+// ApiExerciseTargetsInput leaves the rest as it was when RestSeconds is left out, new exercises get the default rest.
+// Seconds are the targets of timed exercises and distances, in meters, those of distance exercises.
+type ApiExerciseTargetsInput struct {
+	WeightFrom   float64 `json:"weightFrom"`
+	WeightTo     float64 `json:"weightTo"`
+	RepsFrom     int64   `json:"repsFrom"`
+	RepsTo       int64   `json:"repsTo"`
+	SecondsFrom  int64   `json:"secondsFrom,omitempty"`
+	SecondsTo    int64   `json:"secondsTo,omitempty"`
+	DistanceFrom float64 `json:"distanceFrom,omitempty"`
+	DistanceTo   float64 `json:"distanceTo,omitempty"`
+	Sets         int64   `json:"sets"`
+	RestSeconds  *int64  `json:"restSeconds,omitempty"`
+}
+
+// ApiExerciseInput adds the library exercise ExerciseID to a split, without it a new exercise is created
+// in the library from the name, description, muscle groups and measurement.
+type ApiExerciseInput struct {
+	ExerciseID            *int64   `json:"exerciseId,omitempty"`
+	Name                  string   `json:"name"`
+	Description           string   `json:"description"`
+	MuscleGroups          string   `json:"muscleGroups"`
+	PrimaryMuscleGroups   []string `json:"primaryMuscleGroups,omitempty"`
+	SecondaryMuscleGroups []string `json:"secondaryMuscleGroups,omitempty"`
+	Measurement           string   `json:"measurement,omitempty"`
+	ApiExerciseTargetsInput
+}
+
+// ApiLibraryExercise names the muscle groups it trains in MuscleGroups, the tagged ones are also listed
+// in PrimaryMuscleGroups and SecondaryMuscleGroups.
+type ApiLibraryExercise struct {
+	ID                    int64    `json:"id"`
+	Name                  string   `json:"name"`
+	Description           string   `json:"description"`
+	MuscleGroups          string   `json:"muscleGroups"`
+	PrimaryMuscleGroups   []string `json:"primaryMuscleGroups"`
+	SecondaryMuscleGroups []string `json:"secondaryMuscleGroups"`
+	Measurement           string   `json:"measurement"`
+	ImageURL              string   `json:"imageUrl"`
+}
+
+// ApiLibraryExerciseInput measures sets as weight_reps, bodyweight_reps, duration or distance. A new
+// exercise without a measurement is weight_reps, an updated one keeps its measurement. When either list of
+// muscle groups is sent the exercise is tagged with them and MuscleGroups is named after them, otherwise the
+// tags are left as they are.
+type ApiLibraryExerciseInput struct {
+	Name                  string   `json:"name"`
+	Description           string   `json:"description"`
+	MuscleGroups          string   `json:"muscleGroups"`
+	PrimaryMuscleGroups   []string `json:"primaryMuscleGroups,omitempty"`
+	SecondaryMuscleGroups []string `json:"secondaryMuscleGroups,omitempty"`
+	Measurement           string   `json:"measurement,omitempty"`
+}
+
+type ApiWorkout struct {
+	ID          int64           `json:"id"`
+	SplitID     int64           `json:"splitId"`
+	StartedAt   time.Time       `json:"startedAt"`
+	CompletedAt *time.Time      `json:"completedAt"`
+	Sets        []ApiWorkoutSet `json:"sets"`
+}
+
+type ApiWorkoutSummary struct {
+	ID          int64      `json:"id"`
+	SplitID     int64      `json:"splitId"`
+	SplitName   string     `json:"splitName"`
+	StartedAt   time.Time  `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	SetCount    int64      `json:"setCount"`
+	GoodCount   int64      `json:"goodCount"`
+	BadCount    int64      `json:"badCount"`
+}
+
+type ApiWorkoutList struct {
+	Workouts  []ApiWorkoutSummary `json:"workouts"`
+	Page      int                 `json:"page"`
+	PageCount int                 `json:"pageCount"`
+}
+
+// ApiWorkoutInput starts a new workout, or logs a past workout when both times are set.
+type ApiWorkoutInput struct {
+	SplitID     int64      `json:"splitId"`
+	StartedAt   *time.Time `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+type ApiWorkoutSet struct {
+	WorkoutID   int64      `json:"workoutId"`
+	ExerciseID  int64      `json:"exerciseId"`
+	SetNumber   int64      `json:"setNumber"`
+	Rating      string     `json:"rating"`
+	Type        string     `json:"type"`
+	Weight      float64    `json:"weight"`
+	Reps        int64      `json:"reps"`
+	Seconds     int64      `json:"seconds"`
+	Distance    float64    `json:"distance"`
+	StartedAt   time.Time  `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	// RestEndsAt is when the rest before the set is over, RestSeconds is how long it was once it is over.
+	RestEndsAt  *time.Time `json:"restEndsAt"`
+	RestSeconds *int64     `json:"restSeconds"`
+}
+
+// ApiStartExerciseInput starts the exercise ExerciseID, or the first exercise in the split's order that
+// has not been done in the workout when it is left out.
+type ApiStartExerciseInput struct {
+	ExerciseID *int64 `json:"exerciseId,omitempty"`
+}
+
+// ApiSetInput logs a set, the type is one of warmup, working, drop, amrap or failure and a set without
+// one is a working set. Seconds and the distance in meters are logged for timed and distance exercises.
+type ApiSetInput struct {
+	Rating   string  `json:"rating"`
+	Type     string  `json:"type,omitempty"`
+	Weight   float64 `json:"weight"`
+	Reps     int64   `json:"reps"`
+	Seconds  int64   `json:"seconds,omitempty"`
+	Distance float64 `json:"distance,omitempty"`
+}
+
+// ApiNextSetInput logs the active set, with DropSet the next set is a drop set chained off it.
+type ApiNextSetInput struct {
+	ApiSetInput
+	DropSet bool `json:"dropSet,omitempty"`
+}
+
+type ApiAddSetInput struct {
+	ExerciseID int64 `json:"exerciseId"`
+	ApiSetInput
+}
+
+type ApiStats struct {
+	CompletedWorkouts int                `json:"completedWorkouts"`
+	GoodSets          int                `json:"goodSets"`
+	BadSets           int                `json:"badSets"`
+	Activity          []ApiMonthActivity `json:"activity"`
+	Splits            []ApiSplitStats    `json:"splits"`
+}
+
+type ApiMonthActivity struct {
+	Month    string `json:"month"`
+	ThisYear int    `json:"thisYear"`
+	LastYear int    `json:"lastYear"`
+}
+
+type ApiSplitStats struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	GoodRatings int    `json:"goodRatings"`
+	BadRatings  int    `json:"badRatings"`
+}
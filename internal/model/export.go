@@ -0,0 +1,116 @@
+package model
+
+import "time"
+
+// ExportDocument is the JSON document in a data export, see the README for the format.
+type ExportDocument struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	// Every exercise of the library, also the ones that are not in any split.
+	Exercises []ExportExercise `json:"exercises"`
+	Splits    []ExportSplit    `json:"splits"`
+	Workouts  []ExportWorkout  `json:"workouts"`
+}
+
+// ExportExercise is an exercise of the library, the splits refer to it by its ID.
+type ExportExercise struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	MuscleGroups string `json:"muscleGroups"`
+	// The muscle groups the exercise is tagged with, older exports only have MuscleGroups.
+	PrimaryMuscleGroups   []string `json:"primaryMuscleGroups,omitempty"`
+	SecondaryMuscleGroups []string `json:"secondaryMuscleGroups,omitempty"`
+	// Measurement is what the exercise is measured in, an exercise without one, as in older exports, is
+	// measured in weight and reps.
+	Measurement string `json:"measurement,omitempty"`
+	// Path of the image inside the zip, empty when the exercise has no image.
+	Image     string `json:"image"`
+	ImageType string `json:"imageType"`
+}
+
+type ExportSplit struct {
+	ID          int64                 `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Exercises   []ExportSplitExercise `json:"exercises"`
+}
+
+// ExportSplitExercise is an exercise of the library in a split with its targets there.
+type ExportSplitExercise struct {
+	ExerciseID   int64   `json:"exerciseId"`
+	WeightFrom   float64 `json:"weightFrom"`
+	WeightTo     float64 `json:"weightTo"`
+	RepsFrom     int64   `json:"repsFrom"`
+	RepsTo       int64   `json:"repsTo"`
+	SecondsFrom  int64   `json:"secondsFrom"`
+	SecondsTo    int64   `json:"secondsTo"`
+	DistanceFrom float64 `json:"distanceFrom"`
+	DistanceTo   float64 `json:"distanceTo"`
+	Sets         int64   `json:"sets"`
+	// Exports made before the rest timer have no rest, the exercise gets the default rest.
+	RestSeconds *int64 `json:"restSeconds,omitempty"`
+	// Exercises of the split with the same non-zero group are a superset or circuit.
+	Group int64 `json:"group,omitempty"`
+}
+
+type ExportWorkout struct {
+	ID          int64              `json:"id"`
+	SplitID     int64              `json:"splitId"`
+	StartedAt   time.Time          `json:"startedAt"`
+	CompletedAt *time.Time         `json:"completedAt"`
+	Sets        []ExportWorkoutSet `json:"sets"`
+}
+
+type ExportWorkoutSet struct {
+	ExerciseID int64  `json:"exerciseId"`
+	SetNumber  int64  `json:"setNumber"`
+	Rating     string `json:"rating"`
+	// Type is the set type, a set without one, as in older exports, is a working set.
+	Type        string     `json:"type,omitempty"`
+	Weight      float64    `json:"weight"`
+	Reps        int64      `json:"reps"`
+	Seconds     int64      `json:"seconds"`
+	Distance    float64    `json:"distance"`
+	WeightFrom  float64    `json:"weightFrom"`
+	WeightTo    float64    `json:"weightTo"`
+	RepsFrom    int64      `json:"repsFrom"`
+	RepsTo      int64      `json:"repsTo"`
+	StartedAt   time.Time  `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	// The rest taken before the set, nil when there was no rest.
+	RestSeconds *int64 `json:"restSeconds"`
+}
+
+type ImportResultModel struct {
+	Splits    int
+	Exercises int
+	Workouts  int
+	Sets      int
+}
+
+type CsvImportModel struct {
+	Format         string
+	DryRun         bool
+	Workouts       []CsvImportWorkoutModel
+	NewSplits      []string
+	NewExercises   []string
+	Errors         []CsvImportErrorModel
+	ImportedCount  int
+	DuplicateCount int
+}
+
+type CsvImportWorkoutModel struct {
+	Date          string
+	Name          string
+	SplitName     string
+	NewSplit      bool
+	ExerciseCount int
+	SetCount      int
+	Duplicate     bool
+}
+
+type CsvImportErrorModel struct {
+	Line    int
+	Message string
+}
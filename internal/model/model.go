@@ -5,20 +5,81 @@ import (
 )
 
 type ExerciseViewModel struct {
-	Name        string
-	WorkoutID   int64
-	Description string
-	ImageSrc    string
-	WeightFrom  float64
-	WeightTo    float64
-	RepsFrom    float64
-	RepsTo      float64
+	Name         string
+	WorkoutID    int64
+	Description  string
+	ImageSrc     string
+	Measurement  dto.Measurement
+	WeightFrom   float64
+	WeightTo     float64
+	RepsFrom     float64
+	RepsTo       float64
+	SecondsFrom  int64
+	SecondsTo    int64
+	DistanceFrom float64
+	DistanceTo   float64
+	// Weight, Reps, Seconds and Distance start the log of the next set.
+	Weight      float64
+	Reps        int64
+	Seconds     int64
+	Distance    float64
 	Sets        ExerciseSetsModel
+	Progression *ProgressionModel
+	// Group places the exercise in its superset or circuit, like "Superset A1".
+	Group string
+}
+
+type ProgressionModel struct {
+	ID         int64
+	WeightFrom float64
+	WeightTo   float64
+	RepsFrom   float64
+	RepsTo     float64
+	Reason     string
+}
+
+type ExerciseTargetsModel struct {
+	Measurement  dto.Measurement
+	WeightFrom   float64
+	WeightTo     float64
+	RepsFrom     float64
+	RepsTo       float64
+	SecondsFrom  int64
+	SecondsTo    int64
+	DistanceFrom float64
+	DistanceTo   float64
+	Progression  *ProgressionModel
 }
 
 type ExerciseSetsModel struct {
-	Items []dto.SetStatus
-	Htmx  bool
+	WorkoutID int64
+	Items     []ExerciseSetModel
+	Rest      RestTimerModel
+	// CanUndo is true when a logged set or a skipped exercise can be taken back.
+	CanUndo bool
+	// ActiveType is the type the active set is logged as unless another one is picked.
+	ActiveType dto.SetType
+	Htmx       bool
+}
+
+// RestTimerModel counts down to EndsAt, in unix milliseconds, while Resting.
+type RestTimerModel struct {
+	WorkoutID int64
+	Resting   bool
+	EndsAt    int64
+	Remaining string
+	Htmx      bool
+}
+
+type ExerciseSetModel struct {
+	Status dto.SetStatus
+	Type   dto.SetType
+	// Result describes a logged set in what its exercise is measured in, like "50 × 10" or "01m 30s".
+	Result string
+	// Label is the exercise the set is for in a superset or circuit, like "A1".
+	Label string
+	// Records names the personal records the set beat, like "Heaviest weight, Estimated 1RM".
+	Records string
 }
 
 type CardViewModel struct {
@@ -28,20 +89,27 @@ type CardViewModel struct {
 	Name        string
 	Description string
 	Disabled    bool
+	// Group labels an exercise card with its place in a superset or circuit, like "A1".
+	Group string
 }
 
 type EditExerciseTableRowModel struct {
-	IsNew       bool
-	ID          int64
-	SplitID     int64
-	Name        string
-	Description string
-	WeightFrom  float64
-	WeightTo    float64
-	RepsFrom    float64
-	RepsTo      float64
+	IsNew        bool
+	ID           int64
+	SplitID      int64
+	Name         string
+	Description  string
+	MuscleGroups string
+	Measurement  dto.Measurement
+	// Targets describes the targets in what the exercise is measured in, like "40 – 50 kg × 8 – 12".
+	Targets     string
 	ImageSrc    string
 	Sets        int64
+	RestSeconds int64
+	GroupNumber int64
+	// Group labels the exercise's place in a superset or circuit, like "A1", and GroupKind names the group.
+	Group     string
+	GroupKind string
 }
 
 type EditWorkoutTableSplitModel struct {
@@ -52,22 +120,75 @@ type EditWorkoutTableSplitModel struct {
 }
 
 type UserSettingsModel struct {
-	Title  string
-	Splits []EditWorkoutTableSplitModel
-	Header HeaderModel
+	Title           string
+	Splits          []EditWorkoutTableSplitModel
+	Library         []LibraryExerciseModel
+	AutoProgression bool
+	Timezone        string
+	ApiTokens       []ApiTokenModel
+	Header          HeaderModel
 }
 
+type ApiTokenModel struct {
+	ID         int64
+	Name       string
+	ReadOnly   bool
+	CreatedAt  string
+	LastUsedAt string
+}
+
+type ApiTokenCreatedModel struct {
+	Token    string
+	ApiToken ApiTokenModel
+}
+
+// EditExerciseModel is the exercise drawer. Without a SplitID only the library fields are edited, new
+// exercises in a split can be picked from Library instead of being created.
 type EditExerciseModel struct {
+	ID           int64
+	SplitID      int64
+	Name         string
+	Description  string
+	MuscleGroups []MuscleGroupOptionModel
+	Measurement  dto.Measurement
+	WeightFrom   float64
+	WeightTo     float64
+	RepsFrom     float64
+	RepsTo       float64
+	SecondsFrom  int64
+	SecondsTo    int64
+	DistanceFrom float64
+	DistanceTo   float64
+	ImageSrc     string
+	Sets         int64
+	RestSeconds  int64
+	Library      []LibraryExerciseOptionModel
+}
+
+// MuscleGroupOptionModel is a muscle group of the taxonomy in the exercise drawer, Role is empty when the
+// exercise does not train it.
+type MuscleGroupOptionModel struct {
+	MuscleGroup dto.MuscleGroup
+	Name        string
+	Role        dto.MuscleRole
+}
+
+// LibraryExerciseOptionModel is an exercise that can be picked from the library, its measurement decides
+// which targets the drawer asks for.
+type LibraryExerciseOptionModel struct {
 	ID          int64
-	SplitID     int64
 	Name        string
-	Description string
-	WeightFrom  float64
-	WeightTo    float64
-	RepsFrom    float64
-	RepsTo      float64
-	ImageSrc    string
-	Sets        int64
+	Measurement dto.Measurement
+}
+
+type LibraryExerciseModel struct {
+	IsNew        bool
+	ID           int64
+	Name         string
+	Description  string
+	MuscleGroups string
+	ImageSrc     string
+	Splits       []string
 }
 
 type EditSplitModel struct {
@@ -85,7 +206,8 @@ type DashboardPageModel struct {
 	WorkoutStartedAt  int64
 	LatestWorkoutSets LatestWorkoutSetsModel
 	Splits            []CardViewModel
-	WorkoutActivity   WorkoutActivityModel
+	TrainingCalendar  TrainingCalendarModel
+	MuscleBalance     MuscleBalanceModel
 	WorkoutSplits     []WorkoutSplitModel
 	Header            HeaderModel
 }
@@ -114,6 +236,8 @@ type LatestWorkoutSetModel struct {
 	SplitName    string
 	ExerciseName string
 	Status       dto.SetStatus
+	Weight       float64
+	Reps         int64
 }
 
 type WorkoutActivityModel struct {
@@ -128,6 +252,75 @@ type WorkoutActivityMonthModel struct {
 	LastYearActivity int
 }
 
+type TrainingCalendarModel struct {
+	Year          int
+	Years         []TrainingCalendarYearModel
+	WorkoutCount  int
+	TrainingDays  int
+	CurrentStreak int
+	LongestStreak int
+	Weeks         []TrainingCalendarWeekModel
+}
+
+type TrainingCalendarYearModel struct {
+	Year   int
+	Active bool
+}
+
+type TrainingCalendarWeekModel struct {
+	Month string
+	Days  []TrainingCalendarDayModel
+}
+
+type TrainingCalendarDayModel struct {
+	Date   string
+	InYear bool
+	Level  int
+	Count  int
+	Splits []TrainingCalendarSplitModel
+}
+
+type TrainingCalendarSplitModel struct {
+	Name  string
+	Count int
+}
+
+type MuscleReportPageModel struct {
+	Title  string
+	Header HeaderModel
+	// Week is the date of the Monday the week starts on, the previous and next weeks are passed the same way.
+	Week            string
+	WeekName        string
+	PreviousWeek    string
+	NextWeek        string
+	IsCurrentWeek   bool
+	HasMuscleGroups bool
+	MuscleGroups    []MuscleGroupWeekModel
+}
+
+// MuscleGroupWeekModel is a muscle group in a week. Sets can be a half set for secondary muscle groups,
+// Status is under, within or over the range from SetsFrom to SetsTo and Missing is how many sets short it is.
+type MuscleGroupWeekModel struct {
+	MuscleGroup    dto.MuscleGroup
+	Name           string
+	Sets           string
+	Volume         string
+	SetsFrom       int64
+	SetsTo         int64
+	Status         string
+	Missing        string
+	Percentage     int
+	FromPercentage int
+}
+
+// MuscleBalanceModel is the muscle groups off target this week on the dashboard, it is left out until an
+// exercise is tagged with muscle groups.
+type MuscleBalanceModel struct {
+	HasMuscleGroups bool
+	Under           []MuscleGroupWeekModel
+	Over            []MuscleGroupWeekModel
+}
+
 type WorkoutSplitModel struct {
 	ID               int64
 	SplitName        string
@@ -144,13 +337,17 @@ type WorkoutSplitExerciseModel struct {
 }
 
 type PickExerciseModel struct {
-	Title            string
-	Exercises        []CardViewModel
+	Title     string
+	Exercises []CardViewModel
+	// The first exercise in the split's order that has not been done in the workout.
+	NextExercise     CardViewModel
 	ActiveWorkout    ActiveWorkoutModel
 	WorkoutStart     string
 	WorkoutDuration  string
 	WorkoutStartedAt int64
-	Header           HeaderModel
+	// CanUndo is true when a logged set or a skipped exercise can be taken back.
+	CanUndo bool
+	Header  HeaderModel
 }
 
 type WorkoutMetadataModel struct {
@@ -170,7 +367,199 @@ type HeaderModel struct {
 	UserEmail    string
 }
 
+type ResetPasswordPageModel struct {
+	Title      string
+	Header     HeaderModel
+	Token      string
+	ValidToken bool
+}
+
 type BannerModel struct {
 	SwapTarget  string
 	Description string
 }
+
+type HistoryPageModel struct {
+	Title          string
+	Header         HeaderModel
+	Workouts       []HistoryWorkoutRowModel
+	Splits         []HistorySplitFilterModel
+	SplitID        int64
+	Page           int
+	PageCount      int
+	PreviousPage   int
+	NextPage       int
+	NewStartedAt   string
+	NewCompletedAt string
+}
+
+type HistorySplitFilterModel struct {
+	ID       int64
+	Name     string
+	Selected bool
+}
+
+type HistoryWorkoutRowModel struct {
+	ID             int64
+	SplitName      string
+	Date           string
+	Duration       string
+	SetCount       int
+	GoodCount      int
+	BadCount       int
+	GoodPercentage int
+}
+
+type HistoryWorkoutPageModel struct {
+	Title     string
+	Header    HeaderModel
+	ID        int64
+	SplitName string
+	Date      string
+	Duration  string
+	Exercises []HistoryExerciseModel
+	Options   []HistoryExerciseOptionModel
+}
+
+type HistoryExerciseOptionModel struct {
+	ID          int64
+	Name        string
+	Measurement dto.Measurement
+}
+
+type HistoryExerciseModel struct {
+	ID          int64
+	Name        string
+	ImageSrc    string
+	Measurement dto.Measurement
+	Sets        []HistorySetModel
+}
+
+type HistorySetModel struct {
+	WorkoutID  int64
+	ExerciseID int64
+	SetNumber  int64
+	Status     dto.SetStatus
+	Type       dto.SetType
+	// Measurement is what the set's exercise is measured in, it decides which of Weight, Reps, Seconds and
+	// Distance are shown.
+	Measurement dto.Measurement
+	Weight      float64
+	Reps        int64
+	Seconds     int64
+	Distance    float64
+	StartedAt   string
+	Duration    string
+	Rest        string
+}
+
+type WorkoutSummaryPageModel struct {
+	Title     string
+	Header    HeaderModel
+	ID        int64
+	SplitName string
+	Date      string
+	Duration  string
+	// WorkTime is the time spent in sets and RestTime the rest taken between them, WorkPercentage is the
+	// share of WorkTime in both.
+	WorkTime       string
+	RestTime       string
+	WorkPercentage int
+	SetCount       int
+	GoodCount      int
+	BadCount       int
+	Volume         string
+	Exercises      []WorkoutSummaryExerciseModel
+	Records        []WorkoutSummaryRecordModel
+	// Previous compares the workout with the session of the same split before it, it is nil for the first one.
+	Previous *WorkoutSummaryComparisonModel
+}
+
+type WorkoutSummaryExerciseModel struct {
+	ID        int64
+	Name      string
+	ImageSrc  string
+	SetCount  int
+	GoodCount int
+	BadCount  int
+	// BestSet is the result of the best set, Volume is empty for exercises without weights.
+	BestSet string
+	Volume  string
+}
+
+type WorkoutSummaryRecordModel struct {
+	ExerciseID   int64
+	ExerciseName string
+	Record       string
+	Result       string
+	Previous     string
+}
+
+type WorkoutSummaryComparisonModel struct {
+	ID   int64
+	Date string
+	Rows []WorkoutSummaryComparisonRowModel
+}
+
+// WorkoutSummaryComparisonRowModel compares a value of the workout with the session before, Direction is 1
+// when the workout did better, -1 when it did worse and 0 when it is the same or neither is better.
+type WorkoutSummaryComparisonRowModel struct {
+	Label     string
+	Current   string
+	Previous  string
+	Change    string
+	Direction int
+}
+
+type PersonalRecordsPageModel struct {
+	Title      string
+	Header     HeaderModel
+	ExerciseID int64
+	Name       string
+	ImageSrc   string
+	// HasRecords is false for exercises measured in time or distance, they have no records.
+	HasRecords bool
+	// Current holds the standing record of each kind, RepsRecords the most reps at each weight, heaviest first.
+	Current     []PersonalRecordModel
+	RepsRecords []PersonalRecordModel
+	Timeline    []PersonalRecordModel
+}
+
+type PersonalRecordModel struct {
+	Type      dto.RecordType
+	Record    string
+	Result    string
+	Previous  string
+	Weight    float64
+	Date      string
+	WorkoutID int64
+}
+
+type ExerciseProgressPageModel struct {
+	Title      string
+	Header     HeaderModel
+	ExerciseID int64
+	Name       string
+	ImageSrc   string
+	Ranges     []ExerciseProgressRangeModel
+	// SessionCount is the number of workouts of the exercise in the range, each is a point in the charts.
+	SessionCount int
+	Charts       []ExerciseProgressChartModel
+}
+
+type ExerciseProgressRangeModel struct {
+	Value  string
+	Name   string
+	Active bool
+}
+
+type ExerciseProgressChartModel struct {
+	ID     string
+	Label  string
+	Points []ExerciseProgressPointModel
+}
+
+type ExerciseProgressPointModel struct {
+	Date  string
+	Value float64
+}
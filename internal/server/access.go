@@ -0,0 +1,35 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// respondAccessError writes the response for a failed lookup of a user owned resource.
+//
+// The dto queries for splits, exercises, images and workouts are scoped to the session user,
+// so a resource that does not exist and one that belongs to another user both come back as
+// sql.ErrNoRows and respond 404 Not Found. Answering 403 for foreign resources would confirm
+// that the ID exists. 403 Forbidden is used when the resource is visible to the user but the
+// request combines it with one it does not belong to, like an exercise addressed through
+// another split.
+func respondAccessError(w http.ResponseWriter, context string, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Printf("%s: not found", context)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, ErrorResourceMismatch) {
+		log.Printf("%s: %s", context, err.Error())
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	log.Printf("%s Error: %s", context, err.Error())
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+var ErrorResourceMismatch = errors.New("Resource does not belong to the requested parent")
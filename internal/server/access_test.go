@@ -0,0 +1,236 @@
+package server
+
+import (
+	"dumbbell/internal/db"
+	"dumbbell/internal/dto"
+	"dumbbell/internal/mailer"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// accessFixture is what one user owns: two splits with an exercise each and a completed workout of the
+// first split.
+type accessFixture struct {
+	UserID          int64
+	SplitID         int64
+	ExerciseID      int64
+	OtherSplitID    int64
+	OtherExerciseID int64
+	WorkoutID       int64
+}
+
+func newTestServer(t *testing.T) (*HttpServer, *httptest.Server) {
+	t.Helper()
+
+	database, err := db.OpenFile(filepath.Join(t.TempDir(), "database.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if _, err = db.MigrateUp(database); err != nil {
+		t.Fatal(err)
+	}
+
+	server := newHttpServer(database, &mailer.LogMailer{})
+	testServer := httptest.NewServer(server.routes())
+	t.Cleanup(testServer.Close)
+
+	return server, testServer
+}
+
+func createAccessFixture(t *testing.T, s *HttpServer, email string) accessFixture {
+	t.Helper()
+
+	user, err := dto.CreateUser(email, "password", s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := accessFixture{UserID: user.ID}
+
+	createSplitExercise := func(name string) (int64, int64) {
+		split, err := dto.CreateSplit(user.ID, name, "", s.DB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		image, err := dto.CreateImage(dto.ImageType("png"), []byte{}, s.DB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exercise, err := dto.CreateExercise(user.ID, &image.ID, name+" exercise", "", "", dto.MeasurementWeightReps, s.DB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = dto.AddSplitExercise(user.ID, split.ID, exercise.ID, 20, 40, 8, 12, 0, 0, 0, 0, 3, 90, s.DB); err != nil {
+			t.Fatal(err)
+		}
+		return split.ID, exercise.ID
+	}
+	fixture.SplitID, fixture.ExerciseID = createSplitExercise("Push")
+	fixture.OtherSplitID, fixture.OtherExerciseID = createSplitExercise("Pull")
+
+	startedAt := time.Now().Add(-2 * time.Hour)
+	workout, err := dto.CreatePastWorkout(user.ID, fixture.SplitID, startedAt, startedAt.Add(time.Hour), s.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture.WorkoutID = workout.ID
+
+	return fixture
+}
+
+// loginClient signs in as the user and returns a client with the session that does not follow redirects,
+// so a redirect to the login page shows up as one.
+func loginClient(t *testing.T, testServer *httptest.Server, email string) *http.Client {
+	t.Helper()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.PostForm(testServer.URL+"/login", url.Values{
+		"email":    {email},
+		"password": {"password"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusFound || response.Header.Get("Location") != "/" {
+		t.Fatalf("login as %s: status %d, location %q", email, response.StatusCode, response.Header.Get("Location"))
+	}
+
+	return client
+}
+
+type accessCase struct {
+	Name   string
+	Method string
+	Path   string
+	Form   url.Values
+}
+
+func assertStatus(t *testing.T, client *http.Client, testServer *httptest.Server, status int, cases []accessCase) {
+	t.Helper()
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			request, err := http.NewRequest(c.Method, testServer.URL+c.Path, strings.NewReader(c.Form.Encode()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+
+			if response.StatusCode != status {
+				t.Errorf("%s %s: got status %d, want %d", c.Method, c.Path, response.StatusCode, status)
+			}
+		})
+	}
+}
+
+func exerciseForm(name string) url.Values {
+	return url.Values{
+		"name":         {name},
+		"measurement":  {string(dto.MeasurementWeightReps)},
+		"weight-from":  {"20"},
+		"weight-to":    {"40"},
+		"reps-from":    {"8"},
+		"reps-to":      {"12"},
+		"sets":         {"3"},
+		"rest-seconds": {"90"},
+	}
+}
+
+func TestOtherUsersResourcesAreNotFound(t *testing.T) {
+	s, testServer := newTestServer(t)
+	own := createAccessFixture(t, s, "own@example.com")
+	other := createAccessFixture(t, s, "other@example.com")
+	client := loginClient(t, testServer, "own@example.com")
+
+	assertStatus(t, client, testServer, http.StatusNotFound, []accessCase{
+		{"edit split", http.MethodGet, fmt.Sprintf("/split/%d/edit", other.SplitID), nil},
+		{"save split", http.MethodPost, fmt.Sprintf("/split/%d/save", other.SplitID), url.Values{"name": {"Taken"}}},
+		{"delete split", http.MethodDelete, fmt.Sprintf("/split/%d/delete", other.SplitID), nil},
+		{"edit split exercise", http.MethodGet, fmt.Sprintf("/split/%d/exercise/%d/edit", other.SplitID, other.ExerciseID), nil},
+		{"edit other exercise in own split", http.MethodGet, fmt.Sprintf("/split/%d/exercise/%d/edit", own.SplitID, other.ExerciseID), nil},
+		{"save split exercise", http.MethodPost, fmt.Sprintf("/split/%d/exercise/%d/save", other.SplitID, other.ExerciseID), exerciseForm("Taken")},
+		{"save other exercise in own split", http.MethodPost, fmt.Sprintf("/split/%d/exercise/%d/save", own.SplitID, other.ExerciseID), exerciseForm("Taken")},
+		{"delete split exercise", http.MethodDelete, fmt.Sprintf("/split/%d/exercise/%d/delete", other.SplitID, other.ExerciseID), nil},
+		{"delete other exercise from own split", http.MethodDelete, fmt.Sprintf("/split/%d/exercise/%d/delete", own.SplitID, other.ExerciseID), nil},
+		{"edit library exercise", http.MethodGet, fmt.Sprintf("/exercise/%d/edit", other.ExerciseID), nil},
+		{"save library exercise", http.MethodPost, fmt.Sprintf("/exercise/%d/save", other.ExerciseID), exerciseForm("Taken")},
+		{"delete library exercise", http.MethodDelete, fmt.Sprintf("/exercise/%d/delete", other.ExerciseID), nil},
+		{"exercise image", http.MethodGet, fmt.Sprintf("/exercise/image/%d", other.ExerciseID), nil},
+		{"history workout", http.MethodGet, fmt.Sprintf("/history/%d", other.WorkoutID), nil},
+		{"delete history workout", http.MethodDelete, fmt.Sprintf("/history/%d/delete", other.WorkoutID), nil},
+		{"start other exercise", http.MethodPost, fmt.Sprintf("/workout/%d/exercise/start", own.WorkoutID), url.Values{"exercise": {fmt.Sprint(other.ExerciseID)}}},
+		{"start exercise in other workout", http.MethodPost, fmt.Sprintf("/workout/%d/exercise/start", other.WorkoutID), url.Values{"exercise": {fmt.Sprint(own.ExerciseID)}}},
+	})
+
+	// Nothing of the other user was changed or removed.
+	if _, err := dto.GetSplit(other.UserID, other.SplitID, s.DB); err != nil {
+		t.Errorf("split of the other user: %s", err.Error())
+	}
+	exercise, err := dto.GetSplitExercise(other.UserID, other.SplitID, other.ExerciseID, s.DB)
+	if err != nil {
+		t.Errorf("exercise of the other user: %s", err.Error())
+	} else if exercise.Name != "Push exercise" {
+		t.Errorf("exercise of the other user was renamed to %q", exercise.Name)
+	}
+	if _, err := dto.GetWorkout(other.UserID, other.WorkoutID, s.DB); err != nil {
+		t.Errorf("workout of the other user: %s", err.Error())
+	}
+}
+
+func TestExerciseThroughOtherSplitIsForbidden(t *testing.T) {
+	s, testServer := newTestServer(t)
+	own := createAccessFixture(t, s, "own@example.com")
+	client := loginClient(t, testServer, "own@example.com")
+
+	assertStatus(t, client, testServer, http.StatusForbidden, []accessCase{
+		{"edit", http.MethodGet, fmt.Sprintf("/split/%d/exercise/%d/edit", own.SplitID, own.OtherExerciseID), nil},
+		{"save", http.MethodPost, fmt.Sprintf("/split/%d/exercise/%d/save", own.SplitID, own.OtherExerciseID), exerciseForm("Moved")},
+		{"delete", http.MethodDelete, fmt.Sprintf("/split/%d/exercise/%d/delete", own.SplitID, own.OtherExerciseID), nil},
+		{"start exercise", http.MethodPost, fmt.Sprintf("/workout/%d/exercise/start", own.WorkoutID), url.Values{"exercise": {fmt.Sprint(own.OtherExerciseID)}}},
+	})
+
+	if _, err := dto.GetSplitExercise(own.UserID, own.OtherSplitID, own.OtherExerciseID, s.DB); err != nil {
+		t.Errorf("exercise in its own split: %s", err.Error())
+	}
+}
+
+func TestOwnResourcesAreFound(t *testing.T) {
+	s, testServer := newTestServer(t)
+	own := createAccessFixture(t, s, "own@example.com")
+	createAccessFixture(t, s, "other@example.com")
+	client := loginClient(t, testServer, "own@example.com")
+
+	assertStatus(t, client, testServer, http.StatusOK, []accessCase{
+		{"edit split", http.MethodGet, fmt.Sprintf("/split/%d/edit", own.SplitID), nil},
+		{"edit split exercise", http.MethodGet, fmt.Sprintf("/split/%d/exercise/%d/edit", own.SplitID, own.ExerciseID), nil},
+		{"edit library exercise", http.MethodGet, fmt.Sprintf("/exercise/%d/edit", own.ExerciseID), nil},
+		{"exercise image", http.MethodGet, fmt.Sprintf("/exercise/image/%d", own.ExerciseID), nil},
+		{"history workout", http.MethodGet, fmt.Sprintf("/history/%d", own.WorkoutID), nil},
+	})
+}
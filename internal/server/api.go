@@ -0,0 +1,211 @@
+package server
+
+import (
+	"database/sql"
+	"dumbbell/internal/model"
+	"dumbbell/internal/mux"
+	"dumbbell/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+const ApiPrefix = "/api/v1"
+
+// apiHandler returns the value to encode as the JSON response body. Returning a nil value
+// responds with 204 No Content.
+type apiHandler func(r *http.Request, userId int64) (any, error)
+
+type apiParameter struct {
+	Name        string
+	Description string
+}
+
+// apiRoute describes an API endpoint, the same table is used to register the handlers and to
+// generate the OpenAPI document so the two can not drift apart.
+type apiRoute struct {
+	Method  string
+	Path    string
+	Summary string
+	Query   []apiParameter
+	// Zero value of the JSON request body, nil when the endpoint has no body.
+	Request any
+	// Zero value of the JSON response body, nil when the endpoint responds with 204 No Content.
+	Response any
+	Status   int
+	Handler  apiHandler
+}
+
+var apiPathParameterPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+func (s *HttpServer) apiRoutes() []apiRoute {
+	return []apiRoute{
+		{Method: http.MethodGet, Path: "/splits", Summary: "List splits", Response: []model.ApiSplit{}, Handler: s.apiListSplits},
+		{Method: http.MethodPost, Path: "/splits", Summary: "Create a split", Request: model.ApiSplitInput{}, Response: model.ApiSplit{}, Status: http.StatusCreated, Handler: s.apiCreateSplit},
+		{Method: http.MethodGet, Path: "/splits/{splitId}", Summary: "Get a split and its exercises", Response: model.ApiSplitDetail{}, Handler: s.apiGetSplit},
+		{Method: http.MethodPut, Path: "/splits/{splitId}", Summary: "Update a split", Request: model.ApiSplitInput{}, Response: model.ApiSplit{}, Handler: s.apiUpdateSplit},
+		{Method: http.MethodDelete, Path: "/splits/{splitId}", Summary: "Delete a split", Handler: s.apiDeleteSplit},
+		{Method: http.MethodGet, Path: "/splits/{splitId}/exercises", Summary: "List the exercises of a split", Response: []model.ApiExercise{}, Handler: s.apiListExercises},
+		{Method: http.MethodPost, Path: "/splits/{splitId}/exercises", Summary: "Add a library exercise to a split, or create a new one with a placeholder image", Request: model.ApiExerciseInput{}, Response: model.ApiExercise{}, Status: http.StatusCreated, Handler: s.apiCreateExercise},
+		{Method: http.MethodPut, Path: "/splits/{splitId}/exercises/order", Summary: "Reorder the exercises of a split, every exercise has to be listed", Request: model.ApiExerciseOrderInput{}, Response: []model.ApiExercise{}, Handler: s.apiReorderExercises},
+		{Method: http.MethodPost, Path: "/splits/{splitId}/exercises/groups", Summary: "Group exercises of a split into a superset or circuit, they are taken out of their previous groups", Request: model.ApiExerciseGroupInput{}, Response: []model.ApiExercise{}, Handler: s.apiGroupExercises},
+		{Method: http.MethodDelete, Path: "/splits/{splitId}/exercises/groups/{group}", Summary: "Ungroup a superset or circuit of a split", Response: []model.ApiExercise{}, Handler: s.apiUngroupExercises},
+		{Method: http.MethodPut, Path: "/splits/{splitId}/exercises/{exerciseId}", Summary: "Update the targets of an exercise in a split", Request: model.ApiExerciseTargetsInput{}, Response: model.ApiExercise{}, Handler: s.apiUpdateSplitExercise},
+		{Method: http.MethodDelete, Path: "/splits/{splitId}/exercises/{exerciseId}", Summary: "Remove an exercise from a split, it stays in the library", Handler: s.apiRemoveSplitExercise},
+		{Method: http.MethodGet, Path: "/exercises", Summary: "List the exercise library", Response: []model.ApiLibraryExercise{}, Handler: s.apiListLibraryExercises},
+		{Method: http.MethodPost, Path: "/exercises", Summary: "Create a library exercise with a placeholder image", Request: model.ApiLibraryExerciseInput{}, Response: model.ApiLibraryExercise{}, Status: http.StatusCreated, Handler: s.apiCreateLibraryExercise},
+		{Method: http.MethodGet, Path: "/exercises/{exerciseId}", Summary: "Get a library exercise", Response: model.ApiLibraryExercise{}, Handler: s.apiGetExercise},
+		{Method: http.MethodPut, Path: "/exercises/{exerciseId}", Summary: "Update a library exercise in every split it is used in", Request: model.ApiLibraryExerciseInput{}, Response: model.ApiLibraryExercise{}, Handler: s.apiUpdateExercise},
+		{Method: http.MethodDelete, Path: "/exercises/{exerciseId}", Summary: "Delete a library exercise, its sets and its place in every split", Handler: s.apiDeleteExercise},
+		{Method: http.MethodGet, Path: "/workouts", Summary: "List completed workouts, newest first", Query: []apiParameter{{Name: "split", Description: "Only workouts of this split"}, {Name: "page", Description: "Page number, starting at 1"}}, Response: model.ApiWorkoutList{}, Handler: s.apiListWorkouts},
+		{Method: http.MethodPost, Path: "/workouts", Summary: "Start a workout, or log a past workout when both times are set", Request: model.ApiWorkoutInput{}, Response: model.ApiWorkout{}, Status: http.StatusCreated, Handler: s.apiCreateWorkout},
+		{Method: http.MethodGet, Path: "/workouts/active", Summary: "Get the active workout", Response: model.ApiWorkout{}, Handler: s.apiGetActiveWorkout},
+		{Method: http.MethodGet, Path: "/workouts/{workoutId}", Summary: "Get a workout and its sets", Response: model.ApiWorkout{}, Handler: s.apiGetWorkout},
+		{Method: http.MethodDelete, Path: "/workouts/{workoutId}", Summary: "Delete a workout", Handler: s.apiDeleteWorkout},
+		{Method: http.MethodPost, Path: "/workouts/{workoutId}/complete", Summary: "Complete the active workout, the set in progress is dropped and the logged sets are kept", Response: model.ApiWorkout{}, Handler: s.apiCompleteWorkout},
+		{Method: http.MethodPost, Path: "/workouts/{workoutId}/exercise", Summary: "Start an exercise in the active workout, or the next one in the split's order", Request: model.ApiStartExerciseInput{}, Response: model.ApiWorkout{}, Handler: s.apiStartExercise},
+		{Method: http.MethodPost, Path: "/workouts/{workoutId}/next", Summary: "Log the current set of the active workout and start the next one", Request: model.ApiNextSetInput{}, Response: model.ApiWorkout{}, Handler: s.apiNextSet},
+		{Method: http.MethodPost, Path: "/workouts/{workoutId}/skip", Summary: "Skip the remaining sets of the current exercise of the active workout", Response: model.ApiWorkout{}, Handler: s.apiSkipExercise},
+		{Method: http.MethodPost, Path: "/workouts/{workoutId}/undo", Summary: "Open the last logged set of the active workout again, or take back the last skipped exercise", Response: model.ApiWorkout{}, Handler: s.apiUndoSet},
+		{Method: http.MethodPost, Path: "/workouts/{workoutId}/rest/end", Summary: "End the rest before the current set of the active workout and record how long it was", Response: model.ApiWorkout{}, Handler: s.apiEndRest},
+		{Method: http.MethodPost, Path: "/workouts/{workoutId}/sets", Summary: "Add a set to a completed workout", Request: model.ApiAddSetInput{}, Response: model.ApiWorkoutSet{}, Status: http.StatusCreated, Handler: s.apiAddSet},
+		{Method: http.MethodPut, Path: "/workouts/{workoutId}/exercises/{exerciseId}/sets/{setNumber}", Summary: "Update a set of a completed workout", Request: model.ApiSetInput{}, Response: model.ApiWorkoutSet{}, Handler: s.apiUpdateSet},
+		{Method: http.MethodDelete, Path: "/workouts/{workoutId}/exercises/{exerciseId}/sets/{setNumber}", Summary: "Delete a set of a completed workout", Handler: s.apiDeleteSet},
+		{Method: http.MethodGet, Path: "/stats", Summary: "Get workout statistics", Response: model.ApiStats{}, Handler: s.apiGetStats},
+	}
+}
+
+// registerApi adds every API route to the router, path parameters are matched as integers.
+func (s *HttpServer) registerApi(router *mux.HttpMux) {
+	for _, route := range s.apiRoutes() {
+		pattern := apiPathParameterPattern.ReplaceAllString(route.Path, `(?P<$1>[\d]+)`)
+		handler := s.serveApi(route)
+
+		switch route.Method {
+		case http.MethodGet:
+			router.Get(pattern, handler)
+		case http.MethodPost:
+			router.Post(pattern, handler)
+		case http.MethodPut:
+			router.Put(pattern, handler)
+		case http.MethodDelete:
+			router.Delete(pattern, handler)
+		}
+	}
+
+	router.HandleFunc(".*", func(w http.ResponseWriter, r *http.Request) {
+		writeApiError(w, http.StatusNotFound, "Not found")
+	})
+}
+
+func (s *HttpServer) serveApi(route apiRoute) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, err := s.SessionService.GetUserId(r)
+		if err != nil || userId == 0 {
+			writeApiError(w, http.StatusUnauthorized, "Not authenticated")
+			return
+		}
+
+		response, err := route.Handler(r, userId)
+		if err != nil {
+			respondApiError(w, fmt.Sprintf("%s %s", route.Method, route.Path), err)
+			return
+		}
+
+		if response == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		writeJSON(w, status, response)
+	})
+}
+
+// apiAuthMiddleware accepts a bearer API token or the session cookie.
+func (s *HttpServer) apiAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, err := s.SessionService.AuthenticateApiToken(r)
+		if err != nil {
+			switch err {
+			case service.InvalidApiTokenError:
+				writeApiError(w, http.StatusUnauthorized, err.Error())
+			case service.ReadOnlyApiTokenError:
+				writeApiError(w, http.StatusForbidden, err.Error())
+			default:
+				respondApiError(w, "apiAuthMiddleware", err)
+			}
+			return
+		}
+
+		if !s.SessionService.IsAuthenticated(r) {
+			writeApiError(w, http.StatusUnauthorized, "Not authenticated")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Error writing json response: %s", err.Error())
+	}
+}
+
+func writeApiError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, model.ApiErrorResponse{
+		Error: model.ApiError{Status: status, Message: message},
+	})
+}
+
+// respondApiError is the JSON counterpart of respondAccessError.
+func respondApiError(w http.ResponseWriter, context string, err error) {
+	var apiErr *model.ApiError
+	switch {
+	case errors.As(err, &apiErr):
+		writeApiError(w, apiErr.Status, apiErr.Message)
+	case errors.Is(err, sql.ErrNoRows):
+		writeApiError(w, http.StatusNotFound, "Not found")
+	case errors.Is(err, ErrorResourceMismatch):
+		writeApiError(w, http.StatusForbidden, "Forbidden")
+	default:
+		log.Printf("%s: %s", context, err.Error())
+		writeApiError(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
+
+func apiBadRequest(message string) error {
+	return &model.ApiError{Status: http.StatusBadRequest, Message: message}
+}
+
+// decodeApiBody reads the JSON body of a request. The body has to be sent as application/json, the router
+// parses any other body as a form before the handler sees it.
+func decodeApiBody(r *http.Request, value any) error {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		return &model.ApiError{Status: http.StatusUnsupportedMediaType, Message: "Request body must be sent with Content-Type: application/json"}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(value); err != nil {
+		return apiBadRequest(fmt.Sprintf("Invalid request body: %s", err.Error()))
+	}
+	return nil
+}
+
+func apiPathInt64(r *http.Request, name string) (int64, error) {
+	value, err := strconv.ParseInt(r.FormValue(name), 10, 64)
+	if err != nil {
+		return 0, apiBadRequest(fmt.Sprintf("Invalid %s", name))
+	}
+	return value, nil
+}
@@ -0,0 +1,565 @@
+package server
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+func toApiSplit(split dto.Split) model.ApiSplit {
+	return model.ApiSplit{
+		ID:          split.ID,
+		Name:        split.Name,
+		Description: split.Description,
+	}
+}
+
+func toApiExercise(exercise dto.Exercise) model.ApiExercise {
+	return model.ApiExercise{
+		ID:           exercise.ID,
+		SplitID:      exercise.SplitID,
+		Name:         exercise.Name,
+		Description:  exercise.Description,
+		MuscleGroups: exercise.MuscleGroups,
+		Measurement:  string(exercise.Measurement),
+		Position:     exercise.Position,
+		WeightFrom:   exercise.WeightFrom,
+		WeightTo:     exercise.WeightTo,
+		RepsFrom:     exercise.RepsFrom,
+		RepsTo:       exercise.RepsTo,
+		SecondsFrom:  exercise.SecondsFrom,
+		SecondsTo:    exercise.SecondsTo,
+		DistanceFrom: exercise.DistanceFrom,
+		DistanceTo:   exercise.DistanceTo,
+		Sets:         exercise.Sets,
+		RestSeconds:  exercise.RestSeconds,
+		Group:        exercise.GroupNumber,
+		ImageURL:     exercise.GetImageURL(),
+	}
+}
+
+// toApiLibraryExercise lists the muscle groups of the exercise out of muscleGroups, which can hold those
+// of other exercises too.
+func toApiLibraryExercise(exercise dto.Exercise, muscleGroups []dto.ExerciseMuscleGroup) model.ApiLibraryExercise {
+	libraryExercise := model.ApiLibraryExercise{
+		ID:                    exercise.ID,
+		Name:                  exercise.Name,
+		Description:           exercise.Description,
+		MuscleGroups:          exercise.MuscleGroups,
+		PrimaryMuscleGroups:   []string{},
+		SecondaryMuscleGroups: []string{},
+		Measurement:           string(exercise.Measurement),
+		ImageURL:              exercise.GetImageURL(),
+	}
+	for _, muscleGroup := range muscleGroups {
+		if muscleGroup.ExerciseID != exercise.ID {
+			continue
+		}
+		if muscleGroup.Role == dto.MusclePrimary {
+			libraryExercise.PrimaryMuscleGroups = append(libraryExercise.PrimaryMuscleGroups, string(muscleGroup.MuscleGroup))
+		} else {
+			libraryExercise.SecondaryMuscleGroups = append(libraryExercise.SecondaryMuscleGroups, string(muscleGroup.MuscleGroup))
+		}
+	}
+	return libraryExercise
+}
+
+// toApiLibraryExerciseWithMuscleGroups reads the muscle groups of a single exercise for toApiLibraryExercise.
+func (s *HttpServer) toApiLibraryExerciseWithMuscleGroups(exercise dto.Exercise) (model.ApiLibraryExercise, error) {
+	muscleGroups, err := dto.GetExerciseMuscleGroups(exercise.ID, s.DB)
+	if err != nil {
+		return model.ApiLibraryExercise{}, err
+	}
+	return toApiLibraryExercise(exercise, muscleGroups), nil
+}
+
+func validateApiSplitInput(input model.ApiSplitInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return apiBadRequest("name is required")
+	}
+	return nil
+}
+
+func validateApiLibraryExerciseInput(input model.ApiLibraryExerciseInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return apiBadRequest("name is required")
+	}
+	if input.Measurement != "" && !dto.Measurement(input.Measurement).IsValid() {
+		return apiBadRequest("measurement must be weight_reps, bodyweight_reps, duration or distance")
+	}
+	for _, muscleGroup := range append(append([]string{}, input.PrimaryMuscleGroups...), input.SecondaryMuscleGroups...) {
+		if !dto.MuscleGroup(muscleGroup).IsValid() {
+			return apiBadRequest("unknown muscle group " + muscleGroup)
+		}
+	}
+	return nil
+}
+
+// saveApiMuscleGroups tags the exercise with the muscle groups of the input, when it has any.
+func (s *HttpServer) saveApiMuscleGroups(userId int64, exercise dto.Exercise, input model.ApiLibraryExerciseInput) (dto.Exercise, error) {
+	if input.PrimaryMuscleGroups == nil && input.SecondaryMuscleGroups == nil {
+		return exercise, nil
+	}
+
+	primary := []dto.MuscleGroup{}
+	for _, muscleGroup := range input.PrimaryMuscleGroups {
+		primary = append(primary, dto.MuscleGroup(muscleGroup))
+	}
+	secondary := []dto.MuscleGroup{}
+	for _, muscleGroup := range input.SecondaryMuscleGroups {
+		secondary = append(secondary, dto.MuscleGroup(muscleGroup))
+	}
+
+	if err := dto.SetExerciseMuscleGroups(userId, exercise.ID, primary, secondary, s.DB); err != nil {
+		return dto.Exercise{}, err
+	}
+	return dto.GetExercise(userId, exercise.ID, s.DB)
+}
+
+func validateApiExerciseTargetsInput(input model.ApiExerciseTargetsInput) error {
+	if input.WeightFrom < 0 || input.WeightTo < input.WeightFrom {
+		return apiBadRequest("weightFrom and weightTo must be a non negative range")
+	}
+	if input.RepsFrom < 0 || input.RepsTo < input.RepsFrom {
+		return apiBadRequest("repsFrom and repsTo must be a non negative range")
+	}
+	if input.SecondsFrom < 0 || input.SecondsTo < input.SecondsFrom {
+		return apiBadRequest("secondsFrom and secondsTo must be a non negative range")
+	}
+	if input.DistanceFrom < 0 || input.DistanceTo < input.DistanceFrom {
+		return apiBadRequest("distanceFrom and distanceTo must be a non negative range")
+	}
+	if input.Sets < 1 {
+		return apiBadRequest("sets must be at least 1")
+	}
+	if input.RestSeconds != nil && *input.RestSeconds < 0 {
+		return apiBadRequest("restSeconds can not be negative")
+	}
+	return nil
+}
+
+func (s *HttpServer) apiListSplits(r *http.Request, userId int64) (any, error) {
+	splits, err := dto.GetSplits(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []model.ApiSplit{}
+	for _, split := range splits {
+		response = append(response, toApiSplit(split))
+	}
+
+	return response, nil
+}
+
+func (s *HttpServer) apiCreateSplit(r *http.Request, userId int64) (any, error) {
+	input := model.ApiSplitInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+	if err := validateApiSplitInput(input); err != nil {
+		return nil, err
+	}
+
+	split, err := dto.CreateSplit(userId, input.Name, input.Description, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return toApiSplit(split), nil
+}
+
+func (s *HttpServer) apiGetSplit(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+
+	split, err := dto.GetSplit(userId, splitId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	exercises, err := s.getApiExercises(split.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ApiSplitDetail{
+		ApiSplit:  toApiSplit(split),
+		Exercises: exercises,
+	}, nil
+}
+
+func (s *HttpServer) apiUpdateSplit(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+
+	input := model.ApiSplitInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+	if err := validateApiSplitInput(input); err != nil {
+		return nil, err
+	}
+
+	split, err := dto.UpdateSplit(userId, splitId, input.Name, input.Description, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return toApiSplit(split), nil
+}
+
+func (s *HttpServer) apiDeleteSplit(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dto.DeleteSplit(userId, splitId, s.DB); err != nil {
+		return nil, err
+	}
+	s.updateAllRecords(userId)
+
+	return nil, nil
+}
+
+func (s *HttpServer) apiListExercises(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.GetSplit(userId, splitId, s.DB); err != nil {
+		return nil, err
+	}
+
+	return s.getApiExercises(splitId)
+}
+
+func (s *HttpServer) getApiExercises(splitId int64) ([]model.ApiExercise, error) {
+	exercises, err := dto.GetAllExercises(splitId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []model.ApiExercise{}
+	for _, exercise := range exercises {
+		response = append(response, toApiExercise(exercise))
+	}
+
+	return response, nil
+}
+
+func (s *HttpServer) apiCreateExercise(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+
+	input := model.ApiExerciseInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+	if input.ExerciseID == nil {
+		if err := validateApiLibraryExerciseInput(model.ApiLibraryExerciseInput{
+			Name:                  input.Name,
+			Measurement:           input.Measurement,
+			PrimaryMuscleGroups:   input.PrimaryMuscleGroups,
+			SecondaryMuscleGroups: input.SecondaryMuscleGroups,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateApiExerciseTargetsInput(input.ApiExerciseTargetsInput); err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.GetSplit(userId, splitId, s.DB); err != nil {
+		return nil, err
+	}
+
+	var exerciseId int64
+	if input.ExerciseID != nil {
+		exerciseId = *input.ExerciseID
+		if _, err = dto.GetExercise(userId, exerciseId, s.DB); err != nil {
+			return nil, err
+		}
+	} else {
+		exercise, err := s.createApiLibraryExercise(userId, model.ApiLibraryExerciseInput{
+			Name:                  input.Name,
+			Description:           input.Description,
+			MuscleGroups:          input.MuscleGroups,
+			PrimaryMuscleGroups:   input.PrimaryMuscleGroups,
+			SecondaryMuscleGroups: input.SecondaryMuscleGroups,
+			Measurement:           input.Measurement,
+		})
+		if err != nil {
+			return nil, err
+		}
+		exerciseId = exercise.ID
+	}
+
+	targets := input.ApiExerciseTargetsInput
+	restSeconds := int64(dto.DefaultRestSeconds)
+	if targets.RestSeconds != nil {
+		restSeconds = *targets.RestSeconds
+	}
+
+	exercise, err := dto.AddSplitExercise(userId, splitId, exerciseId, targets.WeightFrom, targets.WeightTo, targets.RepsFrom, targets.RepsTo, targets.SecondsFrom, targets.SecondsTo, targets.DistanceFrom, targets.DistanceTo, targets.Sets, restSeconds, s.DB)
+	if errors.Is(err, dto.ErrorExerciseInSplit) {
+		return nil, apiConflict(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toApiExercise(exercise), nil
+}
+
+func (s *HttpServer) apiUpdateSplitExercise(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+	exerciseId, err := apiPathInt64(r, "exerciseId")
+	if err != nil {
+		return nil, err
+	}
+
+	input := model.ApiExerciseTargetsInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+	if err := validateApiExerciseTargetsInput(input); err != nil {
+		return nil, err
+	}
+
+	exercise, err := dto.GetSplitExercise(userId, splitId, exerciseId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	restSeconds := exercise.RestSeconds
+	if input.RestSeconds != nil {
+		restSeconds = *input.RestSeconds
+	}
+
+	exercise, err = dto.UpdateSplitExercise(userId, splitId, exerciseId, input.WeightFrom, input.WeightTo, input.RepsFrom, input.RepsTo, input.SecondsFrom, input.SecondsTo, input.DistanceFrom, input.DistanceTo, input.Sets, restSeconds, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return toApiExercise(exercise), nil
+}
+
+func (s *HttpServer) apiReorderExercises(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.GetSplit(userId, splitId, s.DB); err != nil {
+		return nil, err
+	}
+
+	input := model.ApiExerciseOrderInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+
+	err = dto.ReorderSplitExercises(userId, splitId, input.ExerciseIDs, s.DB)
+	if errors.Is(err, dto.ErrorExerciseOrderMismatch) {
+		return nil, apiBadRequest(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getApiExercises(splitId)
+}
+
+func (s *HttpServer) apiGroupExercises(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.GetSplit(userId, splitId, s.DB); err != nil {
+		return nil, err
+	}
+
+	input := model.ApiExerciseGroupInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+
+	_, err = dto.GroupSplitExercises(userId, splitId, input.ExerciseIDs, s.DB)
+	if errors.Is(err, dto.ErrorExerciseGroupTooSmall) {
+		return nil, apiBadRequest(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getApiExercises(splitId)
+}
+
+func (s *HttpServer) apiUngroupExercises(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+	group, err := apiPathInt64(r, "group")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.GetSplit(userId, splitId, s.DB); err != nil {
+		return nil, err
+	}
+
+	if err = dto.UngroupSplitExercises(userId, splitId, group, s.DB); err != nil {
+		return nil, err
+	}
+
+	return s.getApiExercises(splitId)
+}
+
+func (s *HttpServer) apiRemoveSplitExercise(r *http.Request, userId int64) (any, error) {
+	splitId, err := apiPathInt64(r, "splitId")
+	if err != nil {
+		return nil, err
+	}
+	exerciseId, err := apiPathInt64(r, "exerciseId")
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, dto.RemoveSplitExercise(userId, splitId, exerciseId, s.DB)
+}
+
+func (s *HttpServer) apiListLibraryExercises(r *http.Request, userId int64) (any, error) {
+	exercises, err := dto.GetLibraryExercises(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	muscleGroups, err := dto.GetUserExerciseMuscleGroups(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []model.ApiLibraryExercise{}
+	for _, exercise := range exercises {
+		response = append(response, toApiLibraryExercise(exercise, muscleGroups))
+	}
+
+	return response, nil
+}
+
+func (s *HttpServer) apiCreateLibraryExercise(r *http.Request, userId int64) (any, error) {
+	input := model.ApiLibraryExerciseInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+	if err := validateApiLibraryExerciseInput(input); err != nil {
+		return nil, err
+	}
+
+	exercise, err := s.createApiLibraryExercise(userId, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toApiLibraryExerciseWithMuscleGroups(exercise)
+}
+
+func (s *HttpServer) createApiLibraryExercise(userId int64, input model.ApiLibraryExerciseInput) (dto.Exercise, error) {
+	image, err := s.ExerciseService.CreatePlaceholderImage()
+	if err != nil {
+		return dto.Exercise{}, err
+	}
+
+	measurement := dto.MeasurementWeightReps
+	if input.Measurement != "" {
+		measurement = dto.Measurement(input.Measurement)
+	}
+
+	exercise, err := dto.CreateExercise(userId, &image.ID, strings.TrimSpace(input.Name), input.Description, input.MuscleGroups, measurement, s.DB)
+	if errors.Is(err, dto.ErrorExerciseNameTaken) {
+		return dto.Exercise{}, apiConflict(err.Error())
+	}
+	if err != nil {
+		return dto.Exercise{}, err
+	}
+
+	return s.saveApiMuscleGroups(userId, exercise, input)
+}
+
+func (s *HttpServer) apiGetExercise(r *http.Request, userId int64) (any, error) {
+	exerciseId, err := apiPathInt64(r, "exerciseId")
+	if err != nil {
+		return nil, err
+	}
+
+	exercise, err := dto.GetExercise(userId, exerciseId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toApiLibraryExerciseWithMuscleGroups(exercise)
+}
+
+func (s *HttpServer) apiUpdateExercise(r *http.Request, userId int64) (any, error) {
+	exerciseId, err := apiPathInt64(r, "exerciseId")
+	if err != nil {
+		return nil, err
+	}
+
+	input := model.ApiLibraryExerciseInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+	if err := validateApiLibraryExerciseInput(input); err != nil {
+		return nil, err
+	}
+
+	exercise, err := dto.GetExercise(userId, exerciseId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement := exercise.Measurement
+	if input.Measurement != "" {
+		measurement = dto.Measurement(input.Measurement)
+	}
+
+	exercise, err = dto.UpdateExercise(userId, exerciseId, strings.TrimSpace(input.Name), nil, input.Description, input.MuscleGroups, measurement, s.DB)
+	if errors.Is(err, dto.ErrorExerciseNameTaken) {
+		return nil, apiConflict(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if exercise, err = s.saveApiMuscleGroups(userId, exercise, input); err != nil {
+		return nil, err
+	}
+	s.updateRecords(userId, exercise.ID)
+
+	return s.toApiLibraryExerciseWithMuscleGroups(exercise)
+}
+
+func (s *HttpServer) apiDeleteExercise(r *http.Request, userId int64) (any, error) {
+	exerciseId, err := apiPathInt64(r, "exerciseId")
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, dto.DeleteExercise(userId, exerciseId, s.DB)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"net/http"
+)
+
+func (s *HttpServer) apiGetStats(r *http.Request, userId int64) (any, error) {
+	completedWorkouts, err := dto.CountCompletedWorkouts(userId, 0, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	workoutActivity, err := s.WorkoutService.GetWorkoutActivity(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	workoutSplits, err := s.WorkoutService.GetWorkoutSplits(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ApiStats{
+		CompletedWorkouts: completedWorkouts,
+		Activity:          []model.ApiMonthActivity{},
+		Splits:            []model.ApiSplitStats{},
+	}
+
+	for _, month := range workoutActivity.Months {
+		response.Activity = append(response.Activity, model.ApiMonthActivity{
+			Month:    month.Month,
+			ThisYear: month.ThisYearActivity,
+			LastYear: month.LastYearActivity,
+		})
+	}
+
+	for _, split := range workoutSplits {
+		response.GoodSets += split.TotalGoodRatings
+		response.BadSets += split.TotalBadRatings
+		response.Splits = append(response.Splits, model.ApiSplitStats{
+			ID:          split.ID,
+			Name:        split.SplitName,
+			GoodRatings: split.TotalGoodRatings,
+			BadRatings:  split.TotalBadRatings,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,479 @@
+package server
+
+import (
+	"database/sql"
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"dumbbell/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func apiConflict(message string) error {
+	return &model.ApiError{Status: http.StatusConflict, Message: message}
+}
+
+func nullTimeToPointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+func nullInt64ToPointer(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
+func toApiWorkoutSet(workoutSet dto.WorkoutSet) model.ApiWorkoutSet {
+	return model.ApiWorkoutSet{
+		WorkoutID:   workoutSet.WorkoutID,
+		ExerciseID:  workoutSet.ExerciseID,
+		SetNumber:   workoutSet.SetNumber,
+		Rating:      string(workoutSet.SetRating),
+		Type:        string(workoutSet.SetType),
+		Weight:      workoutSet.Weight,
+		Reps:        workoutSet.Reps,
+		Seconds:     workoutSet.Seconds,
+		Distance:    workoutSet.Distance,
+		StartedAt:   workoutSet.StartedAt,
+		CompletedAt: nullTimeToPointer(workoutSet.CompletedAt),
+		RestEndsAt:  nullTimeToPointer(workoutSet.RestEndsAt),
+		RestSeconds: nullInt64ToPointer(workoutSet.RestSeconds),
+	}
+}
+
+func validateApiSet(input model.ApiSetInput) (dto.SetStatus, dto.SetType, error) {
+	status := dto.SetStatus(input.Rating)
+	if status != dto.SetGood && status != dto.SetBad {
+		return "", "", apiBadRequest("rating must be good or bad")
+	}
+
+	setType := dto.SetWorking
+	if input.Type != "" {
+		setType = dto.SetType(input.Type)
+		if !setType.IsValid() {
+			return "", "", apiBadRequest("type must be warmup, working, drop, amrap or failure")
+		}
+	}
+
+	if input.Weight < 0 || input.Reps < 0 || input.Seconds < 0 || input.Distance < 0 {
+		return "", "", apiBadRequest("weight, reps, seconds and distance can not be negative")
+	}
+	return status, setType, nil
+}
+
+func (s *HttpServer) getApiWorkout(workout dto.Workout) (model.ApiWorkout, error) {
+	workoutSets, err := dto.GetWorkoutSets(workout.ID, s.DB)
+	if err != nil {
+		return model.ApiWorkout{}, err
+	}
+
+	sets := []model.ApiWorkoutSet{}
+	for _, workoutSet := range workoutSets {
+		sets = append(sets, toApiWorkoutSet(workoutSet))
+	}
+
+	return model.ApiWorkout{
+		ID:          workout.ID,
+		SplitID:     workout.SplitID,
+		StartedAt:   workout.StartedAt,
+		CompletedAt: nullTimeToPointer(workout.CompletedAt),
+		Sets:        sets,
+	}, nil
+}
+
+// getActiveApiWorkout returns the workout from the path, which has to be the active workout of the user.
+func (s *HttpServer) getActiveApiWorkout(r *http.Request, userId int64) (dto.Workout, error) {
+	workoutId, err := apiPathInt64(r, "workoutId")
+	if err != nil {
+		return dto.Workout{}, err
+	}
+
+	workout, err := dto.GetWorkout(userId, workoutId, s.DB)
+	if err != nil {
+		return dto.Workout{}, err
+	}
+
+	if workout.CompletedAt.Valid {
+		return dto.Workout{}, apiConflict("Workout is already completed")
+	}
+
+	return workout, nil
+}
+
+func (s *HttpServer) apiListWorkouts(r *http.Request, userId int64) (any, error) {
+	var splitId int64
+	if splitIdString := r.URL.Query().Get("split"); splitIdString != "" {
+		parsedSplitId, err := strconv.ParseInt(splitIdString, 10, 64)
+		if err != nil {
+			return nil, apiBadRequest("Invalid split")
+		}
+		splitId = parsedSplitId
+	}
+
+	page := 1
+	if pageString := r.URL.Query().Get("page"); pageString != "" {
+		parsedPage, err := strconv.Atoi(pageString)
+		if err != nil || parsedPage < 1 {
+			return nil, apiBadRequest("Invalid page")
+		}
+		page = parsedPage
+	}
+
+	count, err := dto.CountCompletedWorkouts(userId, splitId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := dto.GetCompletedWorkoutSummaries(userId, splitId, service.HistoryPageSize, (page-1)*service.HistoryPageSize, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ApiWorkoutList{
+		Workouts:  []model.ApiWorkoutSummary{},
+		Page:      page,
+		PageCount: (count + service.HistoryPageSize - 1) / service.HistoryPageSize,
+	}
+
+	for _, summary := range summaries {
+		response.Workouts = append(response.Workouts, model.ApiWorkoutSummary{
+			ID:          summary.ID,
+			SplitID:     summary.SplitID,
+			SplitName:   summary.SplitName,
+			StartedAt:   summary.StartedAt,
+			CompletedAt: nullTimeToPointer(summary.CompletedAt),
+			SetCount:    summary.SetCount,
+			GoodCount:   summary.GoodCount,
+			BadCount:    summary.BadCount,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *HttpServer) apiCreateWorkout(r *http.Request, userId int64) (any, error) {
+	input := model.ApiWorkoutInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+
+	if _, err := dto.GetSplit(userId, input.SplitID, s.DB); err != nil {
+		return nil, err
+	}
+
+	var workout dto.Workout
+	if input.StartedAt != nil || input.CompletedAt != nil {
+		if input.StartedAt == nil || input.CompletedAt == nil {
+			return nil, apiBadRequest("startedAt and completedAt have to be set together")
+		}
+
+		if !input.CompletedAt.After(*input.StartedAt) || input.CompletedAt.After(time.Now()) {
+			return nil, apiBadRequest("completedAt has to be after startedAt and not in the future")
+		}
+
+		pastWorkout, err := dto.CreatePastWorkout(userId, input.SplitID, *input.StartedAt, *input.CompletedAt, s.DB)
+		if err != nil {
+			return nil, err
+		}
+		workout = pastWorkout
+	} else {
+		if _, err := dto.GetActiveWorkout(userId, s.DB); err == nil {
+			return nil, apiConflict("A workout is already active")
+		} else if err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		newWorkout, err := dto.NewWorkout(input.SplitID, userId, s.DB)
+		if err != nil {
+			return nil, err
+		}
+		workout = newWorkout
+	}
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiGetActiveWorkout(r *http.Request, userId int64) (any, error) {
+	workout, err := dto.GetActiveWorkout(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiGetWorkout(r *http.Request, userId int64) (any, error) {
+	workoutId, err := apiPathInt64(r, "workoutId")
+	if err != nil {
+		return nil, err
+	}
+
+	workout, err := dto.GetWorkout(userId, workoutId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiDeleteWorkout(r *http.Request, userId int64) (any, error) {
+	workoutId, err := apiPathInt64(r, "workoutId")
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, s.WorkoutService.DeleteWorkout(userId, workoutId)
+}
+
+func (s *HttpServer) apiCompleteWorkout(r *http.Request, userId int64) (any, error) {
+	workout, err := s.getActiveApiWorkout(r, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.WorkoutService.CompleteWorkout(userId, workout.ID); err != nil {
+		return nil, err
+	}
+
+	workout, err = dto.GetWorkout(userId, workout.ID, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiStartExercise(r *http.Request, userId int64) (any, error) {
+	workout, err := s.getActiveApiWorkout(r, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	input := model.ApiStartExerciseInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+
+	if input.ExerciseID == nil {
+		remaining, err := dto.GetRemainingWorkoutExercises(workout.SplitID, workout.ID, s.DB)
+		if err != nil {
+			return nil, err
+		}
+		if len(remaining) == 0 {
+			return nil, apiConflict("Every exercise is already done in this workout")
+		}
+		input.ExerciseID = &remaining[0].ID
+	}
+
+	if _, err = dto.GetExercise(userId, *input.ExerciseID, s.DB); err != nil {
+		return nil, err
+	}
+
+	exercise, err := dto.GetSplitExercise(userId, workout.SplitID, *input.ExerciseID, s.DB)
+	if err == sql.ErrNoRows {
+		return nil, ErrorResourceMismatch
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.GetActiveWorkoutSet(workout.ID, s.DB); err == nil {
+		return nil, apiConflict("An exercise is already in progress")
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	completedSets, err := dto.GetCompletedWorkoutSets(workout.ID, exercise.ID, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	if len(completedSets) > 0 {
+		return nil, apiConflict("Exercise is already done in this workout")
+	}
+
+	skipped, err := dto.GetSkippedExercises(workout.ID, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	if skipped[exercise.ID] {
+		return nil, apiConflict("Exercise was skipped in this workout")
+	}
+
+	if _, err = dto.CreateNewSet(workout.ID, exercise.ID, s.DB); err != nil {
+		return nil, err
+	}
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiNextSet(r *http.Request, userId int64) (any, error) {
+	workout, err := s.getActiveApiWorkout(r, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	input := model.ApiNextSetInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+
+	rating, setType, err := validateApiSet(input.ApiSetInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.DropSet {
+		_, err = dto.CreateDropSet(workout.ID, rating, setType, input.Weight, input.Reps, input.Seconds, input.Distance, s.DB)
+	} else {
+		_, err = dto.CreateNextSet(workout.ID, rating, setType, input.Weight, input.Reps, input.Seconds, input.Distance, s.DB)
+	}
+	if err != sql.ErrNoRows {
+		s.updateWorkoutRecords(userId, workout.ID)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apiConflict("No set in progress")
+		}
+		if err != dto.ErrorSetLimitReached {
+			return nil, err
+		}
+	}
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiSkipExercise(r *http.Request, userId int64) (any, error) {
+	workout, err := s.getActiveApiWorkout(r, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.SkipExercise(workout.ID, s.DB); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apiConflict("No set in progress")
+		}
+		if err != dto.ErrorSetLimitReached {
+			return nil, err
+		}
+	}
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiUndoSet(r *http.Request, userId int64) (any, error) {
+	workout, err := s.getActiveApiWorkout(r, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.UndoLastSet(workout.ID, s.DB); err != nil {
+		if err == dto.ErrorNothingToUndo {
+			return nil, apiConflict(err.Error())
+		}
+		return nil, err
+	}
+	s.updateWorkoutRecords(userId, workout.ID)
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiEndRest(r *http.Request, userId int64) (any, error) {
+	workout, err := s.getActiveApiWorkout(r, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dto.EndRest(workout.ID, s.DB); err != nil {
+		if err == dto.ErrorNotResting {
+			return nil, apiConflict(err.Error())
+		}
+		return nil, err
+	}
+
+	return s.getApiWorkout(workout)
+}
+
+func (s *HttpServer) apiAddSet(r *http.Request, userId int64) (any, error) {
+	workoutId, err := apiPathInt64(r, "workoutId")
+	if err != nil {
+		return nil, err
+	}
+
+	input := model.ApiAddSetInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+
+	rating, setType, err := validateApiSet(input.ApiSetInput)
+	if err != nil {
+		return nil, err
+	}
+
+	workoutSet, err := dto.AddWorkoutSet(userId, workoutId, input.ExerciseID, rating, setType, input.Weight, input.Reps, input.Seconds, input.Distance, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	s.updateRecords(userId, workoutSet.ExerciseID)
+
+	return toApiWorkoutSet(workoutSet), nil
+}
+
+func (s *HttpServer) apiUpdateSet(r *http.Request, userId int64) (any, error) {
+	workoutId, err := apiPathInt64(r, "workoutId")
+	if err != nil {
+		return nil, err
+	}
+	exerciseId, err := apiPathInt64(r, "exerciseId")
+	if err != nil {
+		return nil, err
+	}
+	setNumber, err := apiPathInt64(r, "setNumber")
+	if err != nil {
+		return nil, err
+	}
+
+	input := model.ApiSetInput{}
+	if err := decodeApiBody(r, &input); err != nil {
+		return nil, err
+	}
+
+	rating, setType, err := validateApiSet(input)
+	if err != nil {
+		return nil, err
+	}
+
+	workoutSet, err := dto.UpdateWorkoutSet(userId, workoutId, exerciseId, setNumber, rating, setType, input.Weight, input.Reps, input.Seconds, input.Distance, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	s.updateRecords(userId, workoutSet.ExerciseID)
+
+	return toApiWorkoutSet(workoutSet), nil
+}
+
+func (s *HttpServer) apiDeleteSet(r *http.Request, userId int64) (any, error) {
+	workoutId, err := apiPathInt64(r, "workoutId")
+	if err != nil {
+		return nil, err
+	}
+	exerciseId, err := apiPathInt64(r, "exerciseId")
+	if err != nil {
+		return nil, err
+	}
+	setNumber, err := apiPathInt64(r, "setNumber")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dto.DeleteWorkoutSet(userId, workoutId, exerciseId, setNumber, s.DB); err != nil {
+		return nil, err
+	}
+	s.updateRecords(userId, exerciseId)
+
+	return nil, nil
+}
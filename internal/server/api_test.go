@@ -0,0 +1,42 @@
+package server
+
+import (
+	"dumbbell/internal/dto"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestApiBodyNeedsJsonContentType(t *testing.T) {
+	s, testServer := newTestServer(t)
+	if _, err := dto.CreateUser("own@example.com", "password", s.DB); err != nil {
+		t.Fatal(err)
+	}
+	client := loginClient(t, testServer, "own@example.com")
+
+	for contentType, status := range map[string]int{
+		"":                                  http.StatusUnsupportedMediaType,
+		"text/plain":                        http.StatusUnsupportedMediaType,
+		"application/x-www-form-urlencoded": http.StatusUnsupportedMediaType,
+		"application/json":                  http.StatusCreated,
+		"application/json; charset=utf-8":   http.StatusCreated,
+	} {
+		request, err := http.NewRequest(http.MethodPost, testServer.URL+ApiPrefix+"/splits", strings.NewReader(`{"name": "Push"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType != "" {
+			request.Header.Set("Content-Type", contentType)
+		}
+
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		if response.StatusCode != status {
+			t.Errorf("Content-Type %q: got status %d, want %d", contentType, response.StatusCode, status)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"dumbbell/internal/service"
+	"dumbbell/internal/templates"
+	"dumbbell/internal/utils"
+	"log"
+	"net/http"
+)
+
+func (s *HttpServer) exerciseProgressHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	exerciseId := utils.MustParseInt64(r.FormValue("id"))
+	progressRange := service.ParseProgressRange(r.URL.Query().Get("range"))
+
+	viewModel, err := s.WorkoutService.GetExerciseProgressModel(userId, exerciseId, progressRange)
+	if err != nil {
+		respondAccessError(w, "exerciseProgressHandler", err)
+		return
+	}
+	viewModel.Header = s.SessionService.GetHeaderModel(r)
+
+	var templateErr error
+	if s.HtmxService.IsHtmxRequest(r) {
+		templateErr = templates.ExerciseProgress.Execute(w, viewModel)
+	} else {
+		templateErr = templates.ExecutePageTemplate(w, "exerciseProgress.html", viewModel)
+	}
+
+	if templateErr != nil {
+		log.Printf("Error in exercise progress template: %s", templateErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
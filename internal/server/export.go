@@ -0,0 +1,98 @@
+package server
+
+import (
+	"dumbbell/internal/importer"
+	"dumbbell/internal/model"
+	"dumbbell/internal/service"
+	"dumbbell/internal/templates"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+func (s *HttpServer) exportData(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="dumbbell-export-%s.zip"`, time.Now().In(service.GetUserLocation(userId, s.DB)).Format(time.DateOnly)))
+
+	// The zip is streamed, once it has started the status can not be changed so errors are only logged.
+	if err := s.ExportService.Export(w, userId); err != nil {
+		log.Printf("Error exporting data: %s", err.Error())
+	}
+}
+
+func (s *HttpServer) importData(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		templates.AlertBanner.Execute(w, model.BannerModel{
+			SwapTarget:  "beforebegin:#import-data",
+			Description: "Pick an export to import",
+		})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		log.Printf("importData error failed to read file: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	result, err := s.ExportService.Import(userId, content)
+	if err != nil {
+		if errors.Is(err, service.InvalidExportError) {
+			templates.AlertBanner.Execute(w, model.BannerModel{
+				SwapTarget:  "beforebegin:#import-data",
+				Description: err.Error(),
+			})
+		} else {
+			log.Printf("Error importing data: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Add("HX-Trigger", "data-imported")
+	templates.SuccessBanner.Execute(w, model.BannerModel{
+		SwapTarget:  "beforebegin:#import-data",
+		Description: fmt.Sprintf("Imported %d splits, %d exercises, %d workouts and %d sets. Reload the page to see them.", result.Splits, result.Exercises, result.Workouts, result.Sets),
+	})
+}
+
+func (s *HttpServer) importCsv(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		templates.AlertBanner.Execute(w, model.BannerModel{
+			SwapTarget:  "beforebegin:#import-csv",
+			Description: "Pick a CSV file to import",
+		})
+		return
+	}
+	defer file.Close()
+
+	dryRun := r.FormValue("dry-run") == "true"
+	result, err := s.ExportService.ImportCSV(userId, file, service.GetUserLocation(userId, s.DB), dryRun)
+	if err != nil {
+		if errors.Is(err, importer.ErrorUnknownFormat) {
+			templates.AlertBanner.Execute(w, model.BannerModel{
+				SwapTarget:  "beforebegin:#import-csv",
+				Description: err.Error(),
+			})
+		} else {
+			log.Printf("Error importing CSV: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	templates.CsvImportResult.Execute(w, result)
+}
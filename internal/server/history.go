@@ -0,0 +1,307 @@
+package server
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/service"
+	"dumbbell/internal/templates"
+	"dumbbell/internal/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var ErrorInvalidSet = errors.New("Invalid set")
+
+// loggedSet is a set as it is logged on the workout page, or added and edited on the history page. The
+// forms only have the fields the exercise is measured in, the others are 0.
+type loggedSet struct {
+	Rating   dto.SetStatus
+	Type     dto.SetType
+	Weight   float64
+	Reps     int64
+	Seconds  int64
+	Distance float64
+}
+
+// parseLoggedSet reads the rating, type and measurements of a set, a set without a type is a working set.
+func parseLoggedSet(r *http.Request) (loggedSet, error) {
+	set := loggedSet{
+		Rating: dto.SetStatus(r.FormValue("rating")),
+		Type:   dto.SetType(r.FormValue("set-type")),
+	}
+	if set.Rating != dto.SetGood && set.Rating != dto.SetBad {
+		return loggedSet{}, ErrorInvalidSet
+	}
+
+	if set.Type == "" {
+		set.Type = dto.SetWorking
+	}
+	if !set.Type.IsValid() {
+		return loggedSet{}, ErrorInvalidSet
+	}
+
+	var err error
+	if set.Weight, err = parseFormNumber(r, "weight"); err != nil {
+		return loggedSet{}, err
+	}
+	if set.Reps, err = parseFormInt(r, "reps"); err != nil {
+		return loggedSet{}, err
+	}
+	if set.Seconds, err = parseFormInt(r, "seconds"); err != nil {
+		return loggedSet{}, err
+	}
+	if set.Distance, err = parseFormNumber(r, "distance"); err != nil {
+		return loggedSet{}, err
+	}
+
+	return set, nil
+}
+
+// parseFormNumber reads a measurement or target that is only in the form for some exercises, one that is
+// not in the form is 0.
+func parseFormNumber(r *http.Request, name string) (float64, error) {
+	valueString := r.FormValue(name)
+	if valueString == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseFloat(valueString, 64)
+	if err != nil || value < 0 {
+		return 0, ErrorInvalidSet
+	}
+	return value, nil
+}
+
+// parseFormInt is parseFormNumber for whole numbers like reps and seconds.
+func parseFormInt(r *http.Request, name string) (int64, error) {
+	valueString := r.FormValue(name)
+	if valueString == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseInt(valueString, 10, 64)
+	if err != nil || value < 0 {
+		return 0, ErrorInvalidSet
+	}
+	return value, nil
+}
+
+func (s *HttpServer) historyPageHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+
+	page := 1
+	if pageString := r.FormValue("page"); pageString != "" {
+		parsedPage, err := strconv.Atoi(pageString)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page = parsedPage
+	}
+
+	var splitId int64
+	if splitIdString := r.FormValue("split"); splitIdString != "" {
+		parsedSplitId, err := strconv.ParseInt(splitIdString, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		splitId = parsedSplitId
+	}
+
+	viewModel, err := s.WorkoutService.GetHistoryModel(userId, splitId, page)
+	if err != nil {
+		log.Printf("Error historyPageHandler: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	viewModel.Header = s.SessionService.GetHeaderModel(r)
+
+	var templateErr error
+	if s.HtmxService.IsHtmxRequest(r) {
+		templateErr = templates.History.Execute(w, viewModel)
+	} else {
+		templateErr = templates.ExecutePageTemplate(w, "history.html", viewModel)
+	}
+
+	if templateErr != nil {
+		log.Printf("Error in history template: %s", templateErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s *HttpServer) historyWorkoutHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+
+	s.renderHistoryWorkout(w, r, userId, workoutId)
+}
+
+func (s *HttpServer) renderHistoryWorkout(w http.ResponseWriter, r *http.Request, userId int64, workoutId int64) {
+	viewModel, err := s.WorkoutService.GetHistoryWorkoutModel(userId, workoutId)
+	if err != nil {
+		respondAccessError(w, "historyWorkoutHandler", err)
+		return
+	}
+	viewModel.Header = s.SessionService.GetHeaderModel(r)
+
+	var templateErr error
+	if s.HtmxService.IsHtmxRequest(r) {
+		templateErr = templates.HistoryWorkout.Execute(w, viewModel)
+	} else {
+		templateErr = templates.ExecutePageTemplate(w, "historyWorkout.html", viewModel)
+	}
+
+	if templateErr != nil {
+		log.Printf("Error in history workout template: %s", templateErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s *HttpServer) newPastWorkout(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+
+	splitId, err := strconv.ParseInt(r.FormValue("split"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	location := service.GetUserLocation(userId, s.DB)
+	startedAt, startedAtErr := time.ParseInLocation(service.HistoryDateTimeLayout, r.FormValue("started-at"), location)
+	completedAt, completedAtErr := time.ParseInLocation(service.HistoryDateTimeLayout, r.FormValue("completed-at"), location)
+	if startedAtErr != nil || completedAtErr != nil || !completedAt.After(startedAt) || completedAt.After(time.Now()) {
+		log.Printf("Error parsing past workout: started-at=%q completed-at=%q", r.FormValue("started-at"), r.FormValue("completed-at"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	workout, err := dto.CreatePastWorkout(userId, splitId, startedAt, completedAt, s.DB)
+	if err != nil {
+		respondAccessError(w, "newPastWorkout", err)
+		return
+	}
+
+	w.Header().Add("HX-Push-Url", fmt.Sprintf("/history/%d", workout.ID))
+	s.renderHistoryWorkout(w, r, userId, workout.ID)
+}
+
+func (s *HttpServer) deletePastWorkout(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+
+	workout, err := dto.GetWorkout(userId, workoutId, s.DB)
+	if err != nil {
+		respondAccessError(w, "deletePastWorkout", err)
+		return
+	}
+
+	if !workout.CompletedAt.Valid {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err = s.WorkoutService.DeleteWorkout(userId, workout.ID); err != nil {
+		respondAccessError(w, "deletePastWorkout", err)
+		return
+	}
+
+	viewModel, err := s.WorkoutService.GetHistoryModel(userId, 0, 1)
+	if err != nil {
+		log.Printf("Error deletePastWorkout: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	viewModel.Header = s.SessionService.GetHeaderModel(r)
+
+	w.Header().Add("HX-Push-Url", "/history")
+	templates.History.Execute(w, viewModel)
+}
+
+func (s *HttpServer) addPastWorkoutSet(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+
+	exerciseId, err := strconv.ParseInt(r.FormValue("exercise"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	set, err := parseLoggedSet(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if _, err = dto.AddWorkoutSet(userId, workoutId, exerciseId, set.Rating, set.Type, set.Weight, set.Reps, set.Seconds, set.Distance, s.DB); err != nil {
+		respondAccessError(w, "addPastWorkoutSet", err)
+		return
+	}
+	s.updateRecords(userId, exerciseId)
+
+	s.renderHistoryWorkout(w, r, userId, workoutId)
+}
+
+func (s *HttpServer) editPastWorkoutSet(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+	exerciseId := utils.MustParseInt64(r.FormValue("exerciseId"))
+	setNumber := utils.MustParseInt64(r.FormValue("setNumber"))
+
+	viewModel, err := s.WorkoutService.GetHistoryWorkoutModel(userId, workoutId)
+	if err != nil {
+		respondAccessError(w, "editPastWorkoutSet", err)
+		return
+	}
+
+	for _, exercise := range viewModel.Exercises {
+		for _, set := range exercise.Sets {
+			if set.ExerciseID == exerciseId && set.SetNumber == setNumber {
+				templates.HistorySetEdit.Execute(w, set)
+				return
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (s *HttpServer) savePastWorkoutSet(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+	exerciseId := utils.MustParseInt64(r.FormValue("exerciseId"))
+	setNumber := utils.MustParseInt64(r.FormValue("setNumber"))
+
+	set, err := parseLoggedSet(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if _, err = dto.UpdateWorkoutSet(userId, workoutId, exerciseId, setNumber, set.Rating, set.Type, set.Weight, set.Reps, set.Seconds, set.Distance, s.DB); err != nil {
+		respondAccessError(w, "savePastWorkoutSet", err)
+		return
+	}
+	s.updateRecords(userId, exerciseId)
+
+	s.renderHistoryWorkout(w, r, userId, workoutId)
+}
+
+func (s *HttpServer) deletePastWorkoutSet(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+	exerciseId := utils.MustParseInt64(r.FormValue("exerciseId"))
+	setNumber := utils.MustParseInt64(r.FormValue("setNumber"))
+
+	if err := dto.DeleteWorkoutSet(userId, workoutId, exerciseId, setNumber, s.DB); err != nil {
+		respondAccessError(w, "deletePastWorkoutSet", err)
+		return
+	}
+	s.updateRecords(userId, exerciseId)
+
+	s.renderHistoryWorkout(w, r, userId, workoutId)
+}
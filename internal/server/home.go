@@ -8,6 +8,7 @@ import (
 	"dumbbell/internal/templates"
 	"log"
 	"net/http"
+	"strconv"
 )
 
 func (s *HttpServer) homeHandler(w http.ResponseWriter, r *http.Request) {
@@ -28,7 +29,7 @@ func (s *HttpServer) homeHandler(w http.ResponseWriter, r *http.Request) {
 	activeWorkout, err := dto.GetActiveWorkout(userId, s.DB)
 	if err == nil {
 		activeWorkoutData, _ := s.WorkoutService.GetActiveWorkoutData(userId, activeWorkout.ID)
-		workoutMetadata := service.GetWorkoutMetaData(activeWorkout)
+		workoutMetadata := service.GetWorkoutMetaData(activeWorkout, service.GetUserLocation(userId, s.DB))
 		viewModel.ActiveWorkout = activeWorkoutData
 		viewModel.HasActiveWorkout = true
 		viewModel.WorkoutStart = workoutMetadata.WorkoutStart
@@ -57,10 +58,20 @@ func (s *HttpServer) homeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workoutActivity, getWorkoutActivityErr := s.WorkoutService.GetWorkoutActivity(userId)
-	viewModel.WorkoutActivity = workoutActivity
-	if getWorkoutActivityErr != nil {
-		log.Printf("Error getting workout activity: %s", getWorkoutActivityErr.Error())
+	// An unknown or missing year shows the current one.
+	year, _ := strconv.Atoi(r.URL.Query().Get("year"))
+	trainingCalendar, getTrainingCalendarErr := s.WorkoutService.GetTrainingCalendar(userId, year)
+	viewModel.TrainingCalendar = trainingCalendar
+	if getTrainingCalendarErr != nil {
+		log.Printf("Error getting training calendar: %s", getTrainingCalendarErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	muscleBalance, getMuscleBalanceErr := s.WorkoutService.GetMuscleBalanceModel(userId)
+	viewModel.MuscleBalance = muscleBalance
+	if getMuscleBalanceErr != nil {
+		log.Printf("Error getting muscle balance: %s", getMuscleBalanceErr.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"dumbbell/internal/service"
+	"dumbbell/internal/templates"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *HttpServer) muscleReportHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+
+	// An invalid or missing week shows this week.
+	week, _ := time.Parse(service.ReportWeekLayout, r.FormValue("week"))
+	viewModel, err := s.WorkoutService.GetMuscleReportModel(userId, week)
+	if err != nil {
+		log.Printf("Error muscleReportHandler: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	viewModel.Header = s.SessionService.GetHeaderModel(r)
+
+	var templateErr error
+	if s.HtmxService.IsHtmxRequest(r) {
+		templateErr = templates.MuscleReport.Execute(w, viewModel)
+	} else {
+		templateErr = templates.ExecutePageTemplate(w, "muscleReport.html", viewModel)
+	}
+
+	if templateErr != nil {
+		log.Printf("Error in muscle report template: %s", templateErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// saveMuscleTargets saves the range of weekly sets for every muscle group of the taxonomy and shows the
+// report of the week again against the new targets.
+func (s *HttpServer) saveMuscleTargets(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+
+	targets := []dto.MuscleGroupTarget{}
+	for _, muscleGroup := range dto.MuscleGroups {
+		setsFrom, fromErr := strconv.ParseInt(r.FormValue("from-"+string(muscleGroup)), 10, 64)
+		setsTo, toErr := strconv.ParseInt(r.FormValue("to-"+string(muscleGroup)), 10, 64)
+		if fromErr != nil || toErr != nil || setsFrom < 0 || setsFrom > setsTo {
+			templates.AlertBanner.Execute(w, model.BannerModel{
+				SwapTarget:  "beforebegin:#muscle-targets",
+				Description: "The target for " + muscleGroup.Name() + " needs a whole number of sets, with the lowest no more than the highest",
+			})
+			return
+		}
+		targets = append(targets, dto.MuscleGroupTarget{
+			MuscleGroup: muscleGroup,
+			SetsFrom:    setsFrom,
+			SetsTo:      setsTo,
+		})
+	}
+
+	if err := dto.SaveMuscleGroupTargets(userId, targets, s.DB); err != nil {
+		log.Printf("Error saving muscle group targets: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	week, _ := time.Parse(service.ReportWeekLayout, r.FormValue("week"))
+	viewModel, err := s.WorkoutService.GetMuscleReportModel(userId, week)
+	if err != nil {
+		log.Printf("Error saveMuscleTargets: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err = templates.MuscleReportContainer.Execute(w, viewModel); err != nil {
+		log.Printf("Error in muscle report template: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	templates.SuccessBanner.Execute(w, model.BannerModel{
+		SwapTarget:  "beforebegin:#muscle-targets",
+		Description: "Targets saved",
+	})
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"dumbbell/internal/model"
+	"dumbbell/internal/service"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const openApiVersion = "3.0.3"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// openApiSchemas collects the named struct schemas referenced from the document.
+type openApiSchemas map[string]any
+
+func (schemas openApiSchemas) schemaFor(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Pointer:
+		schema := schemas.schemaFor(t.Elem())
+		if _, isRef := schema["$ref"]; isRef {
+			return map[string]any{"allOf": []any{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case t.Kind() == reflect.Slice:
+		return map[string]any{"type": "array", "items": schemas.schemaFor(t.Elem())}
+	case t.Kind() == reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate.
+			schemas[t.Name()] = nil
+			schemas[t.Name()] = schemas.objectSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	}
+	return map[string]any{}
+}
+
+// objectSchema follows encoding/json, embedded structs are flattened and fields are named by their json tag.
+func (schemas openApiSchemas) objectSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			properties[name] = schemas.schemaFor(field.Type)
+			if field.Type.Kind() != reflect.Pointer {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func openApiJsonContent(schema map[string]any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{"schema": schema},
+	}
+}
+
+// openApiDocument describes every route in apiRoutes as an OpenAPI 3 document.
+func (s *HttpServer) openApiDocument() map[string]any {
+	schemas := openApiSchemas{}
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content":     openApiJsonContent(schemas.schemaFor(reflect.TypeOf(model.ApiErrorResponse{}))),
+	}
+
+	paths := map[string]any{}
+	for _, route := range s.apiRoutes() {
+		parameters := []any{}
+		for _, match := range apiPathParameterPattern.FindAllStringSubmatch(route.Path, -1) {
+			parameters = append(parameters, map[string]any{
+				"name":     match[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "integer"},
+			})
+		}
+		for _, query := range route.Query {
+			parameters = append(parameters, map[string]any{
+				"name":        query.Name,
+				"in":          "query",
+				"description": query.Description,
+				"schema":      map[string]any{"type": "integer"},
+			})
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		responses := map[string]any{"default": errorResponse}
+		if route.Response == nil {
+			responses["204"] = map[string]any{"description": http.StatusText(http.StatusNoContent)}
+		} else {
+			responses[strconv.Itoa(status)] = map[string]any{
+				"description": http.StatusText(status),
+				"content":     openApiJsonContent(schemas.schemaFor(reflect.TypeOf(route.Response))),
+			}
+		}
+
+		operation := map[string]any{
+			"summary":    route.Summary,
+			"parameters": parameters,
+			"responses":  responses,
+		}
+		if route.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required":    true,
+				"description": "Sent with the header Content-Type: application/json, other bodies are answered with 415.",
+				"content":     openApiJsonContent(schemas.schemaFor(reflect.TypeOf(route.Request))),
+			}
+		}
+
+		pathItem, ok := paths[route.Path].(map[string]any)
+		if !ok {
+			pathItem = map[string]any{}
+			paths[route.Path] = pathItem
+		}
+		pathItem[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": openApiVersion,
+		"info": map[string]any{
+			"title":   "Dumbbell API",
+			"version": "1",
+		},
+		"servers": []any{map[string]any{"url": ApiPrefix}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"session": map[string]any{
+					"type": "apiKey",
+					"in":   "cookie",
+					"name": service.SESSION_COOKIE_NAME,
+				},
+				"apiToken": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Personal API token created on the settings page, read-only tokens can only be used for GET requests.",
+				},
+			},
+		},
+		"security": []any{map[string]any{"session": []any{}}, map[string]any{"apiToken": []any{}}},
+	}
+}
+
+func (s *HttpServer) openApiHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.openApiDocument())
+}
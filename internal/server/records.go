@@ -0,0 +1,32 @@
+package server
+
+import (
+	"dumbbell/internal/templates"
+	"dumbbell/internal/utils"
+	"log"
+	"net/http"
+)
+
+func (s *HttpServer) personalRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	exerciseId := utils.MustParseInt64(r.FormValue("id"))
+
+	viewModel, err := s.WorkoutService.GetPersonalRecordsModel(userId, exerciseId)
+	if err != nil {
+		respondAccessError(w, "personalRecordsHandler", err)
+		return
+	}
+	viewModel.Header = s.SessionService.GetHeaderModel(r)
+
+	var templateErr error
+	if s.HtmxService.IsHtmxRequest(r) {
+		templateErr = templates.PersonalRecords.Execute(w, viewModel)
+	} else {
+		templateErr = templates.ExecutePageTemplate(w, "personalRecords.html", viewModel)
+	}
+
+	if templateErr != nil {
+		log.Printf("Error in personal records template: %s", templateErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"dumbbell/internal/db"
 	"dumbbell/internal/dto"
 	"dumbbell/internal/environment"
+	"dumbbell/internal/mailer"
 	"dumbbell/internal/mux"
 	"dumbbell/internal/service"
 	"fmt"
@@ -22,6 +23,7 @@ type HttpServer struct {
 	ExerciseService *service.ExerciseService
 	SessionService  *service.SessionService
 	HtmxService     *service.HtmxService
+	ExportService   *service.ExportService
 }
 
 var upgrader = websocket.Upgrader{}
@@ -63,15 +65,21 @@ func (s *HttpServer) handleExerciseImage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	image, err := dto.GetExerciseImage(exerciseId, s.DB)
+	userId, err := s.SessionService.GetUserId(r)
 	if err != nil {
-		log.Print(err.Error())
-		w.WriteHeader(http.StatusBadRequest)
+		log.Printf("Error getting user id: %s", err.Error())
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	image, err := dto.GetExerciseImage(userId, exerciseId, s.DB)
+	if err != nil {
+		respondAccessError(w, "handleExerciseImage", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", fmt.Sprintf("image/%s", image.ContentType))
+	w.WriteHeader(http.StatusOK)
 	w.Write(image.Content)
 }
 
@@ -80,14 +88,27 @@ func NewServer() (*http.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	server := &HttpServer{
+	server := newHttpServer(db, mailer.NewMailer())
+
+	return &http.Server{
+		Addr:    environment.GetServerPort(),
+		Handler: server.routes(),
+	}, nil
+}
+
+func newHttpServer(db *sql.DB, mailer mailer.Mailer) *HttpServer {
+	return &HttpServer{
 		DB:              db,
 		WorkoutService:  service.NewWorkoutService(db),
 		ExerciseService: service.NewExerciseService(db),
-		SessionService:  service.NewSessionService(db),
+		SessionService:  service.NewSessionService(db, mailer),
 		HtmxService:     service.NewHtmxService(),
+		ExportService:   service.NewExportService(db),
 	}
+}
 
+// routes registers the pages, the htmx endpoints and the API of the server.
+func (server *HttpServer) routes() http.Handler {
 	handler := mux.NewHttpMux("")
 
 	fs := http.FileServer(http.Dir("./public"))
@@ -95,8 +116,24 @@ func NewServer() (*http.Server, error) {
 
 	userRouter := handler.Use("/user", server.SessionService.AuthMiddleware)
 	userRouter.HandleFunc("", server.settingsPageHandler)
-
-	handler.HandleFunc("/exercise/image/(?P<id>[\\d]+)", server.handleExerciseImage)
+	userRouter.PostFunc("/progression", server.saveProgressionSettings)
+	userRouter.PostFunc("/password", server.ChangePassword)
+	userRouter.PostFunc("/timezone", server.saveTimezone)
+	userRouter.PostFunc("/tokens", server.createApiToken)
+	userRouter.DeleteFunc("/tokens/(?P<id>[\\d]+)", server.revokeApiToken)
+	userRouter.GetFunc("/export", server.exportData)
+	userRouter.PostFunc("/import", server.importData)
+	userRouter.PostFunc("/import/csv", server.importCsv)
+
+	handler.Handle("/exercise/image/(?P<id>[\\d]+)", server.SessionService.ApiTokenMiddleware(http.HandlerFunc(server.handleExerciseImage)))
+
+	exerciseRouter := handler.Use("/exercise", server.SessionService.AuthMiddleware)
+	exerciseRouter.GetFunc("/new", server.newLibraryExercise)
+	exerciseRouter.GetFunc("/(?P<id>[\\d]+)", server.exerciseProgressHandler)
+	exerciseRouter.GetFunc("/(?P<id>[\\d]+)/edit", server.editLibraryExercise)
+	exerciseRouter.GetFunc("/(?P<id>[\\d]+)/records", server.personalRecordsHandler)
+	exerciseRouter.PostFunc("/(?P<id>[\\d]+)/save", server.saveLibraryExercise)
+	exerciseRouter.DeleteFunc("/(?P<id>[\\d]+)/delete", server.deleteLibraryExercise)
 
 	workoutRouter := handler.Use("/workout", server.SessionService.AuthMiddleware)
 	workoutRouter.HandleFunc("", server.workoutPageHandler)
@@ -104,6 +141,26 @@ func NewServer() (*http.Server, error) {
 	workoutRouter.DeleteFunc("/abort", server.abortWorkout)
 	workoutRouter.PostFunc("/(?P<workoutId>[\\d]+)/exercise/start", server.startExerciseHandler)
 	workoutRouter.PostFunc("/(?P<workoutId>[\\d]+)/exercise/next", server.nextExerciseHandler)
+	workoutRouter.PostFunc("/(?P<workoutId>[\\d]+)/rest/end", server.endRestHandler)
+	workoutRouter.PostFunc("/(?P<workoutId>[\\d]+)/exercise/skip", server.skipExerciseHandler)
+	workoutRouter.PostFunc("/(?P<workoutId>[\\d]+)/undo", server.undoSetHandler)
+	workoutRouter.PostFunc("/(?P<workoutId>[\\d]+)/finish", server.finishWorkoutHandler)
+	workoutRouter.GetFunc("/(?P<workoutId>[\\d]+)/summary", server.workoutSummaryHandler)
+	workoutRouter.PostFunc("/progression/(?P<id>[\\d]+)/(?P<decision>accept|decline)", server.decideProgressionHandler)
+
+	historyRouter := handler.Use("/history", server.SessionService.AuthMiddleware)
+	historyRouter.GetFunc("", server.historyPageHandler)
+	historyRouter.PostFunc("/new", server.newPastWorkout)
+	historyRouter.GetFunc("/(?P<workoutId>[\\d]+)", server.historyWorkoutHandler)
+	historyRouter.DeleteFunc("/(?P<workoutId>[\\d]+)/delete", server.deletePastWorkout)
+	historyRouter.PostFunc("/(?P<workoutId>[\\d]+)/set/new", server.addPastWorkoutSet)
+	historyRouter.GetFunc("/(?P<workoutId>[\\d]+)/exercise/(?P<exerciseId>[\\d]+)/set/(?P<setNumber>[\\d]+)/edit", server.editPastWorkoutSet)
+	historyRouter.PostFunc("/(?P<workoutId>[\\d]+)/exercise/(?P<exerciseId>[\\d]+)/set/(?P<setNumber>[\\d]+)/save", server.savePastWorkoutSet)
+	historyRouter.DeleteFunc("/(?P<workoutId>[\\d]+)/exercise/(?P<exerciseId>[\\d]+)/set/(?P<setNumber>[\\d]+)/delete", server.deletePastWorkoutSet)
+
+	reportRouter := handler.Use("/report", server.SessionService.AuthMiddleware)
+	reportRouter.GetFunc("", server.muscleReportHandler)
+	reportRouter.PostFunc("/targets", server.saveMuscleTargets)
 
 	settingsRouter := handler.Use("/split", server.SessionService.AuthMiddleware)
 	settingsRouter.GetFunc("/new", server.newSplit)
@@ -112,10 +169,17 @@ func NewServer() (*http.Server, error) {
 	settingsRouter.DeleteFunc("/(?P<splitId>[\\d]+)/delete", server.deleteSplit)
 
 	settingsRouter.GetFunc("/(?P<splitId>[\\d]+)/exercise/new", server.newExercise)
+	settingsRouter.PostFunc("/(?P<splitId>[\\d]+)/exercise/order", server.reorderExercises)
+	settingsRouter.PostFunc("/(?P<splitId>[\\d]+)/exercise/group", server.groupExercises)
+	settingsRouter.DeleteFunc("/(?P<splitId>[\\d]+)/exercise/group/(?P<group>[\\d]+)/delete", server.ungroupExercises)
 	settingsRouter.GetFunc("/(?P<splitId>[\\d]+)/exercise/(?P<id>[\\d]+)/edit", server.editExercise)
 	settingsRouter.PostFunc("/(?P<splitId>[\\d]+)/exercise/(?P<id>[\\d]+)/save", server.saveExercise)
 	settingsRouter.DeleteFunc("/(?P<splitId>[\\d]+)/exercise/(?P<id>[\\d]+)/delete", server.deleteExercise)
 
+	handler.GetFunc(ApiPrefix+"/openapi.json", server.openApiHandler)
+	apiRouter := handler.Use(ApiPrefix, server.apiAuthMiddleware)
+	server.registerApi(apiRouter)
+
 	handler.GetFunc("/login", server.loginPageHandler)
 	handler.PostFunc("/login", server.LoginUser)
 
@@ -123,14 +187,16 @@ func NewServer() (*http.Server, error) {
 	handler.PostFunc("/signup", server.RegisterUser)
 	handler.GetFunc("/logout", server.LogoutUser)
 
+	handler.GetFunc("/forgot-password", server.forgotPasswordPageHandler)
+	handler.PostFunc("/forgot-password", server.RequestPasswordReset)
+	handler.GetFunc("/reset-password", server.resetPasswordPageHandler)
+	handler.PostFunc("/reset-password", server.ResetPassword)
+
 	if environment.GetEnvironment() == environment.Development {
 		handler.HandleFunc("/ws/hotreload", makeHMREndpoint())
 	}
 
 	handler.HandleFunc("/", server.homeHandler)
 
-	return &http.Server{
-		Addr:    environment.GetServerPort(),
-		Handler: handler,
-	}, nil
+	return handler
 }
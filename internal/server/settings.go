@@ -1,13 +1,18 @@
 package server
 
 import (
+	"database/sql"
 	"dumbbell/internal/dto"
 	"dumbbell/internal/model"
+	"dumbbell/internal/service"
 	"dumbbell/internal/templates"
 	"dumbbell/internal/utils"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -23,7 +28,7 @@ func (s *HttpServer) editSplit(w http.ResponseWriter, r *http.Request) {
 
 	split, err := dto.GetSplit(userId, splitId, s.DB)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		respondAccessError(w, "editSplit", err)
 		return
 	}
 
@@ -66,7 +71,7 @@ func (s *HttpServer) saveSplit(w http.ResponseWriter, r *http.Request) {
 		split, err := dto.UpdateSplit(userId, splitId, name, description, s.DB)
 
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			respondAccessError(w, "saveSplit", err)
 			return
 		}
 
@@ -91,33 +96,240 @@ func (s *HttpServer) deleteSplit(w http.ResponseWriter, r *http.Request) {
 	splitId := utils.MustParseInt64(r.FormValue("splitId"))
 
 	if err := dto.DeleteSplit(userId, splitId, s.DB); err != nil {
-		log.Printf("Delete split error: %s", err.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+		respondAccessError(w, "deleteSplit", err)
 		return
 	}
+	s.updateAllRecords(userId)
 
 	w.WriteHeader(http.StatusOK)
 }
 
 func (s *HttpServer) newExercise(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
 	splitId := utils.MustParseInt64(r.FormValue("splitId"))
 
+	if _, err := dto.GetSplit(userId, splitId, s.DB); err != nil {
+		respondAccessError(w, "newExercise", err)
+		return
+	}
+
+	library, err := dto.GetLibraryExercises(userId, s.DB)
+	if err != nil {
+		log.Printf("newExercise Error: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	splitExercises, err := dto.GetAllExercises(splitId, s.DB)
+	if err != nil {
+		log.Printf("newExercise Error: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	inSplit := map[int64]bool{}
+	for _, exercise := range splitExercises {
+		inSplit[exercise.ID] = true
+	}
+
+	options := []model.LibraryExerciseOptionModel{}
+	for _, exercise := range library {
+		if !inSplit[exercise.ID] {
+			options = append(options, model.LibraryExerciseOptionModel{
+				ID:          exercise.ID,
+				Name:        exercise.Name,
+				Measurement: exercise.Measurement,
+			})
+		}
+	}
+
 	w.Header().Add("HX-Reswap", "beforeend")
 	w.Header().Add("HX-Retarget", "main")
 	templates.ExecuteHtmxTemplate(w, "editExercise.html", model.EditExerciseModel{
-		SplitID: splitId,
+		SplitID:      splitId,
+		Measurement:  dto.MeasurementWeightReps,
+		MuscleGroups: toMuscleGroupOptions(nil),
+		RestSeconds:  dto.DefaultRestSeconds,
+		Library:      options,
 	})
 }
 
 func (s *HttpServer) deleteExercise(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	splitId := utils.MustParseInt64(r.FormValue("splitId"))
 	id := utils.MustParseInt64(r.FormValue("id"))
-	if err := dto.DeleteExercise(id, s.DB); err != nil {
-		log.Printf("deleteExercise delete error: %s", err.Error())
+
+	exercise, err := dto.GetExercise(userId, id, s.DB)
+	if err != nil {
+		respondAccessError(w, "deleteExercise", err)
+		return
+	}
+
+	if err = dto.RemoveSplitExercise(userId, splitId, id, s.DB); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrorResourceMismatch
+		}
+		respondAccessError(w, "deleteExercise", err)
+		return
+	}
+
+	libraryExercise, err := s.getLibraryExerciseModel(userId, exercise, false)
+	if err != nil {
+		log.Printf("deleteExercise Error: %s", err.Error())
+		return
+	}
+
+	// A superset left with a single exercise was dissolved.
+	s.respondSplitExerciseRows(w, splitId)
+	templates.LibraryExerciseRow.Execute(w, libraryExercise)
+}
+
+// reorderExercises saves the order the exercises of the split were dragged into, the form lists the
+// exercise IDs in their new order.
+func (s *HttpServer) reorderExercises(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	splitId := utils.MustParseInt64(r.FormValue("splitId"))
+
+	if _, err := dto.GetSplit(userId, splitId, s.DB); err != nil {
+		respondAccessError(w, "reorderExercises", err)
+		return
+	}
+
+	exerciseIds := []int64{}
+	for _, value := range r.Form["exercise"] {
+		exerciseId, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Printf("reorderExercises: invalid exercise id %q", value)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		exerciseIds = append(exerciseIds, exerciseId)
+	}
+
+	if err := dto.ReorderSplitExercises(userId, splitId, exerciseIds, s.DB); err != nil {
+		if errors.Is(err, dto.ErrorExerciseOrderMismatch) {
+			log.Printf("reorderExercises: %s", err.Error())
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		respondAccessError(w, "reorderExercises", err)
+		return
+	}
+
+	// The superset and circuit labels follow the order of the split.
+	s.respondSplitExerciseRows(w, splitId)
+}
+
+// groupExercises makes a superset or circuit of the exercises that are checked in the split table.
+func (s *HttpServer) groupExercises(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	splitId := utils.MustParseInt64(r.FormValue("splitId"))
+
+	if _, err := dto.GetSplit(userId, splitId, s.DB); err != nil {
+		respondAccessError(w, "groupExercises", err)
+		return
+	}
+
+	exerciseIds := []int64{}
+	for _, value := range r.Form["group-exercise"] {
+		exerciseId, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Printf("groupExercises: invalid exercise id %q", value)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		exerciseIds = append(exerciseIds, exerciseId)
+	}
+
+	if _, err := dto.GroupSplitExercises(userId, splitId, exerciseIds, s.DB); err != nil {
+		if errors.Is(err, dto.ErrorExerciseGroupTooSmall) {
+			templates.AlertBanner.Execute(w, model.BannerModel{
+				SwapTarget:  fmt.Sprintf("afterbegin:#split-%d", splitId),
+				Description: err.Error(),
+			})
+			return
+		}
+		respondAccessError(w, "groupExercises", err)
+		return
+	}
+
+	s.respondSplitExerciseRows(w, splitId)
+}
+
+// ungroupExercises splits up a superset or circuit, its exercises are done one at a time again.
+func (s *HttpServer) ungroupExercises(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	splitId := utils.MustParseInt64(r.FormValue("splitId"))
+	groupNumber := utils.MustParseInt64(r.FormValue("group"))
+
+	if _, err := dto.GetSplit(userId, splitId, s.DB); err != nil {
+		respondAccessError(w, "ungroupExercises", err)
+		return
+	}
+
+	if err := dto.UngroupSplitExercises(userId, splitId, groupNumber, s.DB); err != nil {
+		respondAccessError(w, "ungroupExercises", err)
+		return
+	}
+
+	s.respondSplitExerciseRows(w, splitId)
+}
+
+// respondSplitExerciseRows updates every exercise row of the split, grouping an exercise changes the
+// labels of the exercises around it.
+func (s *HttpServer) respondSplitExerciseRows(w http.ResponseWriter, splitId int64) {
+	rows, err := getSplitExerciseRows(splitId, s.DB)
+	if err != nil {
+		log.Printf("respondSplitExerciseRows Error: %s", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	for _, row := range rows {
+		templates.ExecuteHtmxTemplate(w, "saveExercise.html", row)
+	}
+}
+
+func getSplitExerciseRows(splitId int64, db *sql.DB) ([]model.EditExerciseTableRowModel, error) {
+	exercises, err := dto.GetAllExercises(splitId, db)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := service.GetExerciseGroupLabels(exercises)
+	rows := []model.EditExerciseTableRowModel{}
+	for _, exercise := range exercises {
+		row := toEditExerciseTableRowModel(exercise, false)
+		row.Group = labels[exercise.ID]
+		if exercise.GroupNumber != 0 {
+			row.GroupKind = service.GetExerciseGroupKind(len(dto.GroupExercises(exercises, exercise)))
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// readExerciseImage stores the image uploaded with the exercise form, the ID is nil when no image was sent.
+func (s *HttpServer) readExerciseImage(r *http.Request) (*int64, error) {
+	imageReader, imageHeader, err := r.FormFile("image")
+	if err != nil {
+		return nil, nil
+	}
+
+	imageContentType := imageHeader.Header.Get("Content-Type")
+	imageType := strings.Replace(imageContentType, "image/", "", 1)
+	imageContent, err := io.ReadAll(imageReader)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := dto.CreateImage(dto.ImageType(imageType), imageContent, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return &image.ID, nil
 }
 
 func (s *HttpServer) saveExercise(w http.ResponseWriter, r *http.Request) {
@@ -128,74 +340,340 @@ func (s *HttpServer) saveExercise(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userId := s.SessionService.MustGetUserId(w, r)
 	id := utils.MustParseInt64(r.FormValue("id"))
 	splitId := utils.MustParseInt64(r.FormValue("splitId"))
 
-	name := r.FormValue("name")
-	description := r.FormValue("description")
-
-	weightFrom := utils.MustParseFloat64(r.FormValue("weight-from"))
-	weightTo := utils.MustParseFloat64(r.FormValue("weight-to"))
-	repsFrom := utils.MustParseInt64(r.FormValue("reps-from"))
-	repsTo := utils.MustParseInt64(r.FormValue("reps-to"))
-	sets := utils.MustParseInt64(r.FormValue("sets"))
-
-	if err != nil {
-		log.Printf("Error reading image file content: %s", err.Error())
+	if _, err = dto.GetSplit(userId, splitId, s.DB); err != nil {
+		respondAccessError(w, "saveExercise GetSplit", err)
+		return
 	}
 
-	imageReader, imageHeader, err := r.FormFile("image")
-	var imageId *int64
-	if err == nil {
-		imageContentType := imageHeader.Header.Get("Content-Type")
-		imageType := strings.Replace(imageContentType, "image/", "", 1)
-		imageContent, err := io.ReadAll(imageReader)
-		if err != nil {
-			log.Printf("saveExercise error failed to read image data: %s", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
+	if id != 0 {
+		if _, err = dto.GetExercise(userId, id, s.DB); err != nil {
+			respondAccessError(w, "saveExercise GetExercise", err)
 			return
 		}
 
-		image, err := dto.CreateImage(dto.ImageType(imageType), imageContent, s.DB)
-		if err != nil {
-			log.Printf("saveExercise error failed to create image: %s", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
+		if _, err = dto.GetSplitExercise(userId, splitId, id, s.DB); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = ErrorResourceMismatch
+			}
+			respondAccessError(w, "saveExercise", err)
 			return
 		}
-		imageId = &image.ID
-	} else {
-		imageId = nil
+	}
+
+	// Only the targets of the exercise's measurement are in the form, the others are 0.
+	weightFrom, weightFromErr := parseFormNumber(r, "weight-from")
+	weightTo, weightToErr := parseFormNumber(r, "weight-to")
+	repsFrom, repsFromErr := parseFormInt(r, "reps-from")
+	repsTo, repsToErr := parseFormInt(r, "reps-to")
+	secondsFrom, secondsFromErr := parseFormInt(r, "seconds-from")
+	secondsTo, secondsToErr := parseFormInt(r, "seconds-to")
+	distanceFrom, distanceFromErr := parseFormNumber(r, "distance-from")
+	distanceTo, distanceToErr := parseFormNumber(r, "distance-to")
+	if err = errors.Join(weightFromErr, weightToErr, repsFromErr, repsToErr, secondsFromErr, secondsToErr, distanceFromErr, distanceToErr); err != nil {
+		respondExerciseFormError(w, "The targets can not be negative")
+		return
+	}
+
+	sets, setsErr := parseFormInt(r, "sets")
+	if setsErr != nil {
+		respondExerciseFormError(w, "The sets have to be a whole number that is not negative")
+		return
+	}
+	restSeconds, restSecondsErr := parseFormInt(r, "rest-seconds")
+	if restSecondsErr != nil {
+		respondExerciseFormError(w, "The rest has to be a whole number of seconds that is not negative")
+		return
 	}
 
 	isNew := id == 0
+	libraryIsNew := false
 
 	var exercise dto.Exercise
 	if isNew {
-		exercise, err = dto.CreateExercise(splitId, imageId, name, description, weightFrom, weightTo, repsFrom, repsTo, sets, s.DB)
+		// A new exercise in the split is either picked from the library or created in it.
+		if libraryId := r.FormValue("library-exercise"); libraryId != "" {
+			if id, err = strconv.ParseInt(libraryId, 10, 64); err != nil {
+				respondExerciseFormError(w, "Pick an exercise from the library")
+				return
+			}
+			if _, err = dto.GetExercise(userId, id, s.DB); err != nil {
+				respondAccessError(w, "saveExercise GetExercise", err)
+				return
+			}
+		} else {
+			libraryExercise, ok := s.saveLibraryFields(w, r, userId, 0)
+			if !ok {
+				return
+			}
+			id = libraryExercise.ID
+			libraryIsNew = true
+		}
+
+		exercise, err = dto.AddSplitExercise(
+			userId,
+			splitId,
+			id,
+			weightFrom,
+			weightTo,
+			repsFrom,
+			repsTo,
+			secondsFrom,
+			secondsTo,
+			distanceFrom,
+			distanceTo,
+			sets,
+			restSeconds,
+			s.DB,
+		)
+		if errors.Is(err, dto.ErrorExerciseInSplit) {
+			respondExerciseFormError(w, err.Error())
+			return
+		}
 	} else {
-		exercise, err = dto.UpdateExercise(id, name, imageId, description, weightFrom, weightTo, repsFrom, repsTo, sets, s.DB)
+		if _, ok := s.saveLibraryFields(w, r, userId, id); !ok {
+			return
+		}
+
+		exercise, err = dto.UpdateSplitExercise(
+			userId,
+			splitId,
+			id,
+			weightFrom,
+			weightTo,
+			repsFrom,
+			repsTo,
+			secondsFrom,
+			secondsTo,
+			distanceFrom,
+			distanceTo,
+			sets,
+			restSeconds,
+			s.DB,
+		)
 	}
 
 	if err != nil {
 		log.Printf("saveExercise error updating exercise: %s", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.respondSavedExercise(w, userId, exercise, isNew, libraryIsNew)
+}
+
+// saveLibraryFields creates or updates the library exercise from the name, description, muscle groups,
+// measurement and image of the exercise form. The response has been written when it returns false.
+func (s *HttpServer) saveLibraryFields(w http.ResponseWriter, r *http.Request, userId int64, id int64) (dto.Exercise, bool) {
+	name := strings.TrimSpace(r.FormValue("name"))
+	description := r.FormValue("description")
+
+	primary := []dto.MuscleGroup{}
+	secondary := []dto.MuscleGroup{}
+	for _, muscleGroup := range dto.MuscleGroups {
+		switch dto.MuscleRole(r.FormValue("muscle-" + string(muscleGroup))) {
+		case "":
+		case dto.MusclePrimary:
+			primary = append(primary, muscleGroup)
+		case dto.MuscleSecondary:
+			secondary = append(secondary, muscleGroup)
+		default:
+			respondExerciseFormError(w, "Unknown muscle group role")
+			return dto.Exercise{}, false
+		}
+	}
+	muscleGroups := dto.FormatMuscleGroups(primary, secondary)
+
+	measurement := dto.Measurement(r.FormValue("measurement"))
+	if measurement == "" {
+		measurement = dto.MeasurementWeightReps
+	}
+	if !measurement.IsValid() {
+		respondExerciseFormError(w, "Unknown measurement")
+		return dto.Exercise{}, false
 	}
 
-	templates.ExecuteHtmxTemplate(w, "saveExercise.html", model.EditExerciseTableRowModel{
-		ID:          exercise.ID,
-		SplitID:     exercise.SplitID,
-		Name:        exercise.Name,
-		Description: exercise.Description,
-		WeightFrom:  exercise.WeightFrom,
-		WeightTo:    exercise.WeightTo,
-		RepsFrom:    exercise.RepsFrom,
-		RepsTo:      exercise.RepsTo,
-		Sets:        exercise.Sets,
-		ImageSrc:    exercise.GetImageURL(),
-		IsNew:       isNew,
+	imageId, err := s.readExerciseImage(r)
+	if err != nil {
+		log.Printf("saveExercise error failed to store image: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return dto.Exercise{}, false
+	}
+
+	var exercise dto.Exercise
+	if id == 0 {
+		if imageId == nil {
+			image, err := s.ExerciseService.CreatePlaceholderImage()
+			if err != nil {
+				log.Printf("saveExercise error failed to create image: %s", err.Error())
+				w.WriteHeader(http.StatusInternalServerError)
+				return dto.Exercise{}, false
+			}
+			imageId = &image.ID
+		}
+
+		exercise, err = dto.CreateExercise(userId, imageId, name, description, muscleGroups, measurement, s.DB)
+	} else {
+		exercise, err = dto.UpdateExercise(userId, id, name, imageId, description, muscleGroups, measurement, s.DB)
+	}
+
+	if errors.Is(err, dto.ErrorExerciseNameTaken) {
+		respondExerciseFormError(w, err.Error())
+		return dto.Exercise{}, false
+	}
+
+	if err != nil {
+		log.Printf("saveExercise error updating exercise: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return dto.Exercise{}, false
+	}
+
+	if err = dto.SetExerciseMuscleGroups(userId, exercise.ID, primary, secondary, s.DB); err != nil {
+		log.Printf("saveExercise error saving muscle groups: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return dto.Exercise{}, false
+	}
+
+	// The measurement decides which records the exercise has.
+	if id != 0 {
+		s.updateRecords(userId, exercise.ID)
+	}
+
+	return exercise, true
+}
+
+// respondExerciseFormError keeps the exercise drawer open and shows the error in it.
+func respondExerciseFormError(w http.ResponseWriter, description string) {
+	w.Header().Add("HX-Reswap", "none")
+	templates.AlertBanner.Execute(w, model.BannerModel{
+		SwapTarget:  "afterbegin:#edit-exercise-form-content",
+		Description: description,
 	})
 }
 
+// respondSavedExercise updates every row that shows the exercise, the name and image are shared by the
+// library and all the splits the exercise is used in.
+func (s *HttpServer) respondSavedExercise(w http.ResponseWriter, userId int64, exercise dto.Exercise, isNew bool, libraryIsNew bool) {
+	libraryExercise, err := s.getLibraryExerciseModel(userId, exercise, libraryIsNew)
+	if err != nil {
+		log.Printf("respondSavedExercise Error: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if exercise.SplitID != 0 && isNew {
+		templates.ExecuteHtmxTemplate(w, "saveExercise.html", toEditExerciseTableRowModel(exercise, isNew))
+	}
+
+	splits, err := dto.GetExerciseSplits(userId, exercise.ID, s.DB)
+	if err != nil {
+		log.Printf("respondSavedExercise Error: %s", err.Error())
+		return
+	}
+
+	for _, split := range splits {
+		if split.ID == exercise.SplitID && isNew {
+			continue
+		}
+
+		splitRows, err := getSplitExerciseRows(split.ID, s.DB)
+		if err != nil {
+			log.Printf("respondSavedExercise Error: %s", err.Error())
+			return
+		}
+
+		for _, row := range splitRows {
+			if row.ID == exercise.ID {
+				templates.ExecuteHtmxTemplate(w, "saveExercise.html", row)
+			}
+		}
+	}
+
+	templates.LibraryExerciseRow.Execute(w, libraryExercise)
+}
+
+func (s *HttpServer) getLibraryExerciseModel(userId int64, exercise dto.Exercise, isNew bool) (model.LibraryExerciseModel, error) {
+	splits, err := dto.GetExerciseSplits(userId, exercise.ID, s.DB)
+	if err != nil {
+		return model.LibraryExerciseModel{}, err
+	}
+
+	splitNames := []string{}
+	for _, split := range splits {
+		splitNames = append(splitNames, split.Name)
+	}
+
+	return model.LibraryExerciseModel{
+		IsNew:        isNew,
+		ID:           exercise.ID,
+		Name:         exercise.Name,
+		Description:  exercise.Description,
+		MuscleGroups: exercise.MuscleGroups,
+		ImageSrc:     exercise.GetImageURL(),
+		Splits:       splitNames,
+	}, nil
+}
+
+func toEditExerciseTableRowModel(exercise dto.Exercise, isNew bool) model.EditExerciseTableRowModel {
+	return model.EditExerciseTableRowModel{
+		ID:           exercise.ID,
+		SplitID:      exercise.SplitID,
+		Name:         exercise.Name,
+		Description:  exercise.Description,
+		MuscleGroups: exercise.MuscleGroups,
+		Measurement:  exercise.Measurement,
+		Targets:      service.FormatTargets(exercise),
+		Sets:         exercise.Sets,
+		RestSeconds:  exercise.RestSeconds,
+		GroupNumber:  exercise.GroupNumber,
+		ImageSrc:     exercise.GetImageURL(),
+		IsNew:        isNew,
+	}
+}
+
+func toEditExerciseModel(exercise dto.Exercise, muscleGroups []dto.ExerciseMuscleGroup) model.EditExerciseModel {
+	return model.EditExerciseModel{
+		ID:           exercise.ID,
+		SplitID:      exercise.SplitID,
+		Name:         exercise.Name,
+		Description:  exercise.Description,
+		MuscleGroups: toMuscleGroupOptions(muscleGroups),
+		Measurement:  exercise.Measurement,
+		WeightFrom:   exercise.WeightFrom,
+		WeightTo:     exercise.WeightTo,
+		RepsFrom:     exercise.RepsFrom,
+		RepsTo:       exercise.RepsTo,
+		SecondsFrom:  exercise.SecondsFrom,
+		SecondsTo:    exercise.SecondsTo,
+		DistanceFrom: exercise.DistanceFrom,
+		DistanceTo:   exercise.DistanceTo,
+		ImageSrc:     exercise.GetImageURL(),
+		Sets:         exercise.Sets,
+		RestSeconds:  exercise.RestSeconds,
+	}
+}
+
+// toMuscleGroupOptions lists the whole taxonomy with the role the exercise has in each muscle group.
+func toMuscleGroupOptions(muscleGroups []dto.ExerciseMuscleGroup) []model.MuscleGroupOptionModel {
+	roles := map[dto.MuscleGroup]dto.MuscleRole{}
+	for _, muscleGroup := range muscleGroups {
+		roles[muscleGroup.MuscleGroup] = muscleGroup.Role
+	}
+
+	options := []model.MuscleGroupOptionModel{}
+	for _, muscleGroup := range dto.MuscleGroups {
+		options = append(options, model.MuscleGroupOptionModel{
+			MuscleGroup: muscleGroup,
+			Name:        muscleGroup.Name(),
+			Role:        roles[muscleGroup],
+		})
+	}
+	return options
+}
+
 func (s *HttpServer) editExercise(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		log.Printf("editExercise Parse form error: %s", err.Error())
@@ -203,29 +681,125 @@ func (s *HttpServer) editExercise(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userId := s.SessionService.MustGetUserId(w, r)
+	splitId := utils.MustParseInt64(r.FormValue("splitId"))
 	exerciseId := utils.MustParseInt64(r.FormValue("id"))
 
-	exercise, err := dto.GetExercise(exerciseId, s.DB)
+	if _, err := dto.GetExercise(userId, exerciseId, s.DB); err != nil {
+		respondAccessError(w, "editExercise", err)
+		return
+	}
+
+	exercise, err := dto.GetSplitExercise(userId, splitId, exerciseId, s.DB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrorResourceMismatch
+		}
+		respondAccessError(w, "editExercise", err)
+		return
+	}
+
+	muscleGroups, err := dto.GetExerciseMuscleGroups(exercise.ID, s.DB)
 	if err != nil {
-		log.Printf("editExercise GetExercise error: %s", err.Error())
+		log.Printf("editExercise Error: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
+	w.Header().Add("HX-Reswap", "beforeend")
+	w.Header().Add("HX-Retarget", "main")
+	templates.ExecuteHtmxTemplate(w, "editExercise.html", toEditExerciseModel(exercise, muscleGroups))
+}
+
+func (s *HttpServer) newLibraryExercise(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("HX-Reswap", "beforeend")
 	w.Header().Add("HX-Retarget", "main")
 	templates.ExecuteHtmxTemplate(w, "editExercise.html", model.EditExerciseModel{
-		ID:          exercise.ID,
-		SplitID:     exercise.SplitID,
-		Name:        exercise.Name,
-		Description: exercise.Description,
-		WeightFrom:  exercise.WeightFrom,
-		WeightTo:    exercise.WeightTo,
-		RepsFrom:    exercise.RepsFrom,
-		RepsTo:      exercise.RepsTo,
-		ImageSrc:    exercise.GetImageURL(),
-		Sets:        exercise.Sets,
+		Measurement:  dto.MeasurementWeightReps,
+		MuscleGroups: toMuscleGroupOptions(nil),
 	})
 }
 
+func (s *HttpServer) editLibraryExercise(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	exerciseId := utils.MustParseInt64(r.FormValue("id"))
+
+	exercise, err := dto.GetExercise(userId, exerciseId, s.DB)
+	if err != nil {
+		respondAccessError(w, "editLibraryExercise", err)
+		return
+	}
+
+	muscleGroups, err := dto.GetExerciseMuscleGroups(exercise.ID, s.DB)
+	if err != nil {
+		log.Printf("editLibraryExercise Error: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("HX-Reswap", "beforeend")
+	w.Header().Add("HX-Retarget", "main")
+	templates.ExecuteHtmxTemplate(w, "editExercise.html", toEditExerciseModel(exercise, muscleGroups))
+}
+
+func (s *HttpServer) saveLibraryExercise(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Printf("saveLibraryExercise Parse form error: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	userId := s.SessionService.MustGetUserId(w, r)
+	id := utils.MustParseInt64(r.FormValue("id"))
+
+	if id != 0 {
+		if _, err := dto.GetExercise(userId, id, s.DB); err != nil {
+			respondAccessError(w, "saveLibraryExercise", err)
+			return
+		}
+	}
+
+	exercise, ok := s.saveLibraryFields(w, r, userId, id)
+	if !ok {
+		return
+	}
+
+	s.respondSavedExercise(w, userId, exercise, false, id == 0)
+}
+
+// deleteLibraryExercise deletes the exercise with its logged sets and removes it from the splits on the page.
+func (s *HttpServer) deleteLibraryExercise(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	id := utils.MustParseInt64(r.FormValue("id"))
+
+	splits, err := dto.GetExerciseSplits(userId, id, s.DB)
+	if err != nil {
+		log.Printf("deleteLibraryExercise Error: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err = dto.DeleteExercise(userId, id, s.DB); err != nil {
+		respondAccessError(w, "deleteLibraryExercise", err)
+		return
+	}
+
+	template, err := templates.New("DeleteLibraryExerciseResponse").Parse(`
+	{{ range .Splits }}
+	<tr hx-swap-oob="delete:#split-{{ .ID }}-exercise-row-{{ $.ID }}"></tr>
+	{{ end }}
+	`)
+
+	if err != nil {
+		log.Printf("Error in delete library exercise template: %s", err.Error())
+		return
+	}
+	template.Execute(w, struct {
+		ID     int64
+		Splits []dto.Split
+	}{ID: id, Splits: splits})
+}
+
 func (s *HttpServer) settingsPageHandler(w http.ResponseWriter, r *http.Request) {
 	userId := s.SessionService.MustGetUserId(w, r)
 	splits, err := dto.GetSplits(userId, s.DB)
@@ -237,6 +811,7 @@ func (s *HttpServer) settingsPageHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	splitModels := []model.EditWorkoutTableSplitModel{}
+	exerciseSplits := map[int64][]string{}
 	for _, split := range splits {
 		exercises, err := dto.GetAllExercises(split.ID, s.DB)
 		if err != nil {
@@ -244,20 +819,14 @@ func (s *HttpServer) settingsPageHandler(w http.ResponseWriter, r *http.Request)
 			break
 		}
 
-		exerciseModels := []model.EditExerciseTableRowModel{}
 		for _, exercise := range exercises {
-			exerciseModels = append(exerciseModels, model.EditExerciseTableRowModel{
-				ID:          exercise.ID,
-				SplitID:     exercise.SplitID,
-				Name:        exercise.Name,
-				Description: exercise.Description,
-				WeightFrom:  exercise.WeightFrom,
-				WeightTo:    exercise.WeightTo,
-				RepsFrom:    exercise.RepsFrom,
-				RepsTo:      exercise.RepsTo,
-				Sets:        exercise.Sets,
-				ImageSrc:    exercise.GetImageURL(),
-			})
+			exerciseSplits[exercise.ID] = append(exerciseSplits[exercise.ID], split.Name)
+		}
+
+		exerciseModels, err := getSplitExerciseRows(split.ID, s.DB)
+		if err != nil {
+			log.Printf("Error userHandler %s", err.Error())
+			break
 		}
 
 		splitModels = append(splitModels, model.EditWorkoutTableSplitModel{
@@ -268,10 +837,47 @@ func (s *HttpServer) settingsPageHandler(w http.ResponseWriter, r *http.Request)
 		})
 	}
 
+	libraryExercises, err := dto.GetLibraryExercises(userId, s.DB)
+	if err != nil {
+		log.Printf("Error userHandler %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	libraryModels := []model.LibraryExerciseModel{}
+	for _, exercise := range libraryExercises {
+		libraryModels = append(libraryModels, model.LibraryExerciseModel{
+			ID:           exercise.ID,
+			Name:         exercise.Name,
+			Description:  exercise.Description,
+			MuscleGroups: exercise.MuscleGroups,
+			ImageSrc:     exercise.GetImageURL(),
+			Splits:       exerciseSplits[exercise.ID],
+		})
+	}
+
+	user, err := dto.GetUserById(userId, s.DB)
+	if err != nil {
+		log.Printf("Error userHandler %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	apiTokens, err := s.SessionService.GetApiTokenModels(userId)
+	if err != nil {
+		log.Printf("Error userHandler %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	viewModel := model.UserSettingsModel{
-		Title:  "Dumbbell - Settings",
-		Splits: splitModels,
-		Header: s.SessionService.GetHeaderModel(r),
+		Title:           "Dumbbell - Settings",
+		Splits:          splitModels,
+		Library:         libraryModels,
+		AutoProgression: user.AutoProgression,
+		Timezone:        user.Timezone,
+		ApiTokens:       apiTokens,
+		Header:          s.SessionService.GetHeaderModel(r),
 	}
 
 	var templateErr error
@@ -286,3 +892,54 @@ func (s *HttpServer) settingsPageHandler(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
+
+func (s *HttpServer) saveProgressionSettings(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	autoProgression := r.FormValue("auto-progression") == "on"
+
+	if _, err := dto.UpdateUserAutoProgression(userId, autoProgression, s.DB); err != nil {
+		log.Printf("Error saving progression settings: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *HttpServer) createApiToken(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	name := strings.TrimSpace(r.FormValue("name"))
+	readOnly := r.FormValue("read-only") == "on"
+
+	if name == "" {
+		templates.AlertBanner.Execute(w, model.BannerModel{
+			SwapTarget:  "beforebegin:#create-api-token",
+			Description: "Give the token a name",
+		})
+		return
+	}
+
+	apiToken, err := s.SessionService.CreateApiToken(userId, name, readOnly)
+	if err != nil {
+		log.Printf("Error creating api token: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("HX-Trigger", "api-token-created")
+	if err = templates.ApiTokenCreated.Execute(w, apiToken); err != nil {
+		log.Printf("Error in api token template: %s", err.Error())
+	}
+}
+
+func (s *HttpServer) revokeApiToken(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	tokenId := utils.MustParseInt64(r.FormValue("id"))
+
+	if err := dto.DeleteApiToken(userId, tokenId, s.DB); err != nil {
+		respondAccessError(w, "revokeApiToken", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
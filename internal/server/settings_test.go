@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSaveExerciseRejectsInvalidSetsAndRest(t *testing.T) {
+	s, testServer := newTestServer(t)
+	own := createAccessFixture(t, s, "own@example.com")
+	client := loginClient(t, testServer, "own@example.com")
+
+	for _, field := range []string{"sets", "rest-seconds"} {
+		for _, value := range []string{"", "abc", "-1"} {
+			form := exerciseForm("Bench press")
+			form.Set(field, value)
+			if value == "" {
+				form.Del(field)
+			}
+
+			response, err := client.PostForm(testServer.URL+fmt.Sprintf("/split/%d/exercise/%d/save", own.SplitID, own.ExerciseID), form)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(response.Body)
+			response.Body.Close()
+
+			// A missing field is 0, like the targets.
+			if value == "" {
+				if response.StatusCode != http.StatusOK || strings.Contains(string(body), "alert-banner") {
+					t.Errorf("%s missing: got status %d with an alert", field, response.StatusCode)
+				}
+				continue
+			}
+			if response.StatusCode != http.StatusOK || !strings.Contains(string(body), "alert-banner") {
+				t.Errorf("%s=%q: got status %d without an alert", field, value, response.StatusCode)
+			}
+		}
+	}
+
+	exercise, err := client.Get(testServer.URL + fmt.Sprintf("/split/%d/exercise/%d/edit", own.SplitID, own.ExerciseID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise.Body.Close()
+	if exercise.StatusCode != http.StatusOK {
+		t.Errorf("edit after invalid saves: got status %d", exercise.StatusCode)
+	}
+}
+
+func TestAddExerciseRejectsInvalidLibraryExercise(t *testing.T) {
+	s, testServer := newTestServer(t)
+	own := createAccessFixture(t, s, "own@example.com")
+	client := loginClient(t, testServer, "own@example.com")
+
+	form := exerciseForm("")
+	form.Set("library-exercise", "abc")
+	response, err := client.PostForm(testServer.URL+fmt.Sprintf("/split/%d/exercise/0/save", own.SplitID), form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK || !strings.Contains(string(body), "alert-banner") {
+		t.Errorf("library-exercise=abc: got status %d without an alert", response.StatusCode)
+	}
+}
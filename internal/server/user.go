@@ -5,8 +5,10 @@ import (
 	"dumbbell/internal/model"
 	"dumbbell/internal/service"
 	"dumbbell/internal/templates"
+	"dumbbell/internal/utils"
 	"log"
 	"net/http"
+	"strings"
 )
 
 func (s *HttpServer) RegisterUser(w http.ResponseWriter, r *http.Request) {
@@ -38,6 +40,7 @@ func (s *HttpServer) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		Email:    email,
 		Password: password,
 		Remember: true,
+		Timezone: r.FormValue("timezone"),
 	})
 
 	if loginErr != nil {
@@ -58,6 +61,7 @@ func (s *HttpServer) LoginUser(w http.ResponseWriter, r *http.Request) {
 		Email:    email,
 		Password: password,
 		Remember: true,
+		Timezone: r.FormValue("timezone"),
 	})
 	if loginErr != nil {
 		if loginErr == service.InvalidCredentialsError {
@@ -88,12 +92,170 @@ func (s *HttpServer) LogoutUser(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/login", http.StatusFound)
 }
 
+func (s *HttpServer) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	email := r.FormValue("email")
+	if email == "" {
+		log.Printf("Invalid form data")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := s.SessionService.RequestPasswordReset(email); err != nil {
+		log.Printf("Error requesting password reset: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	templates.SuccessBanner.Execute(w, model.BannerModel{
+		SwapTarget:  "afterend:#container h1",
+		Description: "If the email has an account, a reset link is on its way",
+	})
+}
+
 func (s *HttpServer) ResetPassword(w http.ResponseWriter, r *http.Request) {
-	// ...
+	token := r.FormValue("token")
+	password := r.FormValue("password")
+	confirmPassword := r.FormValue("confirm-password")
+
+	if token == "" || password == "" || confirmPassword == "" {
+		log.Printf("Invalid form data")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if password != confirmPassword {
+		templates.AlertBanner.Execute(w, model.BannerModel{
+			SwapTarget:  "afterend:#container h1",
+			Description: "Passwords do not match",
+		})
+		return
+	}
+
+	if err := s.SessionService.ResetPassword(token, password); err != nil {
+		if err == service.InvalidResetTokenError {
+			templates.AlertBanner.Execute(w, model.BannerModel{
+				SwapTarget:  "afterend:#container h1",
+				Description: "The reset link is invalid or has expired",
+			})
+		} else {
+			log.Printf("Error resetting password: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Add("HX-Replace-Url", "/login")
+	http.Redirect(w, r, "/login", http.StatusFound)
 }
 
 func (s *HttpServer) ChangePassword(w http.ResponseWriter, r *http.Request) {
-	// ...
+	userId := s.SessionService.MustGetUserId(w, r)
+	oldPassword := r.FormValue("old-password")
+	newPassword := r.FormValue("new-password")
+	confirmPassword := r.FormValue("confirm-password")
+
+	if oldPassword == "" || newPassword == "" || confirmPassword == "" {
+		log.Printf("Invalid form data")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if newPassword != confirmPassword {
+		templates.AlertBanner.Execute(w, model.BannerModel{
+			SwapTarget:  "beforebegin:#change-password",
+			Description: "Passwords do not match",
+		})
+		return
+	}
+
+	if err := s.SessionService.ChangePassword(userId, oldPassword, newPassword); err != nil {
+		if err == service.InvalidCredentialsError {
+			templates.AlertBanner.Execute(w, model.BannerModel{
+				SwapTarget:  "beforebegin:#change-password",
+				Description: "Current password is incorrect",
+			})
+		} else {
+			log.Printf("Error changing password: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Add("HX-Trigger", "password-changed")
+	templates.SuccessBanner.Execute(w, model.BannerModel{
+		SwapTarget:  "beforebegin:#change-password",
+		Description: "Password changed",
+	})
+}
+
+func (s *HttpServer) saveTimezone(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	timezone := strings.TrimSpace(r.FormValue("timezone"))
+
+	if !utils.IsValidTimezone(timezone) {
+		templates.AlertBanner.Execute(w, model.BannerModel{
+			SwapTarget:  "beforebegin:#change-timezone",
+			Description: "Unknown time zone, use a name like Europe/Stockholm",
+		})
+		return
+	}
+
+	if err := dto.UpdateUserTimezone(userId, timezone, s.DB); err != nil {
+		log.Printf("Error saving timezone: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	templates.SuccessBanner.Execute(w, model.BannerModel{
+		SwapTarget:  "beforebegin:#change-timezone",
+		Description: "Time zone saved",
+	})
+}
+
+func (s *HttpServer) forgotPasswordPageHandler(w http.ResponseWriter, r *http.Request) {
+	if s.SessionService.IsAuthenticated(r) {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	viewModel := model.LoginPageModel{
+		Title:  "Dumbbell - Forgot password",
+		Header: s.SessionService.GetHeaderModel(r),
+	}
+
+	var templateErr error
+	if s.HtmxService.IsHtmxRequest(r) {
+		templateErr = templates.ForgotPassword.Execute(w, viewModel)
+	} else {
+		templateErr = templates.ExecutePageTemplate(w, "forgotPassword.html", viewModel)
+	}
+
+	if templateErr != nil {
+		log.Printf("Forgot password page handler Error: %s", templateErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s *HttpServer) resetPasswordPageHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	viewModel := model.ResetPasswordPageModel{
+		Title:      "Dumbbell - Reset password",
+		Header:     s.SessionService.GetHeaderModel(r),
+		Token:      token,
+		ValidToken: s.SessionService.IsResetTokenValid(token),
+	}
+
+	var templateErr error
+	if s.HtmxService.IsHtmxRequest(r) {
+		templateErr = templates.ResetPassword.Execute(w, viewModel)
+	} else {
+		templateErr = templates.ExecutePageTemplate(w, "resetPassword.html", viewModel)
+	}
+
+	if templateErr != nil {
+		log.Printf("Reset password page handler Error: %s", templateErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
 }
 
 func (s *HttpServer) loginPageHandler(w http.ResponseWriter, r *http.Request) {
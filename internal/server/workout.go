@@ -1,6 +1,7 @@
 package server
 
 import (
+	"database/sql"
 	"dumbbell/internal/dto"
 	"dumbbell/internal/model"
 	"dumbbell/internal/service"
@@ -45,50 +46,42 @@ func (s *HttpServer) startExerciseHandler(w http.ResponseWriter, r *http.Request
 	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
 	exerciseId := utils.MustParseInt64(r.FormValue("exercise"))
 
-	exercise, err := dto.GetExercise(exerciseId, s.DB)
-	if err != nil {
-		log.Printf("Error getting exercise: %s", err.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+	if _, err := dto.GetExercise(userId, exerciseId, s.DB); err != nil {
+		respondAccessError(w, "startExerciseHandler GetExercise", err)
 		return
 	}
 
 	workout, err := dto.GetWorkout(userId, workoutId, s.DB)
 	if err != nil {
-		log.Printf("Error getting workout: %s", err.Error())
-		w.WriteHeader(http.StatusInternalServerError)
+		respondAccessError(w, "startExerciseHandler GetWorkout", err)
+		return
+	}
+
+	if _, err := dto.GetSplitExercise(userId, workout.SplitID, exerciseId, s.DB); err != nil {
+		if err == sql.ErrNoRows {
+			err = ErrorResourceMismatch
+		}
+		respondAccessError(w, "startExerciseHandler GetSplitExercise", err)
 		return
 	}
 
-	_, err = dto.CreateNewSet(workout.ID, exerciseId, s.DB)
+	newSet, err := dto.CreateNewSet(workout.ID, exerciseId, s.DB)
 	if err != nil {
 		log.Printf("Error creating new set: %s", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	sets := []dto.SetStatus{
-		dto.SetCurrent,
-	}
-	for i := 1; i < int(exercise.Sets); i++ {
-		sets = append(sets, dto.SetUncompleted)
+	exercise, err := s.WorkoutService.GetExerciseViewModel(newSet, false)
+	if err != nil {
+		log.Printf("Error getting exercise: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
 	viewModel := map[string]interface{}{
-		"Title": fmt.Sprintf("Dumbbell - %s", exercise.Name),
-		"Exercise": model.ExerciseViewModel{
-			Name:        exercise.Name,
-			WorkoutID:   workout.ID,
-			Description: exercise.Description,
-			ImageSrc:    exercise.GetImageURL(),
-			WeightFrom:  exercise.WeightFrom,
-			WeightTo:    exercise.WeightTo,
-			RepsFrom:    exercise.RepsFrom,
-			RepsTo:      exercise.RepsTo,
-			Sets: model.ExerciseSetsModel{
-				Items: sets,
-				Htmx:  false,
-			},
-		},
+		"Title":    fmt.Sprintf("Dumbbell - %s", exercise.Name),
+		"Exercise": exercise,
 	}
 	templates.StartWorkout.Execute(w, viewModel)
 }
@@ -96,7 +89,13 @@ func (s *HttpServer) startExerciseHandler(w http.ResponseWriter, r *http.Request
 func (s *HttpServer) nextExerciseHandler(w http.ResponseWriter, r *http.Request) {
 	userId := s.SessionService.MustGetUserId(w, r)
 	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
-	rating := r.FormValue("rating")
+
+	set, parseSetErr := parseLoggedSet(r)
+	if parseSetErr != nil {
+		log.Printf("Error parsing logged set: %v", r.Form)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 
 	workout, getWorkoutErr := dto.GetWorkout(userId, workoutId, s.DB)
 	if getWorkoutErr != nil {
@@ -105,7 +104,22 @@ func (s *HttpServer) nextExerciseHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	newSet, createNextSetErr := dto.CreateNextSet(workout.ID, dto.SetStatus(rating), s.DB)
+	var newSet dto.WorkoutSet
+	var createNextSetErr error
+	if r.FormValue("drop-set") == "on" {
+		newSet, createNextSetErr = dto.CreateDropSet(workout.ID, set.Rating, set.Type, set.Weight, set.Reps, set.Seconds, set.Distance, s.DB)
+	} else {
+		newSet, createNextSetErr = dto.CreateNextSet(workout.ID, set.Rating, set.Type, set.Weight, set.Reps, set.Seconds, set.Distance, s.DB)
+	}
+	if createNextSetErr == nil || createNextSetErr == dto.ErrorSetLimitReached {
+		s.updateWorkoutRecords(userId, workout.ID)
+	}
+	s.respondNextSet(w, r, userId, workout, newSet, createNextSetErr)
+}
+
+// respondNextSet moves the workout page on to the set that was started. When the exercise has no sets
+// left the next exercise is picked, or the workout is completed and summed up when all of them are done.
+func (s *HttpServer) respondNextSet(w http.ResponseWriter, r *http.Request, userId int64, workout dto.Workout, newSet dto.WorkoutSet, createNextSetErr error) {
 	if createNextSetErr != nil {
 		if createNextSetErr == dto.ErrorSetLimitReached {
 			pickExerciseData, pickExerciseModelErr := s.WorkoutService.GetPickExerciseModel(userId, workout.ID)
@@ -116,13 +130,7 @@ func (s *HttpServer) nextExerciseHandler(w http.ResponseWriter, r *http.Request)
 			}
 
 			if pickExerciseModelErr == service.ErrorNoExercises {
-				if completeWorkoutErr := dto.CompleteWorkout(workout.ID, s.DB); completeWorkoutErr != nil {
-					log.Printf("Error completing workout: %s", completeWorkoutErr.Error())
-					w.WriteHeader(http.StatusInternalServerError)
-					return
-				}
-
-				http.Redirect(w, r, "/", http.StatusMovedPermanently)
+				s.respondWorkoutCompleted(w, r, userId, workout.ID)
 				return
 			}
 			log.Printf("Error getting pick exercise model: %s", pickExerciseModelErr.Error())
@@ -134,25 +142,253 @@ func (s *HttpServer) nextExerciseHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	completedSets, err := dto.GetCompletedWorkoutSets(workout.ID, newSet.ExerciseID, s.DB)
+	exercise, err := dto.GetWorkoutExercise(workout.ID, newSet.ExerciseID, s.DB)
+	if err != nil {
+		log.Printf("Error getting exercise: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The exercises of a superset or circuit take turns, the whole exercise is swapped for the next one.
+	if exercise.GroupNumber != 0 {
+		exerciseViewModel, err := s.WorkoutService.GetExerciseViewModel(newSet, false)
+		if err != nil {
+			log.Printf("Error getting exercise: %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		templates.StartWorkout.Execute(w, map[string]interface{}{
+			"Title":    fmt.Sprintf("Dumbbell - %s", exerciseViewModel.Name),
+			"Exercise": exerciseViewModel,
+		})
+		return
+	}
+
+	sets, err := s.WorkoutService.GetExerciseSetsModel(newSet, true)
 	if err != nil {
-		log.Fatal(err.Error())
+		log.Printf("Error getting exercise sets: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	templates.NextExercise.Execute(w, sets)
+}
+
+// updateWorkoutRecords rebuilds the personal records of the exercises of the workout after its sets
+// changed, the change stands when it fails.
+func (s *HttpServer) updateWorkoutRecords(userId int64, workoutId int64) {
+	if err := s.WorkoutService.UpdateWorkoutPersonalRecords(userId, workoutId); err != nil {
+		log.Printf("Error updating personal records: %s", err.Error())
+	}
+}
+
+// updateRecords rebuilds the personal records of the exercise after its sets changed, the change stands
+// when it fails.
+func (s *HttpServer) updateRecords(userId int64, exerciseId int64) {
+	if err := s.WorkoutService.UpdatePersonalRecords(userId, exerciseId); err != nil {
+		log.Printf("Error updating personal records: %s", err.Error())
+	}
+}
+
+// updateAllRecords rebuilds every personal record of the user after workouts of several exercises were
+// added or deleted, the change stands when it fails.
+func (s *HttpServer) updateAllRecords(userId int64) {
+	if err := s.WorkoutService.UpdateAllPersonalRecords(userId); err != nil {
+		log.Printf("Error updating personal records: %s", err.Error())
+	}
+}
+
+func (s *HttpServer) skipExerciseHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+
+	workout, err := dto.GetActiveWorkout(userId, s.DB)
+	if err != nil || workout.ID != workoutId {
+		respondAccessError(w, "skipExerciseHandler", sql.ErrNoRows)
+		return
+	}
+
+	// Without an active set there is no exercise to skip.
+	newSet, skipExerciseErr := dto.SkipExercise(workout.ID, s.DB)
+	if skipExerciseErr == sql.ErrNoRows {
+		respondAccessError(w, "skipExerciseHandler", skipExerciseErr)
+		return
+	}
+	s.respondNextSet(w, r, userId, workout, newSet, skipExerciseErr)
+}
+
+func (s *HttpServer) undoSetHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+
+	workout, err := dto.GetActiveWorkout(userId, s.DB)
+	if err != nil || workout.ID != workoutId {
+		respondAccessError(w, "undoSetHandler", sql.ErrNoRows)
+		return
 	}
 
-	sets := []dto.SetStatus{}
-	for _, completedSet := range completedSets {
-		sets = append(sets, completedSet.SetRating)
+	activeWorkoutSet, err := dto.UndoLastSet(workout.ID, s.DB)
+	if err == dto.ErrorNothingToUndo {
+		w.WriteHeader(http.StatusConflict)
+		return
 	}
-	sets = append(sets, newSet.SetRating)
+	if err != nil {
+		log.Printf("Error undoing last set: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.updateWorkoutRecords(userId, workout.ID)
 
-	remaining := int(newSet.Sets) - len(sets)
-	for i := 0; i < remaining; i++ {
-		sets = append(sets, dto.SetUncompleted)
+	exercise, err := s.WorkoutService.GetExerciseViewModel(activeWorkoutSet, false)
+	if err != nil {
+		log.Printf("Error getting exercise: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
-	templates.NextExercise.Execute(w, model.ExerciseSetsModel{
-		Items: sets,
-		Htmx:  true,
+	templates.StartWorkout.Execute(w, map[string]interface{}{
+		"Title":    fmt.Sprintf("Dumbbell - %s", exercise.Name),
+		"Exercise": exercise,
+	})
+}
+
+// finishWorkoutHandler completes the active workout before all of its sets are done.
+func (s *HttpServer) finishWorkoutHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+
+	workout, err := dto.GetActiveWorkout(userId, s.DB)
+	if err != nil || workout.ID != workoutId {
+		respondAccessError(w, "finishWorkoutHandler", sql.ErrNoRows)
+		return
+	}
+
+	if err = s.WorkoutService.CompleteWorkout(userId, workout.ID); err != nil {
+		log.Printf("Error completing workout: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("HX-Replace-Url", fmt.Sprintf("/workout/%d/summary", workout.ID))
+	s.renderWorkoutSummary(w, r, userId, workout.ID)
+}
+
+// respondWorkoutCompleted completes the workout once all of its exercises are done and sums it up, htmx
+// requests get the summary in place and page loads are redirected to it.
+func (s *HttpServer) respondWorkoutCompleted(w http.ResponseWriter, r *http.Request, userId int64, workoutId int64) {
+	if err := s.WorkoutService.CompleteWorkout(userId, workoutId); err != nil {
+		log.Printf("Error completing workout: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	summaryUrl := fmt.Sprintf("/workout/%d/summary", workoutId)
+	if !s.HtmxService.IsHtmxRequest(r) {
+		http.Redirect(w, r, summaryUrl, http.StatusFound)
+		return
+	}
+
+	w.Header().Add("HX-Replace-Url", summaryUrl)
+	s.renderWorkoutSummary(w, r, userId, workoutId)
+}
+
+func (s *HttpServer) workoutSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+
+	s.renderWorkoutSummary(w, r, userId, workoutId)
+}
+
+func (s *HttpServer) renderWorkoutSummary(w http.ResponseWriter, r *http.Request, userId int64, workoutId int64) {
+	viewModel, err := s.WorkoutService.GetWorkoutSummaryModel(userId, workoutId)
+	if err != nil {
+		respondAccessError(w, "workoutSummaryHandler", err)
+		return
+	}
+	viewModel.Header = s.SessionService.GetHeaderModel(r)
+
+	var templateErr error
+	if s.HtmxService.IsHtmxRequest(r) {
+		templateErr = templates.WorkoutSummary.Execute(w, viewModel)
+	} else {
+		templateErr = templates.ExecutePageTemplate(w, "workoutSummary.html", viewModel)
+	}
+
+	if templateErr != nil {
+		log.Printf("Error in workout summary template: %s", templateErr.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s *HttpServer) endRestHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	workoutId := utils.MustParseInt64(r.FormValue("workoutId"))
+
+	workout, err := dto.GetWorkout(userId, workoutId, s.DB)
+	if err != nil {
+		respondAccessError(w, "endRestHandler", err)
+		return
+	}
+
+	// The rest may already have been ended from another tab, the timer is removed either way.
+	activeWorkoutSet, err := dto.EndRest(workout.ID, s.DB)
+	if err == dto.ErrorNotResting {
+		activeWorkoutSet, err = dto.GetActiveWorkoutSet(workout.ID, s.DB)
+	}
+	if err != nil {
+		respondAccessError(w, "endRestHandler", err)
+		return
+	}
+
+	templates.RestTimer.Execute(w, service.GetRestTimerModel(activeWorkoutSet, true))
+}
+
+func (s *HttpServer) decideProgressionHandler(w http.ResponseWriter, r *http.Request) {
+	userId := s.SessionService.MustGetUserId(w, r)
+	progressionId := utils.MustParseInt64(r.FormValue("id"))
+
+	status := dto.ProgressionDeclined
+	if r.FormValue("decision") == "accept" {
+		status = dto.ProgressionAccepted
+	}
+
+	progression, err := dto.DecideProgression(userId, progressionId, status, s.DB)
+	if err != nil {
+		log.Printf("Error deciding progression: %s", err.Error())
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	workout, err := dto.GetWorkout(userId, progression.WorkoutID, s.DB)
+	if err != nil {
+		log.Printf("Error getting progression workout: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var exercise dto.Exercise
+	if status == dto.ProgressionAccepted {
+		exercise, err = dto.UpdateExerciseTargets(workout.SplitID, progression.ExerciseID, progression.WeightFrom, progression.WeightTo, progression.RepsFrom, progression.RepsTo, s.DB)
+	} else {
+		exercise, err = dto.GetSplitExercise(userId, workout.SplitID, progression.ExerciseID, s.DB)
+	}
+	if err != nil {
+		log.Printf("Error updating exercise targets: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	templates.ExerciseTargets.Execute(w, model.ExerciseTargetsModel{
+		Measurement:  exercise.Measurement,
+		WeightFrom:   exercise.WeightFrom,
+		WeightTo:     exercise.WeightTo,
+		RepsFrom:     exercise.RepsFrom,
+		RepsTo:       exercise.RepsTo,
+		SecondsFrom:  exercise.SecondsFrom,
+		SecondsTo:    exercise.SecondsTo,
+		DistanceFrom: exercise.DistanceFrom,
+		DistanceTo:   exercise.DistanceTo,
 	})
 }
 
@@ -165,7 +401,7 @@ func (s *HttpServer) abortWorkout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = dto.DeleteWorkout(userId, activeWorkout.ID, s.DB)
+	err = s.WorkoutService.DeleteWorkout(userId, activeWorkout.ID)
 	if err != nil {
 		log.Printf("Error getting active workout: %s", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -181,47 +417,16 @@ func (s *HttpServer) workoutPageHandler(w http.ResponseWriter, r *http.Request)
 	if activeWorkoutErr == nil {
 		activeWorkoutSet, activeWorkoutSetErr := dto.GetActiveWorkoutSet(activeWorkout.ID, s.DB)
 		if activeWorkoutSetErr == nil {
-			exercise, getExerciseErr := dto.GetExercise(activeWorkoutSet.ExerciseID, s.DB)
+			exercise, getExerciseErr := s.WorkoutService.GetExerciseViewModel(activeWorkoutSet, false)
 			if getExerciseErr != nil {
 				log.Printf("Error getting exercise: %s", getExerciseErr.Error())
 				w.WriteHeader(http.StatusInternalServerError)
 				return
 			}
 
-			completedSets, getCompletedWorkoutsErr := dto.GetCompletedWorkoutSets(activeWorkoutSet.WorkoutID, activeWorkoutSet.ExerciseID, s.DB)
-			if getCompletedWorkoutsErr != nil {
-				log.Printf("Error getting completed workouts: %s", getCompletedWorkoutsErr.Error())
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-
-			sets := []dto.SetStatus{}
-			for _, completedSet := range completedSets {
-				sets = append(sets, completedSet.SetRating)
-			}
-			sets = append(sets, activeWorkoutSet.SetRating)
-
-			remaining := int(activeWorkoutSet.Sets) - len(sets)
-			for i := 0; i < remaining; i++ {
-				sets = append(sets, dto.SetUncompleted)
-			}
-
 			viewModel := map[string]interface{}{
-				"Title": fmt.Sprintf("Dumbbell - %s", exercise.Name),
-				"Exercise": model.ExerciseViewModel{
-					Name:        exercise.Name,
-					WorkoutID:   activeWorkout.ID,
-					Description: exercise.Description,
-					ImageSrc:    exercise.GetImageURL(),
-					WeightFrom:  exercise.WeightFrom,
-					WeightTo:    exercise.WeightTo,
-					RepsFrom:    exercise.RepsFrom,
-					RepsTo:      exercise.RepsTo,
-					Sets: model.ExerciseSetsModel{
-						Items: sets,
-						Htmx:  false,
-					},
-				},
+				"Title":    fmt.Sprintf("Dumbbell - %s", exercise.Name),
+				"Exercise": exercise,
 			}
 
 			var templateErr error
@@ -241,9 +446,7 @@ func (s *HttpServer) workoutPageHandler(w http.ResponseWriter, r *http.Request)
 		pickExerciseData, pickExerciseErr := s.WorkoutService.GetPickExerciseModel(userId, activeWorkout.ID)
 		if pickExerciseErr != nil {
 			if pickExerciseErr == service.ErrorNoExercises {
-				dto.CompleteWorkout(activeWorkout.ID, s.DB)
-				w.Header().Add("HX-Replace-Url", "/")
-				http.Redirect(w, r, "/", http.StatusMovedPermanently)
+				s.respondWorkoutCompleted(w, r, userId, activeWorkout.ID)
 				return
 			}
 
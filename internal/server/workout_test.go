@@ -0,0 +1,69 @@
+package server
+
+import (
+	"dumbbell/internal/dto"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestWorkoutPageCompletesWorkoutWithoutExercisesLeft(t *testing.T) {
+	s, testServer := newTestServer(t)
+	own := createAccessFixture(t, s, "own@example.com")
+	client := loginClient(t, testServer, "own@example.com")
+
+	newEmptyWorkout := func() dto.Workout {
+		split, err := dto.CreateSplit(own.UserID, "Empty", "", s.DB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		workout, err := dto.NewWorkout(split.ID, own.UserID, s.DB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return workout
+	}
+
+	for _, test := range []struct {
+		name   string
+		htmx   bool
+		status int
+		header string
+	}{
+		{"page load", false, http.StatusFound, "Location"},
+		{"htmx", true, http.StatusOK, "HX-Replace-Url"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			workout := newEmptyWorkout()
+
+			request, err := http.NewRequest(http.MethodGet, testServer.URL+"/workout", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.htmx {
+				request.Header.Set("HX-Request", "true")
+			}
+
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+
+			if response.StatusCode != test.status {
+				t.Errorf("got status %d, want %d", response.StatusCode, test.status)
+			}
+			if got, want := response.Header.Get(test.header), fmt.Sprintf("/workout/%d/summary", workout.ID); got != want {
+				t.Errorf("got %s %q, want %q", test.header, got, want)
+			}
+
+			completed, err := dto.GetWorkout(own.UserID, workout.ID, s.DB)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !completed.CompletedAt.Valid {
+				t.Error("the workout was not completed")
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Prefix of every API token so they are easy to recognise, e.g. by secret scanners.
+const ApiTokenPrefix = "dbt_"
+
+const apiTokenTimeLayout = "15:04 2006-01-02"
+
+var InvalidApiTokenError = errors.New("Invalid API token")
+var ReadOnlyApiTokenError = errors.New("API token is read-only")
+
+type apiTokenContextKey struct{}
+
+func (s *SessionService) CreateApiToken(userId int64, name string, readOnly bool) (model.ApiTokenCreatedModel, error) {
+	token, err := generateToken()
+	if err != nil {
+		return model.ApiTokenCreatedModel{}, err
+	}
+	token = ApiTokenPrefix + token
+
+	scope := dto.ApiTokenWrite
+	if readOnly {
+		scope = dto.ApiTokenRead
+	}
+
+	apiToken, err := dto.CreateApiToken(userId, name, hashToken(token), scope, s.DB)
+	if err != nil {
+		return model.ApiTokenCreatedModel{}, err
+	}
+
+	return model.ApiTokenCreatedModel{
+		Token:    token,
+		ApiToken: toApiTokenModel(apiToken, GetUserLocation(userId, s.DB)),
+	}, nil
+}
+
+func (s *SessionService) GetApiTokenModels(userId int64) ([]model.ApiTokenModel, error) {
+	apiTokens, err := dto.GetApiTokens(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	location := GetUserLocation(userId, s.DB)
+	apiTokenModels := []model.ApiTokenModel{}
+	for _, apiToken := range apiTokens {
+		apiTokenModels = append(apiTokenModels, toApiTokenModel(apiToken, location))
+	}
+
+	return apiTokenModels, nil
+}
+
+func toApiTokenModel(apiToken dto.ApiToken, location *time.Location) model.ApiTokenModel {
+	lastUsedAt := "Never"
+	if apiToken.LastUsedAt.Valid {
+		lastUsedAt = apiToken.LastUsedAt.Time.In(location).Format(apiTokenTimeLayout)
+	}
+
+	return model.ApiTokenModel{
+		ID:         apiToken.ID,
+		Name:       apiToken.Name,
+		ReadOnly:   apiToken.Scope == dto.ApiTokenRead,
+		CreatedAt:  apiToken.CreatedAt.In(location).Format(apiTokenTimeLayout),
+		LastUsedAt: lastUsedAt,
+	}
+}
+
+// AuthenticateApiToken checks the bearer token of the request. The returned request carries the
+// user of the token for GetUserId, it is the request itself when no token was sent.
+func (s *SessionService) AuthenticateApiToken(r *http.Request) (*http.Request, error) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return r, nil
+	}
+
+	token, isBearer := strings.CutPrefix(authorization, "Bearer ")
+	if !isBearer || !strings.HasPrefix(token, ApiTokenPrefix) {
+		return r, InvalidApiTokenError
+	}
+
+	apiToken, err := dto.UseApiToken(hashToken(token), s.DB)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return r, InvalidApiTokenError
+		}
+		return r, err
+	}
+
+	if apiToken.Scope == dto.ApiTokenRead && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return r, ReadOnlyApiTokenError
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), apiTokenContextKey{}, apiToken.UserID)), nil
+}
+
+// ApiTokenMiddleware authenticates requests that send a bearer token, requests without one are
+// passed on unchanged so they can still use the session cookie.
+func (s *SessionService) ApiTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, err := s.AuthenticateApiToken(r)
+		if err != nil {
+			switch err {
+			case InvalidApiTokenError:
+				w.WriteHeader(http.StatusUnauthorized)
+			case ReadOnlyApiTokenError:
+				w.WriteHeader(http.StatusForbidden)
+			default:
+				log.Printf("Error authenticating api token: %s", err.Error())
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func apiTokenUserId(r *http.Request) (int64, bool) {
+	userId, ok := r.Context().Value(apiTokenContextKey{}).(int64)
+	return userId, ok
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"time"
+)
+
+// calendarMaxLevel is the darkest shade of a day in the training calendar, days with more workouts share it.
+const calendarMaxLevel = 3
+
+// GetTrainingCalendar lays out a year of training days week by week in the time zone of the user, with the
+// workouts of each day by split and the current and longest weekly streaks. Years without workouts before
+// the first one, and the year 0, are the current year.
+func (s *WorkoutService) GetTrainingCalendar(userId int64, year int) (model.TrainingCalendarModel, error) {
+	location := GetUserLocation(userId, s.DB)
+	now := time.Now().In(location)
+
+	startedAts, err := dto.GetCompletedWorkoutStarts(userId, s.DB)
+	if err != nil {
+		return model.TrainingCalendarModel{}, err
+	}
+
+	firstYear := now.Year()
+	if len(startedAts) > 0 {
+		firstYear = min(firstYear, startedAts[0].In(location).Year())
+	}
+	if year < firstYear || year > now.Year() {
+		year = now.Year()
+	}
+
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+	yearEnd := yearStart.AddDate(1, 0, 0)
+	workouts, err := dto.GetCompletedSplitWorkoutsBetween(userId, yearStart, yearEnd, s.DB)
+	if err != nil {
+		return model.TrainingCalendarModel{}, err
+	}
+
+	currentStreak, longestStreak := weeklyStreaks(startedAts, now)
+	viewModel := model.TrainingCalendarModel{
+		Year:          year,
+		Years:         []model.TrainingCalendarYearModel{},
+		WorkoutCount:  len(workouts),
+		CurrentStreak: currentStreak,
+		LongestStreak: longestStreak,
+		Weeks:         []model.TrainingCalendarWeekModel{},
+	}
+
+	for calendarYear := now.Year(); calendarYear >= firstYear; calendarYear-- {
+		viewModel.Years = append(viewModel.Years, model.TrainingCalendarYearModel{
+			Year:   calendarYear,
+			Active: calendarYear == year,
+		})
+	}
+
+	// The splits of a day are in the order they were first done that day.
+	days := map[string][]model.TrainingCalendarSplitModel{}
+	for _, workout := range workouts {
+		date := workout.StartedAt.In(location).Format(time.DateOnly)
+		splits := days[date]
+
+		found := false
+		for i := range splits {
+			if splits[i].Name == workout.SplitName {
+				splits[i].Count++
+				found = true
+				break
+			}
+		}
+		if !found {
+			splits = append(splits, model.TrainingCalendarSplitModel{Name: workout.SplitName, Count: 1})
+		}
+		days[date] = splits
+	}
+	viewModel.TrainingDays = len(days)
+
+	for weekStart := startOfWeek(yearStart); weekStart.Before(yearEnd); weekStart = weekStart.AddDate(0, 0, 7) {
+		week := model.TrainingCalendarWeekModel{Days: []model.TrainingCalendarDayModel{}}
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			date := day.Format(time.DateOnly)
+			if day.Year() == year && day.Day() == 1 {
+				week.Month = MONTH_NAMES[day.Month()-1]
+			}
+
+			dayModel := model.TrainingCalendarDayModel{
+				Date:   date,
+				InYear: day.Year() == year,
+				Splits: days[date],
+			}
+			for _, split := range dayModel.Splits {
+				dayModel.Count += split.Count
+			}
+			dayModel.Level = min(dayModel.Count, calendarMaxLevel)
+			week.Days = append(week.Days, dayModel)
+		}
+		viewModel.Weeks = append(viewModel.Weeks, week)
+	}
+
+	return viewModel, nil
+}
+
+// weeklyStreaks counts the weeks in a row with at least one workout, weeks start on Monday. The current
+// streak is still going when there has been no workout yet this week but there was one last week.
+func weeklyStreaks(startedAts []time.Time, now time.Time) (int, int) {
+	// The workouts are oldest first so their weeks are too.
+	weeks := []time.Time{}
+	for _, startedAt := range startedAts {
+		week := startOfWeek(startedAt.In(now.Location()))
+		if len(weeks) == 0 || !weeks[len(weeks)-1].Equal(week) {
+			weeks = append(weeks, week)
+		}
+	}
+
+	streak, longest := 0, 0
+	for i, week := range weeks {
+		if i > 0 && weeks[i-1].AddDate(0, 0, 7).Equal(week) {
+			streak++
+		} else {
+			streak = 1
+		}
+		longest = max(longest, streak)
+	}
+
+	thisWeek := startOfWeek(now)
+	if len(weeks) == 0 {
+		return 0, longest
+	}
+	lastWeek := weeks[len(weeks)-1]
+	if !lastWeek.Equal(thisWeek) && !lastWeek.AddDate(0, 0, 7).Equal(thisWeek) {
+		return 0, longest
+	}
+	return streak, longest
+}
+
+// startOfWeek returns midnight of the Monday in the week of t, in the location of t.
+func startOfWeek(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
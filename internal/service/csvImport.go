@@ -0,0 +1,338 @@
+package service
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/importer"
+	"dumbbell/internal/model"
+	"dumbbell/public"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// Name of the split for workouts that have no name in the CSV.
+const DefaultImportSplitName = "Imported workout"
+
+type importSplit struct {
+	split     dto.Split
+	isNew     bool
+	exercises map[string]*importExercise
+	// The exercises in the order they were found, new exercises are created in this order.
+	ordered []*importExercise
+}
+
+// importExercise is an exercise with its targets in a split, isNew is set when it is not part of the split yet.
+type importExercise struct {
+	exercise dto.Exercise
+	isNew    bool
+}
+
+// importPlan maps the workouts of a CSV onto the user's splits and exercises, creating the ones
+// that are missing only when the plan is carried out.
+type importPlan struct {
+	splits   map[string]*importSplit
+	ordered  []*importSplit
+	workouts []importWorkout
+	// The user's library by name, exercises that still have to be created have no ID.
+	library map[string]*dto.Exercise
+}
+
+type importWorkout struct {
+	workout importer.Workout
+	split   *importSplit
+}
+
+func importKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (s *ExportService) newImportPlan(userId int64) (*importPlan, error) {
+	splits, err := dto.GetSplits(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	libraryExercises, err := dto.GetLibraryExercises(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &importPlan{splits: map[string]*importSplit{}, library: map[string]*dto.Exercise{}}
+	for i := range libraryExercises {
+		plan.library[importKey(libraryExercises[i].Name)] = &libraryExercises[i]
+	}
+
+	for _, split := range splits {
+		exercises, err := dto.GetAllExercises(split.ID, s.DB)
+		if err != nil {
+			return nil, err
+		}
+
+		planSplit := &importSplit{split: split, exercises: map[string]*importExercise{}}
+		for _, exercise := range exercises {
+			planExercise := &importExercise{exercise: exercise}
+			planSplit.exercises[importKey(exercise.Name)] = planExercise
+			planSplit.ordered = append(planSplit.ordered, planExercise)
+		}
+
+		plan.ordered = append(plan.ordered, planSplit)
+		if _, ok := plan.splits[importKey(split.Name)]; !ok {
+			plan.splits[importKey(split.Name)] = planSplit
+		}
+	}
+
+	return plan, nil
+}
+
+// splitFor picks the split with the name of the workout, otherwise the split that has the most of its
+// exercises, otherwise a new split named after the workout.
+func (p *importPlan) splitFor(workout importer.Workout) *importSplit {
+	if split, ok := p.splits[importKey(workout.Name)]; ok {
+		return split
+	}
+
+	var best *importSplit
+	bestMatches := 0
+	for _, split := range p.ordered {
+		matches := 0
+		for _, exercise := range workout.Exercises {
+			if _, ok := split.exercises[importKey(exercise.Name)]; ok {
+				matches++
+			}
+		}
+		if matches > bestMatches {
+			best = split
+			bestMatches = matches
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	split := &importSplit{
+		split:     dto.Split{Name: workout.Name},
+		isNew:     true,
+		exercises: map[string]*importExercise{},
+	}
+	p.splits[importKey(workout.Name)] = split
+	p.ordered = append(p.ordered, split)
+	return split
+}
+
+func (p *importPlan) add(workout importer.Workout) {
+	split := p.splitFor(workout)
+	for _, exercise := range workout.Exercises {
+		planExercise, ok := split.exercises[importKey(exercise.Name)]
+		if !ok {
+			planExercise = &importExercise{
+				exercise: dto.Exercise{
+					Name:       exercise.Name,
+					WeightFrom: math.MaxFloat64,
+					RepsFrom:   math.MaxFloat64,
+				},
+				isNew: true,
+			}
+			split.exercises[importKey(exercise.Name)] = planExercise
+			split.ordered = append(split.ordered, planExercise)
+
+			if _, ok := p.library[importKey(exercise.Name)]; !ok {
+				p.library[importKey(exercise.Name)] = &dto.Exercise{Name: exercise.Name}
+			}
+		}
+
+		// New exercises get targets that cover everything that was imported for them.
+		if planExercise.isNew {
+			target := &planExercise.exercise
+			target.Sets = max(target.Sets, int64(len(exercise.Sets)))
+			for _, set := range exercise.Sets {
+				target.WeightFrom = min(target.WeightFrom, set.Weight)
+				target.WeightTo = max(target.WeightTo, set.Weight)
+				target.RepsFrom = min(target.RepsFrom, float64(set.Reps))
+				target.RepsTo = max(target.RepsTo, float64(set.Reps))
+			}
+		}
+	}
+
+	p.workouts = append(p.workouts, importWorkout{workout: workout, split: split})
+}
+
+// importRows gives the new splits of the plan and the exercises missing from the splits negative IDs and
+// returns them to be created with the workouts, exercises that are not in the library yet get the placeholder
+// image.
+func (p *importPlan) importRows() dto.ImportedRows {
+	rows := dto.ImportedRows{Images: map[int64]dto.Image{}}
+	nextId := int64(0)
+	newId := func() int64 {
+		nextId--
+		return nextId
+	}
+
+	for _, split := range p.ordered {
+		if split.isNew {
+			split.split.ID = newId()
+			split.split.Description = "Imported"
+			rows.Splits = append(rows.Splits, split.split)
+		}
+
+		for _, exercise := range split.ordered {
+			if !exercise.isNew {
+				continue
+			}
+
+			library := p.library[importKey(exercise.exercise.Name)]
+			if library.ID == 0 {
+				library.ID = newId()
+				library.Measurement = dto.MeasurementWeightReps
+				rows.Images[library.ID] = dto.Image{ContentType: dto.ImageType("png"), Content: public.PlaceholderImage}
+			}
+
+			target := &exercise.exercise
+			target.ID = library.ID
+			target.SplitID = split.split.ID
+			target.Name = library.Name
+			target.Measurement = library.Measurement
+			target.RestSeconds = dto.DefaultRestSeconds
+			rows.Exercises = append(rows.Exercises, *target)
+		}
+	}
+
+	return rows
+}
+
+// ImportCSV imports the workouts of a Strong or Hevy CSV export, times without a zone are read in location.
+// Workouts that start in the same minute as one of the user's workouts are skipped as duplicates and rows
+// that can not be read are reported without stopping the import. With dryRun nothing is written.
+func (s *ExportService) ImportCSV(userId int64, reader io.Reader, location *time.Location, dryRun bool) (model.CsvImportModel, error) {
+	format, workouts, rowErrors, err := importer.Parse(reader, location)
+	if err != nil {
+		return model.CsvImportModel{}, err
+	}
+
+	startTimes, err := dto.GetWorkoutStartTimes(userId, s.DB)
+	if err != nil {
+		return model.CsvImportModel{}, err
+	}
+	existing := map[time.Time]bool{}
+	for _, startedAt := range startTimes {
+		existing[startedAt.UTC().Truncate(time.Minute)] = true
+	}
+
+	plan, err := s.newImportPlan(userId)
+	if err != nil {
+		return model.CsvImportModel{}, err
+	}
+
+	result := model.CsvImportModel{
+		Format:       string(format),
+		DryRun:       dryRun,
+		Workouts:     []model.CsvImportWorkoutModel{},
+		NewSplits:    []string{},
+		NewExercises: []string{},
+		Errors:       []model.CsvImportErrorModel{},
+	}
+
+	for _, workout := range workouts {
+		if workout.Name == "" {
+			workout.Name = DefaultImportSplitName
+		}
+
+		workoutModel := model.CsvImportWorkoutModel{
+			Date:          workout.StartedAt.Format("15:04 2006-01-02"),
+			Name:          workout.Name,
+			ExerciseCount: len(workout.Exercises),
+			SetCount:      workout.SetCount(),
+		}
+
+		startedAt := workout.StartedAt.UTC().Truncate(time.Minute)
+		if existing[startedAt] {
+			workoutModel.Duplicate = true
+			result.DuplicateCount++
+		} else {
+			existing[startedAt] = true
+			plan.add(workout)
+
+			split := plan.workouts[len(plan.workouts)-1].split
+			workoutModel.SplitName = split.split.Name
+			workoutModel.NewSplit = split.isNew
+		}
+
+		result.Workouts = append(result.Workouts, workoutModel)
+	}
+
+	for _, split := range plan.ordered {
+		if split.isNew {
+			result.NewSplits = append(result.NewSplits, split.split.Name)
+		}
+		for _, exercise := range split.ordered {
+			if exercise.isNew {
+				result.NewExercises = append(result.NewExercises, fmt.Sprintf("%s (%s)", exercise.exercise.Name, split.split.Name))
+			}
+		}
+	}
+
+	for _, rowError := range rowErrors {
+		result.Errors = append(result.Errors, model.CsvImportErrorModel{
+			Line:    rowError.Line,
+			Message: rowError.Message,
+		})
+	}
+
+	if dryRun || len(plan.workouts) == 0 {
+		return result, nil
+	}
+
+	rows := plan.importRows()
+	importedWorkouts := []dto.ImportedWorkout{}
+	for _, planWorkout := range plan.workouts {
+		workout := planWorkout.workout
+		importedWorkout := dto.ImportedWorkout{
+			Workout: dto.Workout{
+				SplitID:     planWorkout.split.split.ID,
+				StartedAt:   workout.StartedAt,
+				CompletedAt: pointerToNullTime(&workout.CompletedAt),
+			},
+			Sets: []dto.WorkoutSet{},
+		}
+
+		for _, exercise := range workout.Exercises {
+			target := planWorkout.split.exercises[importKey(exercise.Name)].exercise
+			for i, set := range exercise.Sets {
+				setType := dto.SetWorking
+				if set.Type != "" {
+					setType = dto.SetType(set.Type)
+				}
+
+				importedWorkout.Sets = append(importedWorkout.Sets, dto.WorkoutSet{
+					SetNumber:   int64(i + 1),
+					ExerciseID:  target.ID,
+					StartedAt:   workout.StartedAt,
+					CompletedAt: pointerToNullTime(&workout.StartedAt),
+					SetRating:   dto.SetGood,
+					SetType:     setType,
+					WeightFrom:  target.WeightFrom,
+					WeightTo:    target.WeightTo,
+					RepsFrom:    target.RepsFrom,
+					RepsTo:      target.RepsTo,
+					Weight:      set.Weight,
+					Reps:        set.Reps,
+				})
+			}
+		}
+
+		importedWorkouts = append(importedWorkouts, importedWorkout)
+	}
+
+	if err = dto.ImportWorkouts(userId, rows, importedWorkouts, s.DB); err != nil {
+		return model.CsvImportModel{}, err
+	}
+	result.ImportedCount = len(importedWorkouts)
+
+	if err = NewWorkoutService(s.DB).UpdateAllPersonalRecords(userId); err != nil {
+		log.Printf("Error updating personal records: %s", err.Error())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"dumbbell/internal/dto"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFailedImportLeavesNoSplitsOrExercises(t *testing.T) {
+	database := newTestDB(t)
+
+	user, err := dto.CreateUser("own@example.com", "password", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startedAt := time.Date(2024, time.March, 4, 18, 0, 0, 0, time.UTC)
+	rows := dto.ImportedRows{
+		Splits: []dto.Split{{ID: -1, Name: "Push"}},
+		Exercises: []dto.Exercise{
+			{ID: -2, SplitID: -1, Name: "Bench press", Measurement: dto.MeasurementWeightReps, Sets: 1},
+		},
+		Images: map[int64]dto.Image{-2: {ContentType: dto.ImageType("png"), Content: []byte{}}},
+	}
+	workouts := []dto.ImportedWorkout{{
+		Workout: dto.Workout{SplitID: -1, StartedAt: startedAt},
+		// The set is of an exercise that is not part of the import.
+		Sets: []dto.WorkoutSet{{SetNumber: 1, ExerciseID: -3, StartedAt: startedAt}},
+	}}
+
+	if err = dto.ImportWorkouts(user.ID, rows, workouts, database); err != dto.ErrorInvalidImport {
+		t.Fatalf("got error %v, want %v", err, dto.ErrorInvalidImport)
+	}
+
+	splits, err := dto.GetSplits(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercises, err := dto.GetLibraryExercises(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(splits) != 0 || len(exercises) != 0 {
+		t.Errorf("got %d splits and %d exercises after the failed import, want none", len(splits), len(exercises))
+	}
+}
+
+const strongImport = `Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps
+2024-03-04 18:00:00,Push,1h,Bench Press,1,100,5
+2024-03-04 18:00:00,Push,1h,Bench Press,2,105,3
+2024-03-04 18:00:00,Push,1h,Dips,1,0,12
+2024-03-06 18:00:00,Pull,1h,Deadlift,1,140,3
+2024-03-06 18:00:00,Pull,1h,Deadlift,2,broken,3
+`
+
+func TestImportCSVDryRunWritesNothing(t *testing.T) {
+	database := newTestDB(t)
+	exportService := NewExportService(database)
+
+	user, err := dto.CreateUser("own@example.com", "password", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dryRun, err := exportService.ImportCSV(user.ID, strings.NewReader(strongImport), time.UTC, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	splits, err := dto.GetSplits(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startTimes, err := dto.GetWorkoutStartTimes(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(splits) != 0 || len(startTimes) != 0 {
+		t.Fatalf("the dry run created %d splits and %d workouts", len(splits), len(startTimes))
+	}
+
+	imported, err := exportService.ImportCSV(user.ID, strings.NewReader(strongImport), time.UTC, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The dry run shows what the import does.
+	if !reflect.DeepEqual(dryRun.NewSplits, imported.NewSplits) || !reflect.DeepEqual(dryRun.NewExercises, imported.NewExercises) ||
+		!reflect.DeepEqual(dryRun.Workouts, imported.Workouts) || !reflect.DeepEqual(dryRun.Errors, imported.Errors) {
+		t.Errorf("got dry run %+v, want the import %+v", dryRun, imported)
+	}
+	if dryRun.ImportedCount != 0 || imported.ImportedCount != 2 {
+		t.Errorf("got %d workouts imported by the dry run and %d by the import, want 0 and 2", dryRun.ImportedCount, imported.ImportedCount)
+	}
+	if want := []string{"Push", "Pull"}; !reflect.DeepEqual(imported.NewSplits, want) {
+		t.Errorf("got new splits %v, want %v", imported.NewSplits, want)
+	}
+	if len(imported.Errors) != 1 || imported.Errors[0].Line != 6 {
+		t.Errorf("got errors %+v, want one on line 6", imported.Errors)
+	}
+
+	splits, err = dto.GetSplits(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercises, err := dto.GetLibraryExercises(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startTimes, err = dto.GetWorkoutStartTimes(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(splits) != 2 || len(exercises) != 3 || len(startTimes) != 2 {
+		t.Errorf("got %d splits, %d exercises and %d workouts, want 2, 3 and 2", len(splits), len(exercises), len(startTimes))
+	}
+}
+
+func TestImportCSVSkipsWorkoutsOfTheSameMinute(t *testing.T) {
+	database := newTestDB(t)
+	exportService := NewExportService(database)
+
+	user, err := dto.CreateUser("own@example.com", "password", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = exportService.ImportCSV(user.ID, strings.NewReader(strongImport), time.UTC, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Push workout again a few seconds later and a new Pull workout.
+	again := `Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps
+2024-03-04 18:00:30,Push,1h,Bench Press,1,100,5
+2024-03-08 18:00:00,Pull,1h,Deadlift,1,145,3
+2024-03-08 18:00:00,Pull,1h,Rows,1,80,8
+`
+	result, err := exportService.ImportCSV(user.ID, strings.NewReader(again), time.UTC, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.DuplicateCount != 1 || result.ImportedCount != 1 {
+		t.Errorf("got %d duplicates and %d imported, want 1 and 1", result.DuplicateCount, result.ImportedCount)
+	}
+	if len(result.Workouts) != 2 || !result.Workouts[0].Duplicate || result.Workouts[1].Duplicate {
+		t.Errorf("got workouts %+v, want the first one as a duplicate", result.Workouts)
+	}
+	if len(result.NewSplits) != 0 || !reflect.DeepEqual(result.NewExercises, []string{"Rows (Pull)"}) {
+		t.Errorf("got new splits %v and exercises %v, want only Rows in Pull", result.NewSplits, result.NewExercises)
+	}
+
+	startTimes, err := dto.GetWorkoutStartTimes(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(startTimes) != 3 {
+		t.Errorf("got %d workouts, want 3", len(startTimes))
+	}
+}
@@ -2,6 +2,12 @@ package service
 
 import (
 	"database/sql"
+	"dumbbell/internal/dto"
+	"dumbbell/internal/utils"
+	"dumbbell/public"
+	"fmt"
+	"strconv"
+	"time"
 )
 
 type ExerciseService struct {
@@ -11,3 +17,64 @@ type ExerciseService struct {
 func NewExerciseService(db *sql.DB) *ExerciseService {
 	return &ExerciseService{DB: db}
 }
+
+// CreatePlaceholderImage stores a copy of the placeholder image, every exercise owns its image
+// and deletes it together with the exercise.
+func (s *ExerciseService) CreatePlaceholderImage() (dto.Image, error) {
+	return createPlaceholderImage(s.DB)
+}
+
+func createPlaceholderImage(db *sql.DB) (dto.Image, error) {
+	return dto.CreateImage(dto.ImageType("png"), public.PlaceholderImage, db)
+}
+
+// FormatTargets describes the targets of an exercise in a split in what it is measured in, like
+// "40 – 50 kg × 8 – 12" or "30s – 01m 00s".
+func FormatTargets(exercise dto.Exercise) string {
+	switch exercise.Measurement {
+	case dto.MeasurementBodyweightReps:
+		return formatRange(formatNumber(exercise.RepsFrom), formatNumber(exercise.RepsTo)) + " reps"
+	case dto.MeasurementDuration:
+		return formatRange(formatSeconds(exercise.SecondsFrom), formatSeconds(exercise.SecondsTo))
+	case dto.MeasurementDistance:
+		return fmt.Sprintf(
+			"%s m in %s",
+			formatRange(formatNumber(exercise.DistanceFrom), formatNumber(exercise.DistanceTo)),
+			formatRange(formatSeconds(exercise.SecondsFrom), formatSeconds(exercise.SecondsTo)),
+		)
+	}
+	return fmt.Sprintf(
+		"%s kg × %s",
+		formatRange(formatNumber(exercise.WeightFrom), formatNumber(exercise.WeightTo)),
+		formatRange(formatNumber(exercise.RepsFrom), formatNumber(exercise.RepsTo)),
+	)
+}
+
+// FormatSetResult describes a logged set in what its exercise is measured in, like "50 × 10", "12 reps",
+// "01m 30s" or "2000 m in 08m 12s".
+func FormatSetResult(measurement dto.Measurement, weight float64, reps int64, seconds int64, distance float64) string {
+	switch measurement {
+	case dto.MeasurementBodyweightReps:
+		return fmt.Sprintf("%d reps", reps)
+	case dto.MeasurementDuration:
+		return formatSeconds(seconds)
+	case dto.MeasurementDistance:
+		return fmt.Sprintf("%s m in %s", formatNumber(distance), formatSeconds(seconds))
+	}
+	return fmt.Sprintf("%s × %d", formatNumber(weight), reps)
+}
+
+func formatRange(from string, to string) string {
+	if from == to {
+		return from
+	}
+	return from + " – " + to
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatSeconds(seconds int64) string {
+	return utils.FmtDuration(time.Duration(seconds) * time.Second)
+}
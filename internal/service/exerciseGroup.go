@@ -0,0 +1,51 @@
+package service
+
+import (
+	"dumbbell/internal/dto"
+	"fmt"
+)
+
+// GetExerciseGroupLabels labels the exercises of a split that are in a superset or circuit, A1 and A2 for
+// the first group in split order, B1, B2 and B3 for the next one and so on. Exercises outside of a group
+// have no label.
+func GetExerciseGroupLabels(exercises []dto.Exercise) map[int64]string {
+	labels := map[int64]string{}
+	letters := map[int64]rune{}
+	members := map[int64]int{}
+
+	for _, exercise := range exercises {
+		if exercise.GroupNumber == 0 {
+			continue
+		}
+
+		letter, ok := letters[exercise.GroupNumber]
+		if !ok {
+			letter = rune('A' + len(letters)%26)
+			letters[exercise.GroupNumber] = letter
+		}
+
+		members[exercise.GroupNumber]++
+		labels[exercise.ID] = fmt.Sprintf("%c%d", letter, members[exercise.GroupNumber])
+	}
+
+	return labels
+}
+
+// GetExerciseGroupKind names a group of exercises, two exercises are a superset and more are a circuit.
+func GetExerciseGroupKind(size int) string {
+	if size > 2 {
+		return "Circuit"
+	}
+	return "Superset"
+}
+
+// GetExerciseGroupTitle describes the place of the exercise in its group, like "Superset A1", or returns
+// an empty string for an exercise outside of a group.
+func GetExerciseGroupTitle(exercises []dto.Exercise, exercise dto.Exercise) string {
+	if exercise.GroupNumber == 0 {
+		return ""
+	}
+
+	group := dto.GroupExercises(exercises, exercise)
+	return fmt.Sprintf("%s %s", GetExerciseGroupKind(len(group)), GetExerciseGroupLabels(exercises)[exercise.ID])
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"dumbbell/internal/utils"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ProgressRange is how far back the progress charts of an exercise go.
+type ProgressRange string
+
+const (
+	ProgressRangeFourWeeks   ProgressRange = "4w"
+	ProgressRangeThreeMonths ProgressRange = "3m"
+	ProgressRangeYear        ProgressRange = "1y"
+	ProgressRangeAll         ProgressRange = "all"
+)
+
+var ProgressRanges = []ProgressRange{ProgressRangeFourWeeks, ProgressRangeThreeMonths, ProgressRangeYear, ProgressRangeAll}
+
+// ParseProgressRange reads a range from a query string, anything unknown is three months.
+func ParseProgressRange(value string) ProgressRange {
+	for _, progressRange := range ProgressRanges {
+		if string(progressRange) == value {
+			return progressRange
+		}
+	}
+	return ProgressRangeThreeMonths
+}
+
+func (r ProgressRange) Name() string {
+	switch r {
+	case ProgressRangeFourWeeks:
+		return "4 weeks"
+	case ProgressRangeThreeMonths:
+		return "3 months"
+	case ProgressRangeYear:
+		return "1 year"
+	}
+	return "All"
+}
+
+// Start returns when the range starts counting back from now, the zero time for all of it.
+func (r ProgressRange) Start(now time.Time) time.Time {
+	switch r {
+	case ProgressRangeFourWeeks:
+		return now.AddDate(0, 0, -28)
+	case ProgressRangeThreeMonths:
+		return now.AddDate(0, -3, 0)
+	case ProgressRangeYear:
+		return now.AddDate(-1, 0, 0)
+	}
+	return time.Time{}
+}
+
+// GetExerciseProgressModel follows an exercise across its workouts in the range: the top set, the best
+// estimated one rep max, the volume and the share of good sets of every session.
+func (s *WorkoutService) GetExerciseProgressModel(userId int64, exerciseId int64, progressRange ProgressRange) (model.ExerciseProgressPageModel, error) {
+	exercise, err := dto.GetExercise(userId, exerciseId, s.DB)
+	if err != nil {
+		return model.ExerciseProgressPageModel{}, err
+	}
+
+	workoutSets, err := dto.GetExerciseHistorySets(userId, exercise.ID, s.DB)
+	if err != nil {
+		return model.ExerciseProgressPageModel{}, err
+	}
+
+	viewModel := model.ExerciseProgressPageModel{
+		Title:      fmt.Sprintf("Dumbbell - %s", exercise.Name),
+		ExerciseID: exercise.ID,
+		Name:       exercise.Name,
+		ImageSrc:   exercise.GetImageURL(),
+		Ranges:     []model.ExerciseProgressRangeModel{},
+		Charts:     []model.ExerciseProgressChartModel{},
+	}
+
+	for _, availableRange := range ProgressRanges {
+		viewModel.Ranges = append(viewModel.Ranges, model.ExerciseProgressRangeModel{
+			Value:  string(availableRange),
+			Name:   availableRange.Name(),
+			Active: availableRange == progressRange,
+		})
+	}
+
+	// The sets of a workout are in a row, the first one tells when the workout was done.
+	sessions := []progressSession{}
+	location := GetUserLocation(userId, s.DB)
+	start := progressRange.Start(time.Now().In(location))
+	for i, workoutSet := range workoutSets {
+		if i > 0 && workoutSets[i-1].WorkoutID == workoutSet.WorkoutID {
+			continue
+		}
+		if workoutSet.StartedAt.Before(start) {
+			continue
+		}
+
+		session := progressSession{date: workoutSet.StartedAt.In(location).Format("2006-01-02")}
+		for _, sessionSet := range workoutSets[i:] {
+			if sessionSet.WorkoutID != workoutSet.WorkoutID {
+				break
+			}
+			session.add(exercise.Measurement, sessionSet)
+		}
+		sessions = append(sessions, session)
+	}
+	viewModel.SessionCount = len(sessions)
+
+	topSetLabel, volumeLabel := progressLabels(exercise.Measurement)
+	viewModel.Charts = append(viewModel.Charts, progressChart("top-set", topSetLabel, sessions, func(session progressSession) float64 {
+		return session.topSet
+	}))
+	if exercise.Measurement.UsesWeight() {
+		viewModel.Charts = append(viewModel.Charts, progressChart("one-rep-max", "Estimated 1RM (kg)", sessions, func(session progressSession) float64 {
+			return session.oneRepMax
+		}))
+	}
+	viewModel.Charts = append(viewModel.Charts, progressChart("volume", volumeLabel, sessions, func(session progressSession) float64 {
+		return math.Round(session.volume*100) / 100
+	}))
+	viewModel.Charts = append(viewModel.Charts, progressChart("good-sets", "Good sets (%)", sessions, func(session progressSession) float64 {
+		if session.good+session.bad == 0 {
+			return 0
+		}
+		return math.Round(utils.PercentOf(session.good, session.good+session.bad))
+	}))
+
+	return viewModel, nil
+}
+
+// progressSession adds up the sets of the exercise in one workout.
+type progressSession struct {
+	date      string
+	topSet    float64
+	oneRepMax float64
+	volume    float64
+	good      int
+	bad       int
+}
+
+// add counts a set in what the exercise is measured in, the top set is the heaviest weight or the most
+// reps, time or distance.
+func (p *progressSession) add(measurement dto.Measurement, workoutSet dto.WorkoutSet) {
+	switch workoutSet.SetRating {
+	case dto.SetGood:
+		p.good++
+	case dto.SetBad:
+		p.bad++
+	}
+
+	switch measurement {
+	case dto.MeasurementBodyweightReps:
+		p.topSet = math.Max(p.topSet, float64(workoutSet.Reps))
+		p.volume += float64(workoutSet.Reps)
+	case dto.MeasurementDuration:
+		p.topSet = math.Max(p.topSet, float64(workoutSet.Seconds))
+		p.volume += float64(workoutSet.Seconds)
+	case dto.MeasurementDistance:
+		p.topSet = math.Max(p.topSet, workoutSet.Distance)
+		p.volume += workoutSet.Distance
+	default:
+		p.topSet = math.Max(p.topSet, workoutSet.Weight)
+		p.oneRepMax = math.Max(p.oneRepMax, EstimatedOneRepMax(workoutSet.Weight, workoutSet.Reps))
+		p.volume += workoutSet.Weight * float64(workoutSet.Reps)
+	}
+}
+
+func progressChart(id string, label string, sessions []progressSession, value func(progressSession) float64) model.ExerciseProgressChartModel {
+	chart := model.ExerciseProgressChartModel{
+		ID:     id,
+		Label:  label,
+		Points: []model.ExerciseProgressPointModel{},
+	}
+	for _, session := range sessions {
+		chart.Points = append(chart.Points, model.ExerciseProgressPointModel{
+			Date:  session.date,
+			Value: value(session),
+		})
+	}
+	return chart
+}
+
+// progressLabels names the top set and volume charts in the unit the exercise is measured in.
+func progressLabels(measurement dto.Measurement) (string, string) {
+	switch measurement {
+	case dto.MeasurementBodyweightReps:
+		return "Top set reps", "Total reps"
+	case dto.MeasurementDuration:
+		return "Longest set (s)", "Total time (s)"
+	case dto.MeasurementDistance:
+		return "Longest distance (m)", "Total distance (m)"
+	}
+	return "Top set weight (kg)", "Volume (kg)"
+}
@@ -0,0 +1,558 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"dumbbell/public"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version of the export document, bumped whenever the format changes in a way older imports can not read.
+const ExportVersion = 2
+
+// Name of the JSON document inside the export zip.
+const ExportDocumentName = "dumbbell.json"
+
+var InvalidExportError = errors.New("The file is not a Dumbbell export")
+
+type ExportService struct {
+	DB *sql.DB
+}
+
+func NewExportService(db *sql.DB) *ExportService {
+	return &ExportService{DB: db}
+}
+
+func exportImagePath(exerciseId int64, imageType dto.ImageType) string {
+	extension, _, _ := strings.Cut(string(imageType), "+")
+	if extension == "" {
+		extension = "bin"
+	}
+	return fmt.Sprintf("images/%d.%s", exerciseId, extension)
+}
+
+func nullTimeToPointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	utc := value.Time.UTC()
+	return &utc
+}
+
+func pointerToNullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *value, Valid: true}
+}
+
+func formatCSVTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+func nullInt64ToPointer(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
+func pointerToNullInt64(value *int64) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *value, Valid: true}
+}
+
+func formatCSVInt(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
+
+func formatCSVFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Export streams a zip with the JSON document, a CSV file per table and the exercise images.
+func (s *ExportService) Export(w io.Writer, userId int64) error {
+	data, err := dto.GetUserData(userId, s.DB)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	document := model.ExportDocument{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Exercises:  []model.ExportExercise{},
+		Splits:     []model.ExportSplit{},
+		Workouts:   []model.ExportWorkout{},
+	}
+
+	primaryMuscleGroups := map[int64][]string{}
+	secondaryMuscleGroups := map[int64][]string{}
+	for _, muscleGroup := range data.MuscleGroups {
+		if muscleGroup.Role == dto.MusclePrimary {
+			primaryMuscleGroups[muscleGroup.ExerciseID] = append(primaryMuscleGroups[muscleGroup.ExerciseID], string(muscleGroup.MuscleGroup))
+		} else {
+			secondaryMuscleGroups[muscleGroup.ExerciseID] = append(secondaryMuscleGroups[muscleGroup.ExerciseID], string(muscleGroup.MuscleGroup))
+		}
+	}
+
+	for _, exercise := range data.Exercises {
+		exportExercise := model.ExportExercise{
+			ID:                    exercise.ID,
+			Name:                  exercise.Name,
+			Description:           exercise.Description,
+			MuscleGroups:          exercise.MuscleGroups,
+			PrimaryMuscleGroups:   primaryMuscleGroups[exercise.ID],
+			SecondaryMuscleGroups: secondaryMuscleGroups[exercise.ID],
+			Measurement:           string(exercise.Measurement),
+		}
+
+		if image, hasImage := data.Images[exercise.ID]; hasImage {
+			exportExercise.Image = exportImagePath(exercise.ID, image.ContentType)
+			exportExercise.ImageType = string(image.ContentType)
+
+			image, err = dto.GetExerciseImage(userId, exercise.ID, s.DB)
+			if err != nil {
+				return err
+			}
+
+			imageWriter, err := createExportFile(archive, exportExercise.Image, document.ExportedAt)
+			if err != nil {
+				return err
+			}
+			if _, err = imageWriter.Write(image.Content); err != nil {
+				return err
+			}
+		}
+
+		document.Exercises = append(document.Exercises, exportExercise)
+	}
+
+	splitIndex := map[int64]int{}
+	for _, split := range data.Splits {
+		splitIndex[split.ID] = len(document.Splits)
+		document.Splits = append(document.Splits, model.ExportSplit{
+			ID:          split.ID,
+			Name:        split.Name,
+			Description: split.Description,
+			Exercises:   []model.ExportSplitExercise{},
+		})
+	}
+
+	for _, exercise := range data.SplitExercises {
+		restSeconds := exercise.RestSeconds
+		split := &document.Splits[splitIndex[exercise.SplitID]]
+		split.Exercises = append(split.Exercises, model.ExportSplitExercise{
+			ExerciseID:   exercise.ID,
+			WeightFrom:   exercise.WeightFrom,
+			WeightTo:     exercise.WeightTo,
+			RepsFrom:     int64(exercise.RepsFrom),
+			RepsTo:       int64(exercise.RepsTo),
+			SecondsFrom:  exercise.SecondsFrom,
+			SecondsTo:    exercise.SecondsTo,
+			DistanceFrom: exercise.DistanceFrom,
+			DistanceTo:   exercise.DistanceTo,
+			Sets:         exercise.Sets,
+			RestSeconds:  &restSeconds,
+			Group:        exercise.GroupNumber,
+		})
+	}
+
+	workoutIndex := map[int64]int{}
+	for _, workout := range data.Workouts {
+		workoutIndex[workout.ID] = len(document.Workouts)
+		document.Workouts = append(document.Workouts, model.ExportWorkout{
+			ID:          workout.ID,
+			SplitID:     workout.SplitID,
+			StartedAt:   workout.StartedAt.UTC(),
+			CompletedAt: nullTimeToPointer(workout.CompletedAt),
+			Sets:        []model.ExportWorkoutSet{},
+		})
+	}
+
+	for _, workoutSet := range data.WorkoutSets {
+		workout := &document.Workouts[workoutIndex[workoutSet.WorkoutID]]
+		workout.Sets = append(workout.Sets, model.ExportWorkoutSet{
+			ExerciseID:  workoutSet.ExerciseID,
+			SetNumber:   workoutSet.SetNumber,
+			Rating:      string(workoutSet.SetRating),
+			Type:        string(workoutSet.SetType),
+			Weight:      workoutSet.Weight,
+			Reps:        workoutSet.Reps,
+			Seconds:     workoutSet.Seconds,
+			Distance:    workoutSet.Distance,
+			WeightFrom:  workoutSet.WeightFrom,
+			WeightTo:    workoutSet.WeightTo,
+			RepsFrom:    int64(workoutSet.RepsFrom),
+			RepsTo:      int64(workoutSet.RepsTo),
+			StartedAt:   workoutSet.StartedAt.UTC(),
+			CompletedAt: nullTimeToPointer(workoutSet.CompletedAt),
+			RestSeconds: nullInt64ToPointer(workoutSet.RestSeconds),
+		})
+	}
+
+	documentWriter, err := createExportFile(archive, ExportDocumentName, document.ExportedAt)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(documentWriter)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(document); err != nil {
+		return err
+	}
+
+	if err = writeExportCSV(archive, document); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func createExportFile(archive *zip.Writer, name string, modified time.Time) (io.Writer, error) {
+	return archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+}
+
+func writeExportCSV(archive *zip.Writer, document model.ExportDocument) error {
+	exerciseNames := map[int64]string{}
+	exerciseRows := [][]string{{"id", "name", "description", "muscle_groups", "measurement", "image"}}
+	for _, exercise := range document.Exercises {
+		exerciseNames[exercise.ID] = exercise.Name
+		exerciseRows = append(exerciseRows, []string{
+			strconv.FormatInt(exercise.ID, 10),
+			exercise.Name,
+			exercise.Description,
+			exercise.MuscleGroups,
+			exercise.Measurement,
+			exercise.Image,
+		})
+	}
+
+	splitNames := map[int64]string{}
+	splitRows := [][]string{{"id", "name", "description"}}
+	splitExerciseRows := [][]string{{"split_id", "exercise_id", "exercise_name", "weight_from", "weight_to", "reps_from", "reps_to", "seconds_from", "seconds_to", "distance_from", "distance_to", "sets", "rest_seconds", "group"}}
+	for _, split := range document.Splits {
+		splitNames[split.ID] = split.Name
+		splitRows = append(splitRows, []string{strconv.FormatInt(split.ID, 10), split.Name, split.Description})
+
+		for _, exercise := range split.Exercises {
+			splitExerciseRows = append(splitExerciseRows, []string{
+				strconv.FormatInt(split.ID, 10),
+				strconv.FormatInt(exercise.ExerciseID, 10),
+				exerciseNames[exercise.ExerciseID],
+				formatCSVFloat(exercise.WeightFrom),
+				formatCSVFloat(exercise.WeightTo),
+				strconv.FormatInt(exercise.RepsFrom, 10),
+				strconv.FormatInt(exercise.RepsTo, 10),
+				strconv.FormatInt(exercise.SecondsFrom, 10),
+				strconv.FormatInt(exercise.SecondsTo, 10),
+				formatCSVFloat(exercise.DistanceFrom),
+				formatCSVFloat(exercise.DistanceTo),
+				strconv.FormatInt(exercise.Sets, 10),
+				formatCSVInt(exercise.RestSeconds),
+				strconv.FormatInt(exercise.Group, 10),
+			})
+		}
+	}
+
+	workoutRows := [][]string{{"id", "split_id", "split_name", "started_at", "completed_at"}}
+	setRows := [][]string{{"workout_id", "exercise_id", "exercise_name", "set_number", "rating", "set_type", "weight", "reps", "seconds", "distance", "weight_from", "weight_to", "reps_from", "reps_to", "rest_seconds", "started_at", "completed_at"}}
+	for _, workout := range document.Workouts {
+		workoutRows = append(workoutRows, []string{
+			strconv.FormatInt(workout.ID, 10),
+			strconv.FormatInt(workout.SplitID, 10),
+			splitNames[workout.SplitID],
+			formatCSVTime(&workout.StartedAt),
+			formatCSVTime(workout.CompletedAt),
+		})
+
+		for _, workoutSet := range workout.Sets {
+			setRows = append(setRows, []string{
+				strconv.FormatInt(workout.ID, 10),
+				strconv.FormatInt(workoutSet.ExerciseID, 10),
+				exerciseNames[workoutSet.ExerciseID],
+				strconv.FormatInt(workoutSet.SetNumber, 10),
+				workoutSet.Rating,
+				workoutSet.Type,
+				formatCSVFloat(workoutSet.Weight),
+				strconv.FormatInt(workoutSet.Reps, 10),
+				strconv.FormatInt(workoutSet.Seconds, 10),
+				formatCSVFloat(workoutSet.Distance),
+				formatCSVFloat(workoutSet.WeightFrom),
+				formatCSVFloat(workoutSet.WeightTo),
+				strconv.FormatInt(workoutSet.RepsFrom, 10),
+				strconv.FormatInt(workoutSet.RepsTo, 10),
+				formatCSVInt(workoutSet.RestSeconds),
+				formatCSVTime(&workoutSet.StartedAt),
+				formatCSVTime(workoutSet.CompletedAt),
+			})
+		}
+	}
+
+	files := []struct {
+		name string
+		rows [][]string
+	}{
+		{"splits.csv", splitRows},
+		{"exercises.csv", exerciseRows},
+		{"split_exercises.csv", splitExerciseRows},
+		{"workouts.csv", workoutRows},
+		{"workout_sets.csv", setRows},
+	}
+
+	for _, file := range files {
+		fileWriter, err := createExportFile(archive, file.name, document.ExportedAt)
+		if err != nil {
+			return err
+		}
+		if err = csv.NewWriter(fileWriter).WriteAll(file.rows); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import adds the splits, exercises and workouts of an export zip, or of its JSON document on its own, to
+// the user. Exercises without an image get the placeholder image and unfinished workouts are skipped.
+func (s *ExportService) Import(userId int64, content []byte) (model.ImportResultModel, error) {
+	var archive *zip.Reader
+	documentContent := content
+	if bytes.HasPrefix(content, []byte("PK")) {
+		zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return model.ImportResultModel{}, InvalidExportError
+		}
+		archive = zipReader
+
+		documentContent, err = readZipFile(archive, ExportDocumentName)
+		if err != nil {
+			return model.ImportResultModel{}, InvalidExportError
+		}
+	}
+
+	document := model.ExportDocument{}
+	if err := json.Unmarshal(documentContent, &document); err != nil {
+		return model.ImportResultModel{}, InvalidExportError
+	}
+	switch document.Version {
+	case ExportVersion:
+	case 1:
+		if err := upgradeExportDocument(documentContent, &document); err != nil {
+			return model.ImportResultModel{}, InvalidExportError
+		}
+	default:
+		return model.ImportResultModel{}, fmt.Errorf("%w: unsupported version %d", InvalidExportError, document.Version)
+	}
+
+	placeholder := public.PlaceholderImage
+	data := dto.UserData{Images: map[int64]dto.Image{}}
+	for _, exercise := range document.Exercises {
+		measurement := dto.MeasurementWeightReps
+		if exercise.Measurement != "" {
+			measurement = dto.Measurement(exercise.Measurement)
+			if !measurement.IsValid() {
+				return model.ImportResultModel{}, fmt.Errorf("%w: unknown measurement %q", InvalidExportError, exercise.Measurement)
+			}
+		}
+
+		if err := importMuscleGroups(&data, exercise.ID, dto.MusclePrimary, exercise.PrimaryMuscleGroups); err != nil {
+			return model.ImportResultModel{}, err
+		}
+		if err := importMuscleGroups(&data, exercise.ID, dto.MuscleSecondary, exercise.SecondaryMuscleGroups); err != nil {
+			return model.ImportResultModel{}, err
+		}
+
+		data.Exercises = append(data.Exercises, dto.Exercise{
+			ID:           exercise.ID,
+			Name:         exercise.Name,
+			Description:  exercise.Description,
+			MuscleGroups: exercise.MuscleGroups,
+			Measurement:  measurement,
+		})
+
+		image := dto.Image{ContentType: dto.ImageType("png"), Content: placeholder}
+		if archive != nil && exercise.Image != "" && exercise.ImageType != "" {
+			if imageContent, err := readZipFile(archive, exercise.Image); err == nil {
+				image = dto.Image{ContentType: dto.ImageType(exercise.ImageType), Content: imageContent}
+			}
+		}
+		data.Images[exercise.ID] = image
+	}
+
+	for _, split := range document.Splits {
+		data.Splits = append(data.Splits, dto.Split{
+			ID:          split.ID,
+			Name:        split.Name,
+			Description: split.Description,
+		})
+
+		for _, exercise := range split.Exercises {
+			restSeconds := int64(dto.DefaultRestSeconds)
+			if exercise.RestSeconds != nil {
+				restSeconds = *exercise.RestSeconds
+			}
+
+			data.SplitExercises = append(data.SplitExercises, dto.Exercise{
+				ID:           exercise.ExerciseID,
+				SplitID:      split.ID,
+				WeightFrom:   exercise.WeightFrom,
+				WeightTo:     exercise.WeightTo,
+				RepsFrom:     float64(exercise.RepsFrom),
+				RepsTo:       float64(exercise.RepsTo),
+				SecondsFrom:  exercise.SecondsFrom,
+				SecondsTo:    exercise.SecondsTo,
+				DistanceFrom: exercise.DistanceFrom,
+				DistanceTo:   exercise.DistanceTo,
+				Sets:         exercise.Sets,
+				RestSeconds:  restSeconds,
+				GroupNumber:  exercise.Group,
+			})
+		}
+	}
+
+	for _, workout := range document.Workouts {
+		if workout.CompletedAt == nil {
+			continue
+		}
+
+		data.Workouts = append(data.Workouts, dto.Workout{
+			ID:          workout.ID,
+			SplitID:     workout.SplitID,
+			StartedAt:   workout.StartedAt,
+			CompletedAt: pointerToNullTime(workout.CompletedAt),
+		})
+
+		for _, workoutSet := range workout.Sets {
+			rating := dto.SetStatus(workoutSet.Rating)
+			if rating != dto.SetGood && rating != dto.SetBad && rating != dto.SetUncompleted && rating != dto.SetCurrent {
+				return model.ImportResultModel{}, fmt.Errorf("%w: unknown rating %q", InvalidExportError, workoutSet.Rating)
+			}
+
+			setType := dto.SetWorking
+			if workoutSet.Type != "" {
+				setType = dto.SetType(workoutSet.Type)
+				if !setType.IsValid() {
+					return model.ImportResultModel{}, fmt.Errorf("%w: unknown set type %q", InvalidExportError, workoutSet.Type)
+				}
+			}
+
+			data.WorkoutSets = append(data.WorkoutSets, dto.WorkoutSet{
+				SetNumber:   workoutSet.SetNumber,
+				WorkoutID:   workout.ID,
+				ExerciseID:  workoutSet.ExerciseID,
+				StartedAt:   workoutSet.StartedAt,
+				CompletedAt: pointerToNullTime(workoutSet.CompletedAt),
+				SetRating:   rating,
+				SetType:     setType,
+				WeightFrom:  workoutSet.WeightFrom,
+				WeightTo:    workoutSet.WeightTo,
+				RepsFrom:    float64(workoutSet.RepsFrom),
+				RepsTo:      float64(workoutSet.RepsTo),
+				Weight:      workoutSet.Weight,
+				Reps:        workoutSet.Reps,
+				Seconds:     workoutSet.Seconds,
+				Distance:    workoutSet.Distance,
+				RestSeconds: pointerToNullInt64(workoutSet.RestSeconds),
+			})
+		}
+	}
+
+	if err := dto.ImportUserData(userId, data, s.DB); err != nil {
+		if err == dto.ErrorInvalidImport {
+			return model.ImportResultModel{}, fmt.Errorf("%w: %s", InvalidExportError, err.Error())
+		}
+		return model.ImportResultModel{}, err
+	}
+
+	// The imported sets may beat records the user already had.
+	if err := NewWorkoutService(s.DB).UpdateAllPersonalRecords(userId); err != nil {
+		log.Printf("Error updating personal records: %s", err.Error())
+	}
+
+	return model.ImportResultModel{
+		Splits:    len(data.Splits),
+		Exercises: len(data.Exercises),
+		Workouts:  len(data.Workouts),
+		Sets:      len(data.WorkoutSets),
+	}, nil
+}
+
+// exportDocumentV1 is how version 1 documents list exercises, only in the splits they are in and with their
+// targets in that split.
+type exportDocumentV1 struct {
+	Splits []struct {
+		Exercises []struct {
+			model.ExportExercise
+			model.ExportSplitExercise
+		} `json:"exercises"`
+	} `json:"splits"`
+}
+
+// upgradeExportDocument moves the exercises of a version 1 document out of its splits into the library.
+func upgradeExportDocument(content []byte, document *model.ExportDocument) error {
+	documentV1 := exportDocumentV1{}
+	if err := json.Unmarshal(content, &documentV1); err != nil {
+		return err
+	}
+
+	listed := map[int64]bool{}
+	for i, split := range documentV1.Splits {
+		for j, exercise := range split.Exercises {
+			document.Splits[i].Exercises[j].ExerciseID = exercise.ID
+			if !listed[exercise.ID] {
+				listed[exercise.ID] = true
+				document.Exercises = append(document.Exercises, exercise.ExportExercise)
+			}
+		}
+	}
+
+	return nil
+}
+
+func readZipFile(archive *zip.Reader, name string) ([]byte, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// importMuscleGroups adds the muscle groups of an exported exercise with the role to the imported data.
+func importMuscleGroups(data *dto.UserData, exerciseId int64, role dto.MuscleRole, muscleGroups []string) error {
+	for _, muscleGroup := range muscleGroups {
+		if !dto.MuscleGroup(muscleGroup).IsValid() {
+			return fmt.Errorf("%w: unknown muscle group %q", InvalidExportError, muscleGroup)
+		}
+		data.MuscleGroups = append(data.MuscleGroups, dto.ExerciseMuscleGroup{
+			ExerciseID:  exerciseId,
+			MuscleGroup: dto.MuscleGroup(muscleGroup),
+			Role:        role,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"dumbbell/internal/dto"
+	"dumbbell/public"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportDocumentGivesExercisesThePlaceholderImage(t *testing.T) {
+	database := newTestDB(t)
+	exportService := NewExportService(database)
+
+	user, err := dto.CreateUser("own@example.com", "password", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A bare JSON document has no images, every exercise gets the placeholder.
+	result, err := exportService.Import(user.ID, []byte(`{
+		"version": 1,
+		"splits": [{"id": 1, "name": "Push", "exercises": [{"id": 2, "name": "Bench press", "sets": 3}]}],
+		"workouts": []
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Splits != 1 || result.Exercises != 1 {
+		t.Fatalf("got %d splits and %d exercises, want 1 and 1", result.Splits, result.Exercises)
+	}
+
+	exercises, err := dto.GetLibraryExercises(user.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exercises) != 1 {
+		t.Fatalf("got %d exercises, want 1", len(exercises))
+	}
+	image, err := dto.GetExerciseImage(user.ID, exercises[0].ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(image.Content, public.PlaceholderImage) {
+		t.Errorf("got an image of %d bytes, want the placeholder of %d bytes", len(image.Content), len(public.PlaceholderImage))
+	}
+}
+
+func TestExportImportsIntoAnotherAccount(t *testing.T) {
+	database := newTestDB(t)
+	exportService := NewExportService(database)
+
+	user, err := dto.CreateUser("own@example.com", "password", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split, err := dto.CreateSplit(user.ID, "Push", "", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createExercise := func(name string) int64 {
+		image, err := createPlaceholderImage(database)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exercise, err := dto.CreateExercise(user.ID, &image.ID, name, "", "", dto.MeasurementWeightReps, database)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exercise.ID
+	}
+	benchPress := createExercise("Bench press")
+	flyes := createExercise("Flyes")
+	// Only in the library, it was never added to a split.
+	createExercise("Curls")
+
+	for _, exerciseId := range []int64{benchPress, flyes} {
+		if _, err = dto.AddSplitExercise(user.ID, split.ID, exerciseId, 20, 40, 8, 12, 0, 0, 0, 0, 3, 90, database); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	startedAt := time.Date(2024, time.March, 4, 18, 0, 0, 0, time.UTC)
+	workout, err := dto.CreatePastWorkout(user.ID, split.ID, startedAt, startedAt.Add(time.Hour), database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, exerciseId := range []int64{benchPress, benchPress, flyes} {
+		if _, err = dto.AddWorkoutSet(user.ID, workout.ID, exerciseId, dto.SetGood, dto.SetWorking, 30, 10, 0, 0, database); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Flyes leave the split but keep their logged sets.
+	if err = dto.RemoveSplitExercise(user.ID, split.ID, flyes, database); err != nil {
+		t.Fatal(err)
+	}
+
+	export := bytes.Buffer{}
+	if err = exportService.Export(&export, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := dto.CreateUser("other@example.com", "password", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := exportService.Import(other.ID, export.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Splits != 1 || result.Exercises != 3 || result.Workouts != 1 || result.Sets != 3 {
+		t.Errorf("got %d splits, %d exercises, %d workouts and %d sets, want 1, 3, 1 and 3", result.Splits, result.Exercises, result.Workouts, result.Sets)
+	}
+
+	exercises, err := dto.GetLibraryExercises(other.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, exercise := range exercises {
+		names = append(names, exercise.Name)
+	}
+	if want := []string{"Bench press", "Curls", "Flyes"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got library %v, want %v", names, want)
+	}
+
+	splits, err := dto.GetSplits(other.ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(splits) != 1 {
+		t.Fatalf("got %d splits, want 1", len(splits))
+	}
+	splitExercises, err := dto.GetAllExercises(splits[0].ID, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(splitExercises) != 1 || splitExercises[0].Name != "Bench press" || splitExercises[0].Sets != 3 {
+		t.Errorf("got split exercises %+v, want only Bench press with 3 sets", splitExercises)
+	}
+}
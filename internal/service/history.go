@@ -0,0 +1,191 @@
+package service
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"dumbbell/internal/utils"
+	"fmt"
+	"time"
+)
+
+const (
+	HistoryPageSize = 20
+	// Layout of datetime-local inputs used when logging a past workout.
+	HistoryDateTimeLayout = "2006-01-02T15:04"
+)
+
+func (s *WorkoutService) GetHistoryModel(userId int64, splitId int64, page int) (model.HistoryPageModel, error) {
+	count, err := dto.CountCompletedWorkouts(userId, splitId, s.DB)
+	if err != nil {
+		return model.HistoryPageModel{}, err
+	}
+
+	pageCount := (count + HistoryPageSize - 1) / HistoryPageSize
+	if pageCount == 0 {
+		pageCount = 1
+	}
+	if page < 1 {
+		page = 1
+	} else if page > pageCount {
+		page = pageCount
+	}
+
+	summaries, err := dto.GetCompletedWorkoutSummaries(userId, splitId, HistoryPageSize, (page-1)*HistoryPageSize, s.DB)
+	if err != nil {
+		return model.HistoryPageModel{}, err
+	}
+
+	splits, err := dto.GetSplits(userId, s.DB)
+	if err != nil {
+		return model.HistoryPageModel{}, err
+	}
+
+	location := GetUserLocation(userId, s.DB)
+	now := time.Now().In(location)
+	viewModel := model.HistoryPageModel{
+		Title:          "Dumbbell - History",
+		NewStartedAt:   now.Add(-time.Hour).Format(HistoryDateTimeLayout),
+		NewCompletedAt: now.Format(HistoryDateTimeLayout),
+		Workouts:       []model.HistoryWorkoutRowModel{},
+		Splits:         []model.HistorySplitFilterModel{},
+		SplitID:        splitId,
+		Page:           page,
+		PageCount:      pageCount,
+	}
+
+	if page > 1 {
+		viewModel.PreviousPage = page - 1
+	}
+	if page < pageCount {
+		viewModel.NextPage = page + 1
+	}
+
+	for _, split := range splits {
+		viewModel.Splits = append(viewModel.Splits, model.HistorySplitFilterModel{
+			ID:       split.ID,
+			Name:     split.Name,
+			Selected: split.ID == splitId,
+		})
+	}
+
+	for _, summary := range summaries {
+		metadata := GetWorkoutMetaData(summary.Workout, location)
+		goodPercentage := 0
+		if summary.GoodCount+summary.BadCount > 0 {
+			goodPercentage = int(utils.PercentOf(int(summary.GoodCount), int(summary.GoodCount+summary.BadCount)))
+		}
+
+		viewModel.Workouts = append(viewModel.Workouts, model.HistoryWorkoutRowModel{
+			ID:             summary.ID,
+			SplitName:      summary.SplitName,
+			Date:           metadata.WorkoutStart,
+			Duration:       completedWorkoutDuration(summary.Workout),
+			SetCount:       int(summary.SetCount),
+			GoodCount:      int(summary.GoodCount),
+			BadCount:       int(summary.BadCount),
+			GoodPercentage: goodPercentage,
+		})
+	}
+
+	return viewModel, nil
+}
+
+func (s *WorkoutService) GetHistoryWorkoutModel(userId int64, workoutId int64) (model.HistoryWorkoutPageModel, error) {
+	workout, err := dto.GetWorkout(userId, workoutId, s.DB)
+	if err != nil {
+		return model.HistoryWorkoutPageModel{}, err
+	}
+
+	split, err := dto.GetSplit(userId, workout.SplitID, s.DB)
+	if err != nil {
+		return model.HistoryWorkoutPageModel{}, err
+	}
+
+	workoutSets, err := dto.GetWorkoutSets(workout.ID, s.DB)
+	if err != nil {
+		return model.HistoryWorkoutPageModel{}, err
+	}
+
+	location := GetUserLocation(userId, s.DB)
+	exercises := []model.HistoryExerciseModel{}
+	exerciseIndex := map[int64]int{}
+	for _, workoutSet := range workoutSets {
+		index, ok := exerciseIndex[workoutSet.ExerciseID]
+		if !ok {
+			exercise, err := dto.GetExercise(userId, workoutSet.ExerciseID, s.DB)
+			if err != nil {
+				return model.HistoryWorkoutPageModel{}, err
+			}
+
+			exercises = append(exercises, model.HistoryExerciseModel{
+				ID:          exercise.ID,
+				Name:        exercise.Name,
+				ImageSrc:    exercise.GetImageURL(),
+				Measurement: exercise.Measurement,
+				Sets:        []model.HistorySetModel{},
+			})
+			index = len(exercises) - 1
+			exerciseIndex[workoutSet.ExerciseID] = index
+		}
+
+		duration := "–"
+		if workoutSet.CompletedAt.Valid {
+			duration = utils.FmtDuration(workoutSet.CompletedAt.Time.Sub(workoutSet.StartedAt))
+		}
+
+		rest := "–"
+		if workoutSet.RestSeconds.Valid {
+			rest = utils.FmtDuration(time.Duration(workoutSet.RestSeconds.Int64) * time.Second)
+		}
+
+		exercises[index].Sets = append(exercises[index].Sets, model.HistorySetModel{
+			WorkoutID:   workoutSet.WorkoutID,
+			ExerciseID:  workoutSet.ExerciseID,
+			SetNumber:   workoutSet.SetNumber,
+			Status:      workoutSet.SetRating,
+			Type:        workoutSet.SetType,
+			Measurement: exercises[index].Measurement,
+			Weight:      workoutSet.Weight,
+			Reps:        workoutSet.Reps,
+			Seconds:     workoutSet.Seconds,
+			Distance:    workoutSet.Distance,
+			StartedAt:   workoutSet.StartedAt.In(location).Format("15:04:05"),
+			Duration:    duration,
+			Rest:        rest,
+		})
+	}
+
+	splitExercises, err := dto.GetAllExercises(workout.SplitID, s.DB)
+	if err != nil {
+		return model.HistoryWorkoutPageModel{}, err
+	}
+
+	options := []model.HistoryExerciseOptionModel{}
+	for _, exercise := range splitExercises {
+		options = append(options, model.HistoryExerciseOptionModel{
+			ID:          exercise.ID,
+			Name:        exercise.Name,
+			Measurement: exercise.Measurement,
+		})
+	}
+
+	metadata := GetWorkoutMetaData(workout, location)
+	return model.HistoryWorkoutPageModel{
+		Title:     fmt.Sprintf("Dumbbell - %s", split.Name),
+		ID:        workout.ID,
+		SplitName: split.Name,
+		Date:      metadata.WorkoutStart,
+		Duration:  completedWorkoutDuration(workout),
+		Exercises: exercises,
+		Options:   options,
+	}, nil
+}
+
+// completedWorkoutDuration measures up until the workout was completed, unlike GetWorkoutMetaData which
+// measures up until now.
+func completedWorkoutDuration(workout dto.Workout) string {
+	if !workout.CompletedAt.Valid {
+		return utils.FmtDuration(time.Since(workout.StartedAt))
+	}
+	return utils.FmtDuration(workout.CompletedAt.Time.Sub(workout.StartedAt))
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	MuscleGroupUnder  = "under"
+	MuscleGroupWithin = "within"
+	MuscleGroupOver   = "over"
+)
+
+// ReportWeekLayout is how the week of the weekly report is passed around, the date of its Monday.
+const ReportWeekLayout = time.DateOnly
+
+// GetMuscleReportModel lays out the working sets and volume per muscle group of the week against the targets
+// of the user. The week is the one of the given date in the time zone of the user, the zero time is this week.
+func (s *WorkoutService) GetMuscleReportModel(userId int64, date time.Time) (model.MuscleReportPageModel, error) {
+	location := GetUserLocation(userId, s.DB)
+	thisWeek := startOfWeek(time.Now().In(location))
+	week := thisWeek
+	if !date.IsZero() {
+		week = startOfWeek(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location))
+	}
+	if week.After(thisWeek) {
+		week = thisWeek
+	}
+
+	muscleGroups, err := s.getMuscleGroupWeek(userId, week)
+	if err != nil {
+		return model.MuscleReportPageModel{}, err
+	}
+
+	hasMuscleGroups, err := dto.HasExerciseMuscleGroups(userId, s.DB)
+	if err != nil {
+		return model.MuscleReportPageModel{}, err
+	}
+
+	lastDay := week.AddDate(0, 0, 6)
+	viewModel := model.MuscleReportPageModel{
+		Title:           "Dumbbell - Weekly report",
+		Week:            week.Format(ReportWeekLayout),
+		WeekName:        fmt.Sprintf("%s – %s", week.Format("2 Jan"), lastDay.Format("2 Jan 2006")),
+		PreviousWeek:    week.AddDate(0, 0, -7).Format(ReportWeekLayout),
+		IsCurrentWeek:   week.Equal(thisWeek),
+		HasMuscleGroups: hasMuscleGroups,
+		MuscleGroups:    muscleGroups,
+	}
+	if !viewModel.IsCurrentWeek {
+		viewModel.NextWeek = week.AddDate(0, 0, 7).Format(ReportWeekLayout)
+	}
+
+	return viewModel, nil
+}
+
+// GetMuscleBalanceModel picks out the muscle groups under and over their targets this week so far.
+func (s *WorkoutService) GetMuscleBalanceModel(userId int64) (model.MuscleBalanceModel, error) {
+	hasMuscleGroups, err := dto.HasExerciseMuscleGroups(userId, s.DB)
+	if err != nil || !hasMuscleGroups {
+		return model.MuscleBalanceModel{}, err
+	}
+
+	week := startOfWeek(time.Now().In(GetUserLocation(userId, s.DB)))
+	muscleGroups, err := s.getMuscleGroupWeek(userId, week)
+	if err != nil {
+		return model.MuscleBalanceModel{}, err
+	}
+
+	viewModel := model.MuscleBalanceModel{
+		HasMuscleGroups: true,
+		Under:           []model.MuscleGroupWeekModel{},
+		Over:            []model.MuscleGroupWeekModel{},
+	}
+	for _, muscleGroup := range muscleGroups {
+		switch muscleGroup.Status {
+		case MuscleGroupUnder:
+			viewModel.Under = append(viewModel.Under, muscleGroup)
+		case MuscleGroupOver:
+			viewModel.Over = append(viewModel.Over, muscleGroup)
+		}
+	}
+
+	return viewModel, nil
+}
+
+// getMuscleGroupWeek adds up every muscle group of the taxonomy in the week starting at week. A set counts
+// fully towards the primary muscle groups of its exercise and half towards the secondary ones.
+func (s *WorkoutService) getMuscleGroupWeek(userId int64, week time.Time) ([]model.MuscleGroupWeekModel, error) {
+	targets, err := dto.GetMuscleGroupTargets(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := dto.GetMuscleGroupVolumes(userId, week, week.AddDate(0, 0, 7), s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := map[dto.MuscleGroup]float64{}
+	volume := map[dto.MuscleGroup]float64{}
+	for _, muscleGroupVolume := range volumes {
+		share := 1.0
+		if muscleGroupVolume.Role == dto.MuscleSecondary {
+			share = 0.5
+		}
+		sets[muscleGroupVolume.MuscleGroup] += float64(muscleGroupVolume.Sets) * share
+		volume[muscleGroupVolume.MuscleGroup] += muscleGroupVolume.Volume * share
+	}
+
+	muscleGroups := []model.MuscleGroupWeekModel{}
+	for _, target := range targets {
+		muscleGroupSets := sets[target.MuscleGroup]
+		muscleGroup := model.MuscleGroupWeekModel{
+			MuscleGroup: target.MuscleGroup,
+			Name:        target.MuscleGroup.Name(),
+			Sets:        formatNumber(muscleGroupSets),
+			Volume:      formatVolume(volume[target.MuscleGroup]),
+			SetsFrom:    target.SetsFrom,
+			SetsTo:      target.SetsTo,
+			Status:      MuscleGroupWithin,
+		}
+
+		if muscleGroupSets < float64(target.SetsFrom) {
+			muscleGroup.Status = MuscleGroupUnder
+			muscleGroup.Missing = formatNumber(float64(target.SetsFrom) - muscleGroupSets)
+		} else if muscleGroupSets > float64(target.SetsTo) {
+			muscleGroup.Status = MuscleGroupOver
+		}
+
+		// The bar runs up to the top of the range, the bottom of the range is marked on it.
+		if target.SetsTo > 0 {
+			muscleGroup.Percentage = int(math.Min(100, muscleGroupSets/float64(target.SetsTo)*100))
+			muscleGroup.FromPercentage = int(target.SetsFrom * 100 / target.SetsTo)
+		} else if muscleGroupSets > 0 {
+			muscleGroup.Percentage = 100
+		}
+		muscleGroups = append(muscleGroups, muscleGroup)
+	}
+
+	return muscleGroups, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"dumbbell/internal/dto"
+	"dumbbell/internal/environment"
+	"dumbbell/internal/mailer"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// How long a password reset link can be used after it has been requested.
+const PasswordResetTokenLifetime = time.Hour
+
+var InvalidResetTokenError = errors.New("Invalid or expired reset token")
+
+// generateToken returns a random token to hand out to the user, only its hash is stored.
+func generateToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func (s *SessionService) ChangePassword(userId int64, oldPassword string, newPassword string) error {
+	user, err := dto.GetUserById(userId, s.DB)
+	if err != nil {
+		return err
+	}
+
+	if err = bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(oldPassword)); err != nil {
+		return InvalidCredentialsError
+	}
+
+	return dto.UpdateUserPassword(user.ID, newPassword, s.DB)
+}
+
+// RequestPasswordReset mails a reset link to the user with the given email. Unknown emails are
+// ignored so the response does not reveal which emails have an account.
+func (s *SessionService) RequestPasswordReset(email string) error {
+	user, err := dto.GetUserByEmail(email, s.DB)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	if err = dto.CreatePasswordResetToken(user.ID, hashToken(token), time.Now().Add(PasswordResetTokenLifetime), s.DB); err != nil {
+		return err
+	}
+
+	resetURL := fmt.Sprintf("%s/reset-password?token=%s", environment.GetBaseURL(), url.QueryEscape(token))
+	return s.Mailer.Send(mailer.Mail{
+		To:      user.Email,
+		Subject: "Reset your Dumbbell password",
+		Body:    fmt.Sprintf("Someone asked to reset the password of your Dumbbell account.\n\nFollow the link below to choose a new password, it is valid for %d minutes:\n%s\n\nIf it was not you, you can ignore this mail.", int(PasswordResetTokenLifetime.Minutes()), resetURL),
+	})
+}
+
+func (s *SessionService) IsResetTokenValid(token string) bool {
+	return token != "" && dto.IsPasswordResetTokenValid(hashToken(token), s.DB)
+}
+
+// ResetPassword sets a new password using a reset token, the token and any other outstanding
+// tokens of the user can not be used again afterwards.
+func (s *SessionService) ResetPassword(token string, password string) error {
+	err := dto.ResetUserPassword(hashToken(token), password, s.DB)
+	if err == sql.ErrNoRows {
+		return InvalidResetTokenError
+	}
+	return err
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"fmt"
+	"log"
+	"math"
+)
+
+const (
+	// Number of consecutive sessions needed before the targets of an exercise are changed.
+	ProgressionSessions = 2
+	// Weight added to both ends of the weight range when every set has been good.
+	ProgressionWeightIncrement = 2.5
+	// Factor the targets are multiplied with when the sessions have been bad.
+	ProgressionDeloadFactor = 0.9
+	// Weights are rounded to the nearest multiple of this, matching the smallest plates.
+	ProgressionWeightStep = 0.25
+)
+
+type progressionSession struct {
+	Good int
+	Bad  int
+}
+
+// EvaluateProgression looks at the recent sessions of every exercise in the workout split and
+// suggests new targets, or applies them directly when the user has automatic progression enabled.
+func (s *WorkoutService) EvaluateProgression(userId int64, workoutId int64) error {
+	workout, err := dto.GetWorkout(userId, workoutId, s.DB)
+	if err != nil {
+		return err
+	}
+
+	user, err := dto.GetUserById(userId, s.DB)
+	if err != nil {
+		return err
+	}
+
+	exercises, err := dto.GetAllExercises(workout.SplitID, s.DB)
+	if err != nil {
+		return err
+	}
+
+	for _, exercise := range exercises {
+		if err := s.evaluateExerciseProgression(user, workout, exercise); err != nil {
+			log.Printf("EvaluateProgression Error exercise=%d: %s", exercise.ID, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *WorkoutService) evaluateExerciseProgression(user dto.User, workout dto.Workout, exercise dto.Exercise) error {
+	// The targets that are changed are weight and reps, timed and distance sets are left to the user.
+	if !exercise.Measurement.UsesReps() {
+		return nil
+	}
+
+	since, err := dto.GetLastProgressionDecision(workout.SplitID, exercise.ID, s.DB)
+	if err != nil {
+		return err
+	}
+
+	workouts, err := dto.GetLatestCompletedWorkoutsForExercise(workout.SplitID, exercise.ID, since, ProgressionSessions, s.DB)
+	if err != nil {
+		return err
+	}
+
+	if len(workouts) < ProgressionSessions {
+		return nil
+	}
+
+	sessions := []progressionSession{}
+	for _, sessionWorkout := range workouts {
+		workoutSets, err := dto.GetCompletedWorkoutSets(sessionWorkout.ID, exercise.ID, s.DB)
+		if err != nil {
+			return err
+		}
+
+		session := progressionSession{}
+		for _, workoutSet := range workoutSets {
+			if workoutSet.SetType == dto.SetWarmup {
+				continue
+			}
+
+			if workoutSet.SetRating == dto.SetGood {
+				session.Good++
+			} else if workoutSet.SetRating == dto.SetBad {
+				session.Bad++
+			}
+		}
+		sessions = append(sessions, session)
+	}
+
+	allGood := true
+	allBad := true
+	for _, session := range sessions {
+		allGood = allGood && session.Bad == 0 && int64(session.Good) >= exercise.Sets
+		allBad = allBad && session.Bad > session.Good
+	}
+
+	var weightFrom, weightTo, repsFrom, repsTo float64
+	var reason string
+	if allGood {
+		weightFrom, weightTo, repsFrom, repsTo = increaseTargets(exercise)
+		reason = fmt.Sprintf("All sets good %d sessions in a row", ProgressionSessions)
+	} else if allBad {
+		weightFrom, weightTo, repsFrom, repsTo = deloadTargets(exercise)
+		reason = fmt.Sprintf("%d bad sessions in a row, deload %d%%", ProgressionSessions, int(math.Round((1-ProgressionDeloadFactor)*100)))
+	} else {
+		return nil
+	}
+
+	if user.AutoProgression {
+		if _, err := dto.UpdateExerciseTargets(workout.SplitID, exercise.ID, weightFrom, weightTo, repsFrom, repsTo, s.DB); err != nil {
+			return err
+		}
+		_, err = dto.CreateProgression(exercise.ID, workout.ID, weightFrom, weightTo, repsFrom, repsTo, reason, dto.ProgressionApplied, s.DB)
+		return err
+	}
+
+	_, err = dto.CreateProgression(exercise.ID, workout.ID, weightFrom, weightTo, repsFrom, repsTo, reason, dto.ProgressionPending, s.DB)
+	return err
+}
+
+// increaseTargets adds weight to loaded exercises and a rep to bodyweight exercises.
+func increaseTargets(exercise dto.Exercise) (float64, float64, float64, float64) {
+	if exercise.WeightTo == 0 {
+		return exercise.WeightFrom, exercise.WeightTo, exercise.RepsFrom + 1, exercise.RepsTo + 1
+	}
+
+	return exercise.WeightFrom + ProgressionWeightIncrement, exercise.WeightTo + ProgressionWeightIncrement, exercise.RepsFrom, exercise.RepsTo
+}
+
+// deloadTargets reduces the weight of loaded exercises and the reps of bodyweight exercises.
+func deloadTargets(exercise dto.Exercise) (float64, float64, float64, float64) {
+	if exercise.WeightTo == 0 {
+		return exercise.WeightFrom, exercise.WeightTo, math.Max(1, math.Floor(exercise.RepsFrom*ProgressionDeloadFactor)), math.Max(1, math.Floor(exercise.RepsTo*ProgressionDeloadFactor))
+	}
+
+	return roundWeight(exercise.WeightFrom * ProgressionDeloadFactor), roundWeight(exercise.WeightTo * ProgressionDeloadFactor), exercise.RepsFrom, exercise.RepsTo
+}
+
+func roundWeight(weight float64) float64 {
+	return math.Round(weight/ProgressionWeightStep) * ProgressionWeightStep
+}
+
+func (s *WorkoutService) GetProgressionModel(splitId int64, exerciseId int64) *model.ProgressionModel {
+	progression, err := dto.GetPendingProgression(splitId, exerciseId, s.DB)
+	if err != nil {
+		return nil
+	}
+
+	return &model.ProgressionModel{
+		ID:         progression.ID,
+		WeightFrom: progression.WeightFrom,
+		WeightTo:   progression.WeightTo,
+		RepsFrom:   progression.RepsFrom,
+		RepsTo:     progression.RepsTo,
+		Reason:     progression.Reason,
+	}
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"database/sql"
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+// EstimatedOneRepMax estimates the heaviest single rep from a set with the Epley formula, a set of one rep
+// is the weight itself.
+func EstimatedOneRepMax(weight float64, reps int64) float64 {
+	if reps <= 1 {
+		return weight
+	}
+	return math.Round(weight*(1+float64(reps)/30)*10) / 10
+}
+
+// FindPersonalRecords goes through the sets of an exercise workout by workout, in the order they were done,
+// and returns every time a workout beat the best of the workouts before it. A workout sets a record of a
+// kind at most once, with its best set, and the first workout with a kind of set sets a record without a
+// previous value. Only exercises measured in reps have records, the weight records need weights.
+func FindPersonalRecords(measurement dto.Measurement, workoutSets []dto.WorkoutSet) []dto.PersonalRecord {
+	records := []dto.PersonalRecord{}
+	if !measurement.UsesReps() {
+		return records
+	}
+
+	var heaviest, oneRepMax, volume sql.NullFloat64
+	repsAtWeight := map[float64]int64{}
+
+	addRecord := func(workoutSet dto.WorkoutSet, recordType dto.RecordType, value float64, previous sql.NullFloat64) {
+		records = append(records, dto.PersonalRecord{
+			ExerciseID:    workoutSet.ExerciseID,
+			WorkoutID:     workoutSet.WorkoutID,
+			SetNumber:     sql.NullInt64{Int64: workoutSet.SetNumber, Valid: true},
+			Type:          recordType,
+			Weight:        workoutSet.Weight,
+			Reps:          workoutSet.Reps,
+			Value:         value,
+			PreviousValue: previous,
+			AchievedAt:    workoutSet.CompletedAt.Time,
+		})
+	}
+
+	for start := 0; start < len(workoutSets); {
+		end := start + 1
+		for end < len(workoutSets) && workoutSets[end].WorkoutID == workoutSets[start].WorkoutID {
+			end++
+		}
+		session := workoutSets[start:end]
+		start = end
+
+		// The best sets of the workout, the first of them when sets tie.
+		heaviestSet, oneRepMaxSet := -1, -1
+		repsSets := map[float64]int{}
+		weights := []float64{}
+		sessionVolume := 0.0
+		for i, workoutSet := range session {
+			if workoutSet.Reps <= 0 {
+				continue
+			}
+
+			if measurement.UsesWeight() && workoutSet.Weight > 0 {
+				if heaviestSet < 0 || workoutSet.Weight > session[heaviestSet].Weight {
+					heaviestSet = i
+				}
+				if oneRepMaxSet < 0 || EstimatedOneRepMax(workoutSet.Weight, workoutSet.Reps) > EstimatedOneRepMax(session[oneRepMaxSet].Weight, session[oneRepMaxSet].Reps) {
+					oneRepMaxSet = i
+				}
+				sessionVolume += workoutSet.Weight * float64(workoutSet.Reps)
+			}
+
+			best, ok := repsSets[workoutSet.Weight]
+			if !ok {
+				weights = append(weights, workoutSet.Weight)
+			}
+			if !ok || workoutSet.Reps > session[best].Reps {
+				repsSets[workoutSet.Weight] = i
+			}
+		}
+
+		if heaviestSet >= 0 {
+			workoutSet := session[heaviestSet]
+			if !heaviest.Valid || workoutSet.Weight > heaviest.Float64 {
+				addRecord(workoutSet, dto.RecordHeaviestWeight, workoutSet.Weight, heaviest)
+				heaviest = sql.NullFloat64{Float64: workoutSet.Weight, Valid: true}
+			}
+		}
+
+		if oneRepMaxSet >= 0 {
+			workoutSet := session[oneRepMaxSet]
+			estimate := EstimatedOneRepMax(workoutSet.Weight, workoutSet.Reps)
+			if !oneRepMax.Valid || estimate > oneRepMax.Float64 {
+				addRecord(workoutSet, dto.RecordEstimatedOneRM, estimate, oneRepMax)
+				oneRepMax = sql.NullFloat64{Float64: estimate, Valid: true}
+			}
+		}
+
+		for _, weight := range weights {
+			workoutSet := session[repsSets[weight]]
+			best, ok := repsAtWeight[weight]
+			if !ok || workoutSet.Reps > best {
+				addRecord(workoutSet, dto.RecordRepsAtWeight, float64(workoutSet.Reps), sql.NullFloat64{Float64: float64(best), Valid: ok})
+				repsAtWeight[weight] = workoutSet.Reps
+			}
+		}
+
+		// The volume of a session is known with its last set.
+		lastSet := session[len(session)-1]
+		sessionVolume = math.Round(sessionVolume*100) / 100
+		if sessionVolume > 0 && (!volume.Valid || sessionVolume > volume.Float64) {
+			records = append(records, dto.PersonalRecord{
+				ExerciseID:    lastSet.ExerciseID,
+				WorkoutID:     lastSet.WorkoutID,
+				Type:          dto.RecordSessionVolume,
+				Value:         sessionVolume,
+				PreviousValue: volume,
+				AchievedAt:    lastSet.CompletedAt.Time,
+			})
+			volume = sql.NullFloat64{Float64: sessionVolume, Valid: true}
+		}
+	}
+
+	return records
+}
+
+// UpdatePersonalRecords rebuilds the record timeline of the exercise from its logged sets, sets that were
+// edited, deleted or undone are reflected in it right away.
+func (s *WorkoutService) UpdatePersonalRecords(userId int64, exerciseId int64) error {
+	exercise, err := dto.GetExercise(userId, exerciseId, s.DB)
+	if err == sql.ErrNoRows {
+		// The records of a deleted exercise are deleted with it.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	workoutSets, err := dto.GetExerciseHistorySets(userId, exercise.ID, s.DB)
+	if err != nil {
+		return err
+	}
+
+	return dto.ReplacePersonalRecords(userId, exercise.ID, FindPersonalRecords(exercise.Measurement, workoutSets), s.DB)
+}
+
+// UpdateWorkoutPersonalRecords rebuilds the records of the exercises done in the workout.
+func (s *WorkoutService) UpdateWorkoutPersonalRecords(userId int64, workoutId int64) error {
+	exerciseIds, err := dto.GetWorkoutRecordExercises(workoutId, s.DB)
+	if err != nil {
+		return err
+	}
+	return s.updatePersonalRecords(userId, exerciseIds)
+}
+
+// UpdateAllPersonalRecords rebuilds the records of every exercise of the user, after an import or for data
+// logged before records were kept.
+func (s *WorkoutService) UpdateAllPersonalRecords(userId int64) error {
+	exerciseIds, err := dto.GetRecordExercises(userId, s.DB)
+	if err != nil {
+		return err
+	}
+	return s.updatePersonalRecords(userId, exerciseIds)
+}
+
+func (s *WorkoutService) updatePersonalRecords(userId int64, exerciseIds []int64) error {
+	for _, exerciseId := range exerciseIds {
+		if err := s.UpdatePersonalRecords(userId, exerciseId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteWorkout deletes the workout, its records go with it and the records of its exercises are rebuilt
+// without it.
+func (s *WorkoutService) DeleteWorkout(userId int64, workoutId int64) error {
+	exerciseIds, err := dto.GetWorkoutRecordExercises(workoutId, s.DB)
+	if err != nil {
+		return err
+	}
+
+	if err = dto.DeleteWorkout(userId, workoutId, s.DB); err != nil {
+		return err
+	}
+
+	if err = s.updatePersonalRecords(userId, exerciseIds); err != nil {
+		log.Printf("Error updating personal records: %s", err.Error())
+	}
+	return nil
+}
+
+// GetBeatenRecords returns the records of the workout that beat an earlier best, by exercise and set
+// number. Session volume records are under set number 0.
+func (s *WorkoutService) GetBeatenRecords(workoutId int64) (map[int64]map[int64][]dto.PersonalRecord, error) {
+	records, err := dto.GetWorkoutPersonalRecords(workoutId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	beaten := map[int64]map[int64][]dto.PersonalRecord{}
+	for _, record := range records {
+		if !record.PreviousValue.Valid {
+			continue
+		}
+		if beaten[record.ExerciseID] == nil {
+			beaten[record.ExerciseID] = map[int64][]dto.PersonalRecord{}
+		}
+		beaten[record.ExerciseID][record.SetNumber.Int64] = append(beaten[record.ExerciseID][record.SetNumber.Int64], record)
+	}
+
+	return beaten, nil
+}
+
+// FormatRecord describes what a record is and what it beat, like "62.5 × 5" and "60 kg".
+func FormatRecord(measurement dto.Measurement, record dto.PersonalRecord) (string, string) {
+	previous := ""
+	switch record.Type {
+	case dto.RecordRepsAtWeight:
+		if record.PreviousValue.Valid {
+			previous = fmt.Sprintf("%s reps", formatNumber(record.PreviousValue.Float64))
+		}
+		return FormatSetResult(measurement, record.Weight, record.Reps, 0, 0), previous
+	case dto.RecordHeaviestWeight:
+		if record.PreviousValue.Valid {
+			previous = formatNumber(record.PreviousValue.Float64) + " kg"
+		}
+		return FormatSetResult(measurement, record.Weight, record.Reps, 0, 0), previous
+	case dto.RecordEstimatedOneRM:
+		if record.PreviousValue.Valid {
+			previous = formatNumber(record.PreviousValue.Float64) + " kg"
+		}
+		return formatNumber(record.Value) + " kg", previous
+	}
+
+	if record.PreviousValue.Valid {
+		previous = formatVolume(record.PreviousValue.Float64)
+	}
+	return formatVolume(record.Value), previous
+}
+
+// GetPersonalRecordsModel lays out the record board of an exercise, the current records on top of the
+// timeline of every record it set.
+func (s *WorkoutService) GetPersonalRecordsModel(userId int64, exerciseId int64) (model.PersonalRecordsPageModel, error) {
+	exercise, err := dto.GetExercise(userId, exerciseId, s.DB)
+	if err != nil {
+		return model.PersonalRecordsPageModel{}, err
+	}
+
+	records, err := dto.GetExercisePersonalRecords(userId, exercise.ID, s.DB)
+	if err != nil {
+		return model.PersonalRecordsPageModel{}, err
+	}
+
+	viewModel := model.PersonalRecordsPageModel{
+		Title:       fmt.Sprintf("Dumbbell - %s records", exercise.Name),
+		ExerciseID:  exercise.ID,
+		Name:        exercise.Name,
+		ImageSrc:    exercise.GetImageURL(),
+		HasRecords:  exercise.Measurement.UsesReps(),
+		Current:     []model.PersonalRecordModel{},
+		RepsRecords: []model.PersonalRecordModel{},
+		Timeline:    []model.PersonalRecordModel{},
+	}
+
+	location := GetUserLocation(userId, s.DB)
+
+	// The timeline is newest first, the first record of a kind seen is the current one.
+	current := map[dto.RecordType]bool{}
+	repsAtWeight := map[float64]bool{}
+	for _, record := range records {
+		result, previous := FormatRecord(exercise.Measurement, record)
+		recordModel := model.PersonalRecordModel{
+			Type:      record.Type,
+			Weight:    record.Weight,
+			Record:    record.Type.Name(),
+			Result:    result,
+			Previous:  previous,
+			Date:      record.AchievedAt.In(location).Format("2006-01-02"),
+			WorkoutID: record.WorkoutID,
+		}
+		viewModel.Timeline = append(viewModel.Timeline, recordModel)
+
+		if record.Type == dto.RecordRepsAtWeight {
+			if !repsAtWeight[record.Weight] {
+				repsAtWeight[record.Weight] = true
+				viewModel.RepsRecords = append(viewModel.RepsRecords, recordModel)
+			}
+		} else if !current[record.Type] {
+			current[record.Type] = true
+			viewModel.Current = append(viewModel.Current, recordModel)
+		}
+	}
+
+	sort.SliceStable(viewModel.Current, func(i, j int) bool {
+		return recordTypeOrder(viewModel.Current[i].Type) < recordTypeOrder(viewModel.Current[j].Type)
+	})
+	sort.SliceStable(viewModel.RepsRecords, func(i, j int) bool {
+		return viewModel.RepsRecords[i].Weight > viewModel.RepsRecords[j].Weight
+	})
+
+	return viewModel, nil
+}
+
+func recordTypeOrder(recordType dto.RecordType) int {
+	for i, orderedType := range dto.RecordTypes {
+		if orderedType == recordType {
+			return i
+		}
+	}
+	return len(dto.RecordTypes)
+}
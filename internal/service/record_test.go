@@ -0,0 +1,88 @@
+package service
+
+import (
+	"database/sql"
+	"dumbbell/internal/dto"
+	"testing"
+	"time"
+)
+
+func TestFindPersonalRecordsComparesWithEarlierWorkouts(t *testing.T) {
+	started := time.Date(2024, time.March, 4, 18, 0, 0, 0, time.UTC)
+	workoutSets := []dto.WorkoutSet{}
+	logSet := func(workoutId int64, setNumber int64, weight float64, reps int64) {
+		completedAt := started.AddDate(0, 0, int(workoutId)).Add(time.Duration(setNumber) * time.Minute)
+		workoutSets = append(workoutSets, dto.WorkoutSet{
+			WorkoutID:   workoutId,
+			ExerciseID:  1,
+			SetNumber:   setNumber,
+			Weight:      weight,
+			Reps:        reps,
+			CompletedAt: sql.NullTime{Time: completedAt, Valid: true},
+		})
+	}
+
+	// Each set of the first workout beats the one before it.
+	logSet(1, 1, 100, 5)
+	logSet(1, 2, 105, 5)
+	logSet(1, 3, 110, 3)
+	logSet(2, 1, 100, 6)
+	logSet(2, 2, 112, 2)
+	logSet(2, 3, 112, 3)
+
+	type recordKey struct {
+		workoutId  int64
+		recordType dto.RecordType
+		weight     float64
+	}
+	records := map[recordKey]dto.PersonalRecord{}
+	for _, record := range FindPersonalRecords(dto.MeasurementWeightReps, workoutSets) {
+		key := recordKey{workoutId: record.WorkoutID, recordType: record.Type}
+		if record.Type == dto.RecordRepsAtWeight {
+			key.weight = record.Weight
+		}
+		if _, ok := records[key]; ok {
+			t.Errorf("workout %d has more than one %s record", key.workoutId, record.Type.Name())
+		}
+		records[key] = record
+	}
+
+	for key, record := range records {
+		if key.workoutId == 1 && record.PreviousValue.Valid {
+			t.Errorf("the first workout beat a %s record of %v", record.Type.Name(), record.PreviousValue.Float64)
+		}
+	}
+
+	for _, want := range []struct {
+		key       recordKey
+		setNumber int64
+		value     float64
+		previous  sql.NullFloat64
+	}{
+		{recordKey{1, dto.RecordHeaviestWeight, 0}, 3, 110, sql.NullFloat64{}},
+		{recordKey{1, dto.RecordEstimatedOneRM, 0}, 2, 122.5, sql.NullFloat64{}},
+		{recordKey{1, dto.RecordSessionVolume, 0}, 0, 1355, sql.NullFloat64{}},
+		{recordKey{2, dto.RecordHeaviestWeight, 0}, 2, 112, sql.NullFloat64{Float64: 110, Valid: true}},
+		{recordKey{2, dto.RecordEstimatedOneRM, 0}, 3, 123.2, sql.NullFloat64{Float64: 122.5, Valid: true}},
+		{recordKey{2, dto.RecordRepsAtWeight, 100}, 1, 6, sql.NullFloat64{Float64: 5, Valid: true}},
+		{recordKey{2, dto.RecordRepsAtWeight, 112}, 3, 3, sql.NullFloat64{}},
+	} {
+		record, ok := records[want.key]
+		if !ok {
+			t.Errorf("no %s record in workout %d", want.key.recordType.Name(), want.key.workoutId)
+			continue
+		}
+		if record.SetNumber.Int64 != want.setNumber || record.Value != want.value || record.PreviousValue != want.previous {
+			t.Errorf("%s record in workout %d: got set %d with %v over %v, want set %d with %v over %v",
+				want.key.recordType.Name(), want.key.workoutId, record.SetNumber.Int64, record.Value, record.PreviousValue,
+				want.setNumber, want.value, want.previous)
+		}
+	}
+
+	if _, ok := records[recordKey{2, dto.RecordSessionVolume, 0}]; ok {
+		t.Error("the second workout beat the session volume with less volume")
+	}
+	if len(records) != 10 {
+		t.Errorf("got %d records, want 10", len(records))
+	}
+}
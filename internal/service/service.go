@@ -1 +1,16 @@
 package service
+
+import (
+	"database/sql"
+	"dumbbell/internal/dto"
+	"time"
+)
+
+// GetUserLocation returns the time zone the dates of the user are shown and grouped in.
+func GetUserLocation(userId int64, db *sql.DB) *time.Location {
+	user, err := dto.GetUserById(userId, db)
+	if err != nil {
+		return time.Local
+	}
+	return user.Location()
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"database/sql"
 	"dumbbell/internal/dto"
+	"dumbbell/internal/mailer"
 	"dumbbell/internal/model"
+	"dumbbell/internal/utils"
 	"errors"
 	"log"
 	"net/http"
@@ -18,19 +20,21 @@ var SESSION_COOKIE_NAME = "user-session"
 var InvalidCredentialsError = errors.New("Invalid credentials")
 
 type SessionService struct {
-	DB    *sql.DB
-	Store *sqlitestore.SqliteStore
+	DB     *sql.DB
+	Store  *sqlitestore.SqliteStore
+	Mailer mailer.Mailer
 }
 
-func NewSessionService(db *sql.DB) *SessionService {
+func NewSessionService(db *sql.DB, mailer mailer.Mailer) *SessionService {
 	store, err := sqlitestore.NewSqliteStoreFromConnection(db, "user_sessions", "/", 0, []byte("NOT_SO_SECRET_KEY"))
 	if err != nil {
 		panic(err)
 	}
 
 	return &SessionService{
-		DB:    db,
-		Store: store,
+		DB:     db,
+		Store:  store,
+		Mailer: mailer,
 	}
 }
 
@@ -38,6 +42,8 @@ type LoginUserData struct {
 	Email    string
 	Password string
 	Remember bool
+	// Timezone is the time zone reported by the browser, it is kept when the user has none yet.
+	Timezone string
 }
 
 func (s *SessionService) LoginUser(w http.ResponseWriter, r *http.Request, data LoginUserData) error {
@@ -71,6 +77,12 @@ func (s *SessionService) LoginUser(w http.ResponseWriter, r *http.Request, data
 		return saveSessionErr
 	}
 
+	if user.Timezone == "" && utils.IsValidTimezone(data.Timezone) {
+		if err := dto.UpdateUserTimezone(user.ID, data.Timezone, s.DB); err != nil {
+			log.Printf("Error saving timezone: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -91,6 +103,11 @@ func (s *SessionService) LogoutUser(w http.ResponseWriter, r *http.Request) erro
 
 func (s *SessionService) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := apiTokenUserId(r); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		session, err := s.Store.Get(r, SESSION_COOKIE_NAME)
 		if err != nil {
 			log.Printf("Error getting session: %s", err.Error())
@@ -109,6 +126,10 @@ func (s *SessionService) AuthMiddleware(next http.Handler) http.Handler {
 }
 
 func (s *SessionService) GetUserId(r *http.Request) (int64, error) {
+	if userId, ok := apiTokenUserId(r); ok {
+		return userId, nil
+	}
+
 	session, err := s.Store.Get(r, SESSION_COOKIE_NAME)
 	if err != nil {
 		return 0, err
@@ -134,6 +155,10 @@ func (s *SessionService) MustGetUserId(w http.ResponseWriter, r *http.Request) i
 }
 
 func (s *SessionService) IsAuthenticated(r *http.Request) bool {
+	if _, ok := apiTokenUserId(r); ok {
+		return true
+	}
+
 	session, err := s.Store.Get(r, SESSION_COOKIE_NAME)
 	if err != nil {
 		return false
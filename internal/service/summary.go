@@ -0,0 +1,303 @@
+package service
+
+import (
+	"database/sql"
+	"dumbbell/internal/dto"
+	"dumbbell/internal/model"
+	"dumbbell/internal/utils"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// workoutTotals adds up the logged sets of a workout. Warm-up sets only count towards the time, like
+// everywhere else they are left out of the sets and the volume.
+type workoutTotals struct {
+	sets   int
+	good   int
+	bad    int
+	volume float64
+	work   time.Duration
+	rest   time.Duration
+}
+
+func (t *workoutTotals) add(workoutSet dto.WorkoutSet, measurement dto.Measurement) {
+	t.work += workoutSet.CompletedAt.Time.Sub(workoutSet.StartedAt)
+	if workoutSet.RestSeconds.Valid {
+		t.rest += time.Duration(workoutSet.RestSeconds.Int64) * time.Second
+	}
+	if workoutSet.SetType == dto.SetWarmup {
+		return
+	}
+
+	t.sets++
+	switch workoutSet.SetRating {
+	case dto.SetGood:
+		t.good++
+	case dto.SetBad:
+		t.bad++
+	}
+	if measurement.UsesWeight() {
+		t.volume += workoutSet.Weight * float64(workoutSet.Reps)
+	}
+}
+
+// GetWorkoutSummaryModel sums up a completed workout, with the personal records set in it and how it
+// compares with the session of the same split before it.
+func (s *WorkoutService) GetWorkoutSummaryModel(userId int64, workoutId int64) (model.WorkoutSummaryPageModel, error) {
+	workout, err := dto.GetWorkout(userId, workoutId, s.DB)
+	if err != nil {
+		return model.WorkoutSummaryPageModel{}, err
+	}
+	if !workout.CompletedAt.Valid {
+		return model.WorkoutSummaryPageModel{}, sql.ErrNoRows
+	}
+
+	split, err := dto.GetSplit(userId, workout.SplitID, s.DB)
+	if err != nil {
+		return model.WorkoutSummaryPageModel{}, err
+	}
+
+	exercises := map[int64]dto.Exercise{}
+	getExercise := func(exerciseId int64) (dto.Exercise, error) {
+		if exercise, ok := exercises[exerciseId]; ok {
+			return exercise, nil
+		}
+
+		exercise, err := dto.GetExercise(userId, exerciseId, s.DB)
+		if err == nil {
+			exercises[exerciseId] = exercise
+		}
+		return exercise, err
+	}
+
+	workoutSets, err := dto.GetWorkoutSets(workout.ID, s.DB)
+	if err != nil {
+		return model.WorkoutSummaryPageModel{}, err
+	}
+
+	totals := workoutTotals{}
+	exerciseTotals := map[int64]*workoutTotals{}
+	bestSets := map[int64]dto.WorkoutSet{}
+	order := []int64{}
+	for _, workoutSet := range workoutSets {
+		if !workoutSet.CompletedAt.Valid {
+			continue
+		}
+
+		exercise, err := getExercise(workoutSet.ExerciseID)
+		if err != nil {
+			return model.WorkoutSummaryPageModel{}, err
+		}
+
+		totals.add(workoutSet, exercise.Measurement)
+		if workoutSet.SetType == dto.SetWarmup {
+			continue
+		}
+
+		if _, ok := exerciseTotals[exercise.ID]; !ok {
+			exerciseTotals[exercise.ID] = &workoutTotals{}
+			bestSets[exercise.ID] = workoutSet
+			order = append(order, exercise.ID)
+		}
+		exerciseTotals[exercise.ID].add(workoutSet, exercise.Measurement)
+		if isBetterSet(exercise.Measurement, workoutSet, bestSets[exercise.ID]) {
+			bestSets[exercise.ID] = workoutSet
+		}
+	}
+
+	beatenRecords, err := s.GetBeatenRecords(workout.ID)
+	if err != nil {
+		return model.WorkoutSummaryPageModel{}, err
+	}
+
+	location := GetUserLocation(userId, s.DB)
+	metadata := GetWorkoutMetaData(workout, location)
+	viewModel := model.WorkoutSummaryPageModel{
+		Title:     fmt.Sprintf("Dumbbell - %s summary", split.Name),
+		ID:        workout.ID,
+		SplitName: split.Name,
+		Date:      metadata.WorkoutStart,
+		Duration:  completedWorkoutDuration(workout),
+		WorkTime:  utils.FmtDuration(totals.work),
+		RestTime:  utils.FmtDuration(totals.rest),
+		SetCount:  totals.sets,
+		GoodCount: totals.good,
+		BadCount:  totals.bad,
+		Volume:    formatVolume(totals.volume),
+		Exercises: []model.WorkoutSummaryExerciseModel{},
+		Records:   []model.WorkoutSummaryRecordModel{},
+	}
+	if totals.work+totals.rest > 0 {
+		viewModel.WorkPercentage = int(utils.PercentOf(int(totals.work.Seconds()), int((totals.work + totals.rest).Seconds())))
+	}
+
+	for _, exerciseId := range order {
+		exercise := exercises[exerciseId]
+		bestSet := bestSets[exerciseId]
+		exerciseModel := model.WorkoutSummaryExerciseModel{
+			ID:        exercise.ID,
+			Name:      exercise.Name,
+			ImageSrc:  exercise.GetImageURL(),
+			SetCount:  exerciseTotals[exerciseId].sets,
+			GoodCount: exerciseTotals[exerciseId].good,
+			BadCount:  exerciseTotals[exerciseId].bad,
+			BestSet:   FormatSetResult(exercise.Measurement, bestSet.Weight, bestSet.Reps, bestSet.Seconds, bestSet.Distance),
+		}
+		if exercise.Measurement.UsesWeight() {
+			exerciseModel.Volume = formatVolume(exerciseTotals[exerciseId].volume)
+		}
+		viewModel.Exercises = append(viewModel.Exercises, exerciseModel)
+		viewModel.Records = append(viewModel.Records, getSummaryRecords(exercise, beatenRecords[exerciseId])...)
+	}
+
+	previous, err := s.getPreviousSession(userId, workout)
+	if err != nil {
+		return model.WorkoutSummaryPageModel{}, err
+	}
+	if previous.ID == 0 {
+		return viewModel, nil
+	}
+
+	previousSets, err := dto.GetWorkoutSets(previous.ID, s.DB)
+	if err != nil {
+		return model.WorkoutSummaryPageModel{}, err
+	}
+
+	previousTotals := workoutTotals{}
+	for _, workoutSet := range previousSets {
+		if !workoutSet.CompletedAt.Valid {
+			continue
+		}
+
+		exercise, err := getExercise(workoutSet.ExerciseID)
+		if err != nil {
+			return model.WorkoutSummaryPageModel{}, err
+		}
+		previousTotals.add(workoutSet, exercise.Measurement)
+	}
+
+	duration := workout.CompletedAt.Time.Sub(workout.StartedAt)
+	previousDuration := previous.CompletedAt.Time.Sub(previous.StartedAt)
+	viewModel.Previous = &model.WorkoutSummaryComparisonModel{
+		ID:   previous.ID,
+		Date: GetWorkoutMetaData(previous, location).WorkoutStart,
+		Rows: []model.WorkoutSummaryComparisonRowModel{
+			{
+				Label:    "Duration",
+				Current:  utils.FmtDuration(duration),
+				Previous: utils.FmtDuration(previousDuration),
+				Change:   formatDurationChange(duration - previousDuration),
+			},
+			compareCounts("Sets", totals.sets, previousTotals.sets),
+			compareCounts("Good sets", totals.good, previousTotals.good),
+			{
+				Label:     "Volume",
+				Current:   formatVolume(totals.volume),
+				Previous:  formatVolume(previousTotals.volume),
+				Change:    formatSignedNumber(math.Round((totals.volume-previousTotals.volume)*100)/100) + " kg",
+				Direction: compareValues(totals.volume, previousTotals.volume),
+			},
+		},
+	}
+
+	return viewModel, nil
+}
+
+// getPreviousSession returns the completed workout of the same split started last before the workout, its
+// ID is 0 when there is none.
+func (s *WorkoutService) getPreviousSession(userId int64, workout dto.Workout) (dto.Workout, error) {
+	workouts, err := dto.GetAllCompletedWorkoutsForSplit(userId, workout.SplitID, s.DB)
+	if err != nil {
+		return dto.Workout{}, err
+	}
+
+	previous := dto.Workout{}
+	for _, splitWorkout := range workouts {
+		if splitWorkout.ID != workout.ID && splitWorkout.StartedAt.Before(workout.StartedAt) {
+			previous = splitWorkout
+		}
+	}
+	return previous, nil
+}
+
+// isBetterSet compares sets in what their exercise is measured in, the heavier weight wins and the reps
+// decide between sets of the same weight.
+func isBetterSet(measurement dto.Measurement, workoutSet dto.WorkoutSet, best dto.WorkoutSet) bool {
+	switch measurement {
+	case dto.MeasurementBodyweightReps:
+		return workoutSet.Reps > best.Reps
+	case dto.MeasurementDuration:
+		return workoutSet.Seconds > best.Seconds
+	case dto.MeasurementDistance:
+		return workoutSet.Distance > best.Distance
+	}
+	return workoutSet.Weight > best.Weight || (workoutSet.Weight == best.Weight && workoutSet.Reps > best.Reps)
+}
+
+// getSummaryRecords lists the records an exercise beat in the workout, with the best before the workout.
+func getSummaryRecords(exercise dto.Exercise, beatenRecords map[int64][]dto.PersonalRecord) []model.WorkoutSummaryRecordModel {
+	beaten := []dto.PersonalRecord{}
+	for _, setRecords := range beatenRecords {
+		beaten = append(beaten, setRecords...)
+	}
+
+	sort.SliceStable(beaten, func(i, j int) bool {
+		if beaten[i].Type != beaten[j].Type {
+			return recordTypeOrder(beaten[i].Type) < recordTypeOrder(beaten[j].Type)
+		}
+		return beaten[i].Weight > beaten[j].Weight
+	})
+
+	records := []model.WorkoutSummaryRecordModel{}
+	for _, record := range beaten {
+		result, previous := FormatRecord(exercise.Measurement, record)
+		records = append(records, model.WorkoutSummaryRecordModel{
+			ExerciseID:   exercise.ID,
+			ExerciseName: exercise.Name,
+			Record:       record.Type.Name(),
+			Result:       result,
+			Previous:     previous,
+		})
+	}
+
+	return records
+}
+
+func compareCounts(label string, current int, previous int) model.WorkoutSummaryComparisonRowModel {
+	return model.WorkoutSummaryComparisonRowModel{
+		Label:     label,
+		Current:   fmt.Sprint(current),
+		Previous:  fmt.Sprint(previous),
+		Change:    formatSignedNumber(float64(current - previous)),
+		Direction: compareValues(float64(current), float64(previous)),
+	}
+}
+
+func compareValues(current float64, previous float64) int {
+	if current > previous {
+		return 1
+	} else if current < previous {
+		return -1
+	}
+	return 0
+}
+
+func formatVolume(volume float64) string {
+	return formatNumber(math.Round(volume*100)/100) + " kg"
+}
+
+func formatSignedNumber(value float64) string {
+	if value > 0 {
+		return "+" + formatNumber(value)
+	}
+	return formatNumber(value)
+}
+
+func formatDurationChange(change time.Duration) string {
+	if change < 0 {
+		return "-" + utils.FmtDuration(-change)
+	}
+	return "+" + utils.FmtDuration(change)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"database/sql"
+	"dumbbell/internal/db"
+	"dumbbell/internal/dto"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestDB(tb testing.TB) *sql.DB {
+	tb.Helper()
+
+	database, err := db.OpenFile(filepath.Join(tb.TempDir(), "database.db"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { database.Close() })
+
+	if _, err = db.MigrateUp(database); err != nil {
+		tb.Fatal(err)
+	}
+	return database
+}
+
+func TestWorkoutSummaryLeavesOutWarmups(t *testing.T) {
+	database := newTestDB(t)
+	workoutService := NewWorkoutService(database)
+
+	user, err := dto.CreateUser("own@example.com", "password", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split, err := dto.CreateSplit(user.ID, "Push", "", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, err := dto.CreateImage(dto.ImageType("png"), []byte{}, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise, err := dto.CreateExercise(user.ID, &image.ID, "Bench press", "", "", dto.MeasurementWeightReps, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = dto.AddSplitExercise(user.ID, split.ID, exercise.ID, 100, 110, 5, 8, 0, 0, 0, 0, 3, 90, database); err != nil {
+		t.Fatal(err)
+	}
+
+	type loggedSet struct {
+		setType dto.SetType
+		rating  dto.SetStatus
+		weight  float64
+		reps    int64
+	}
+	logWorkout := func(startedAt time.Time, sets []loggedSet) dto.Workout {
+		workout, err := dto.CreatePastWorkout(user.ID, split.ID, startedAt, startedAt.Add(time.Hour), database)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, set := range sets {
+			if _, err = dto.AddWorkoutSet(user.ID, workout.ID, exercise.ID, set.rating, set.setType, set.weight, set.reps, 0, 0, database); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return workout
+	}
+
+	now := time.Now()
+	logWorkout(now.AddDate(0, 0, -7), []loggedSet{
+		{dto.SetWarmup, dto.SetGood, 60, 10},
+		{dto.SetWorking, dto.SetGood, 100, 5},
+	})
+	workout := logWorkout(now.AddDate(0, 0, -1), []loggedSet{
+		{dto.SetWarmup, dto.SetGood, 60, 10},
+		{dto.SetWorking, dto.SetGood, 100, 5},
+		{dto.SetWorking, dto.SetBad, 105, 4},
+	})
+
+	summary, err := workoutService.GetWorkoutSummaryModel(user.ID, workout.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.SetCount != 2 || summary.GoodCount != 1 || summary.BadCount != 1 {
+		t.Errorf("got %d sets, %d good and %d bad, want 2, 1 and 1", summary.SetCount, summary.GoodCount, summary.BadCount)
+	}
+	if want := formatVolume(920); summary.Volume != want {
+		t.Errorf("got volume %s, want %s", summary.Volume, want)
+	}
+
+	if len(summary.Exercises) != 1 {
+		t.Fatalf("got %d exercises, want 1", len(summary.Exercises))
+	}
+	if got := summary.Exercises[0]; got.SetCount != 2 || got.Volume != formatVolume(920) {
+		t.Errorf("got %d sets and %s for the exercise, want 2 and %s", got.SetCount, got.Volume, formatVolume(920))
+	}
+
+	if summary.Previous == nil {
+		t.Fatal("no comparison with the previous session")
+	}
+	for _, row := range summary.Previous.Rows {
+		switch row.Label {
+		case "Sets":
+			if row.Current != "2" || row.Previous != "1" {
+				t.Errorf("got sets %s against %s, want 2 against 1", row.Current, row.Previous)
+			}
+		case "Volume":
+			if row.Current != formatVolume(920) || row.Previous != formatVolume(500) {
+				t.Errorf("got volume %s against %s, want %s against %s", row.Current, row.Previous, formatVolume(920), formatVolume(500))
+			}
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"dumbbell/internal/model"
 	"dumbbell/internal/utils"
 	"errors"
+	"log"
+	"strings"
 	"time"
 )
 
@@ -20,7 +22,7 @@ var MONTH_NAMES = []string{
 	"Aug",
 	"Sep",
 	"Oct",
-	"Now",
+	"Nov",
 	"Dec",
 }
 
@@ -74,8 +76,9 @@ func (s *WorkoutService) GetActiveWorkoutData(userId int64, workoutId int64) (mo
 	}, nil
 }
 
+// GetLatestWorkoutSets lists the last sets logged, in one query however long the history is.
 func (s *WorkoutService) GetLatestWorkoutSets(userId int64) (model.LatestWorkoutSetsModel, error) {
-	workoutSets, err := dto.GetAllWorkoutSets(userId, 10, s.DB)
+	workoutSets, err := dto.GetLatestWorkoutSets(userId, 10, s.DB)
 	if err != nil {
 		return model.LatestWorkoutSetsModel{}, err
 	}
@@ -86,14 +89,12 @@ func (s *WorkoutService) GetLatestWorkoutSets(userId int64) (model.LatestWorkout
 	}
 
 	for _, workoutSet := range workoutSets {
-		exercise, _ := dto.GetExercise(workoutSet.ExerciseID, s.DB)
-		workout, _ := dto.GetWorkout(userId, workoutSet.WorkoutID, s.DB)
-		split, _ := dto.GetSplit(userId, workout.SplitID, s.DB)
-
 		viewModel.Sets = append(viewModel.Sets, model.LatestWorkoutSetModel{
-			SplitName:    split.Name,
-			ExerciseName: exercise.Name,
+			SplitName:    workoutSet.SplitName,
+			ExerciseName: workoutSet.ExerciseName,
 			Status:       workoutSet.SetRating,
+			Weight:       workoutSet.Weight,
+			Reps:         workoutSet.Reps,
 		})
 
 		if workoutSet.SetRating == dto.SetCurrent {
@@ -105,19 +106,22 @@ func (s *WorkoutService) GetLatestWorkoutSets(userId int64) (model.LatestWorkout
 }
 
 func (s *WorkoutService) GetWorkoutActivity(userId int64) (model.WorkoutActivityModel, error) {
-	workouts, err := dto.GetAllCompletedWorkouts(userId, s.DB)
+	location := GetUserLocation(userId, s.DB)
+	now := time.Now().In(location)
+	thisYear := now.Year()
+	lastYear := thisYear - 1
+
+	workouts, err := dto.GetCompletedWorkoutsSince(userId, time.Date(lastYear, time.January, 1, 0, 0, 0, 0, location), s.DB)
 	if err != nil {
 		return model.WorkoutActivityModel{}, err
 	}
 
 	months := make([]model.WorkoutActivityMonthModel, 12, 12)
 
-	thisYear := time.Now().Year()
-	lastYear := thisYear - 1
-
 	for _, workout := range workouts {
-		month := workout.StartedAt.Month() - 1
-		year := workout.StartedAt.Year()
+		startedAt := workout.StartedAt.In(location)
+		month := startedAt.Month() - 1
+		year := startedAt.Year()
 
 		if year >= lastYear {
 			if year == lastYear {
@@ -132,8 +136,8 @@ func (s *WorkoutService) GetWorkoutActivity(userId int64) (model.WorkoutActivity
 		months[i].Month = MONTH_NAMES[i]
 	}
 
-	thisYearMonthCount := months[time.Now().Month()-1].ThisYearActivity
-	lastYearMonthCount := months[time.Now().Month()-1].LastYearActivity
+	thisYearMonthCount := months[now.Month()-1].ThisYearActivity
+	lastYearMonthCount := months[now.Month()-1].LastYearActivity
 	monthAverageDiff := utils.Change(int(lastYearMonthCount), int(thisYearMonthCount))
 
 	return model.WorkoutActivityModel{
@@ -143,47 +147,233 @@ func (s *WorkoutService) GetWorkoutActivity(userId int64) (model.WorkoutActivity
 	}, nil
 }
 
+// GetWorkoutSplits counts the good and bad sets of every exercise in the splits, the counting is done by
+// the database rather than by loading every set ever logged.
 func (s *WorkoutService) GetWorkoutSplits(userId int64) ([]model.WorkoutSplitModel, error) {
+	ratings, err := dto.GetSplitExerciseRatings(userId, s.DB)
+	if err != nil {
+		return nil, err
+	}
+
 	splitModels := []model.WorkoutSplitModel{}
-	splits, _ := dto.GetSplits(userId, s.DB)
+	for _, rating := range ratings {
+		if len(splitModels) == 0 || splitModels[len(splitModels)-1].ID != rating.SplitID {
+			splitModels = append(splitModels, model.WorkoutSplitModel{
+				ID:        rating.SplitID,
+				SplitName: rating.SplitName,
+				Exercises: []model.WorkoutSplitExerciseModel{},
+			})
+		}
+		if rating.ExerciseID == 0 {
+			continue
+		}
 
-	for _, split := range splits {
-		splitModel := model.WorkoutSplitModel{
-			ID:               split.ID,
-			SplitName:        split.Name,
-			TotalGoodRatings: 0,
-			TotalBadRatings:  0,
-			Exercises:        []model.WorkoutSplitExerciseModel{},
+		splitModel := &splitModels[len(splitModels)-1]
+		splitModel.TotalGoodRatings += rating.Good
+		splitModel.TotalBadRatings += rating.Bad
+		splitModel.Exercises = append(splitModel.Exercises, model.WorkoutSplitExerciseModel{
+			ID:           rating.ExerciseID,
+			ExerciseName: rating.ExerciseName,
+			GoodRatings:  rating.Good,
+			BadRatings:   rating.Bad,
+		})
+	}
+
+	return splitModels, nil
+}
+
+// GetExerciseViewModel shows the exercise of the active set, the log starts out with what was logged in
+// the set when it was opened again by an undo, or with the last set of the exercise in the workout or with
+// its targets.
+func (s *WorkoutService) GetExerciseViewModel(activeWorkoutSet dto.WorkoutSet, htmx bool) (model.ExerciseViewModel, error) {
+	exercise, err := dto.GetWorkoutExercise(activeWorkoutSet.WorkoutID, activeWorkoutSet.ExerciseID, s.DB)
+	if err != nil {
+		return model.ExerciseViewModel{}, err
+	}
+
+	exercises, err := dto.GetAllExercises(exercise.SplitID, s.DB)
+	if err != nil {
+		return model.ExerciseViewModel{}, err
+	}
+
+	sets, err := s.GetExerciseSetsModel(activeWorkoutSet, htmx)
+	if err != nil {
+		return model.ExerciseViewModel{}, err
+	}
+
+	completedSets, err := dto.GetCompletedWorkoutSets(activeWorkoutSet.WorkoutID, exercise.ID, s.DB)
+	if err != nil {
+		return model.ExerciseViewModel{}, err
+	}
+
+	weight := exercise.WeightFrom
+	reps := int64(exercise.RepsFrom)
+	seconds := exercise.SecondsFrom
+	distance := exercise.DistanceFrom
+	if len(completedSets) > 0 {
+		lastSet := completedSets[len(completedSets)-1]
+		weight = lastSet.Weight
+		reps = lastSet.Reps
+		seconds = lastSet.Seconds
+		distance = lastSet.Distance
+	}
+	if activeWorkoutSet.Weight > 0 || activeWorkoutSet.Reps > 0 || activeWorkoutSet.Seconds > 0 || activeWorkoutSet.Distance > 0 {
+		weight = activeWorkoutSet.Weight
+		reps = activeWorkoutSet.Reps
+		seconds = activeWorkoutSet.Seconds
+		distance = activeWorkoutSet.Distance
+	}
+
+	return model.ExerciseViewModel{
+		Name:         exercise.Name,
+		Group:        GetExerciseGroupTitle(exercises, exercise),
+		WorkoutID:    activeWorkoutSet.WorkoutID,
+		Description:  exercise.Description,
+		ImageSrc:     exercise.GetImageURL(),
+		Measurement:  exercise.Measurement,
+		WeightFrom:   exercise.WeightFrom,
+		WeightTo:     exercise.WeightTo,
+		RepsFrom:     exercise.RepsFrom,
+		RepsTo:       exercise.RepsTo,
+		SecondsFrom:  exercise.SecondsFrom,
+		SecondsTo:    exercise.SecondsTo,
+		DistanceFrom: exercise.DistanceFrom,
+		DistanceTo:   exercise.DistanceTo,
+		Weight:       weight,
+		Reps:         reps,
+		Seconds:      seconds,
+		Distance:     distance,
+		Sets:         sets,
+		Progression:  s.GetProgressionModel(exercise.SplitID, exercise.ID),
+	}, nil
+}
+
+// GetExerciseSetsModel lays out the sets of the active exercise. The sets of a superset or circuit are
+// combined, in the order its exercises take turns and labeled with the exercise they are for. The remaining
+// sets of skipped exercises are left out.
+func (s *WorkoutService) GetExerciseSetsModel(activeWorkoutSet dto.WorkoutSet, htmx bool) (model.ExerciseSetsModel, error) {
+	exercise, err := dto.GetWorkoutExercise(activeWorkoutSet.WorkoutID, activeWorkoutSet.ExerciseID, s.DB)
+	if err != nil {
+		return model.ExerciseSetsModel{}, err
+	}
+
+	exercises, err := dto.GetAllExercises(exercise.SplitID, s.DB)
+	if err != nil {
+		return model.ExerciseSetsModel{}, err
+	}
+
+	workoutSets, err := dto.GetWorkoutSets(activeWorkoutSet.WorkoutID, s.DB)
+	if err != nil {
+		return model.ExerciseSetsModel{}, err
+	}
+
+	skipped, err := dto.GetSkippedExercises(activeWorkoutSet.WorkoutID, s.DB)
+	if err != nil {
+		return model.ExerciseSetsModel{}, err
+	}
+
+	canUndo, err := dto.CanUndo(activeWorkoutSet.WorkoutID, s.DB)
+	if err != nil {
+		return model.ExerciseSetsModel{}, err
+	}
+
+	beatenRecords, err := s.GetBeatenRecords(activeWorkoutSet.WorkoutID)
+	if err != nil {
+		return model.ExerciseSetsModel{}, err
+	}
+
+	group := dto.GroupTurnOrder(dto.GroupExercises(exercises, exercise), workoutSets)
+	labels := GetExerciseGroupLabels(exercises)
+
+	measurements := map[int64]dto.Measurement{}
+	for _, member := range exercises {
+		measurements[member.ID] = member.Measurement
+	}
+
+	current := 0
+	inGroup := map[int64]bool{}
+	for i, member := range group {
+		inGroup[member.ID] = true
+		if member.ID == exercise.ID {
+			current = i
 		}
+	}
 
-		exercises, _ := dto.GetAllExercises(split.ID, s.DB)
+	setCounts := map[int64]int64{}
+	sets := []model.ExerciseSetModel{}
+	for _, workoutSet := range workoutSets {
+		if !inGroup[workoutSet.ExerciseID] {
+			continue
+		}
 
-		for _, exercise := range exercises {
-			workoutSets, _ := dto.GetAllWorkoutSetsForExercise(exercise.ID, s.DB)
-			splitExerciseModel := model.WorkoutSplitExerciseModel{
-				ID:           exercise.ID,
-				ExerciseName: exercise.Name,
-				GoodRatings:  0,
-				BadRatings:   0,
-			}
+		if workoutSet.SetType.CountsAsSet() {
+			setCounts[workoutSet.ExerciseID]++
+		}
 
-			for _, workoutSet := range workoutSets {
-				if workoutSet.SetRating == dto.SetGood {
-					splitExerciseModel.GoodRatings++
-				} else if workoutSet.SetRating == dto.SetBad {
-					splitExerciseModel.BadRatings++
-				}
-			}
+		records := []string{}
+		for _, record := range beatenRecords[workoutSet.ExerciseID][workoutSet.SetNumber] {
+			records = append(records, record.Type.Name())
+		}
+
+		sets = append(sets, model.ExerciseSetModel{
+			Status: workoutSet.SetRating,
+			Type:   workoutSet.SetType,
+			Result: FormatSetResult(
+				measurements[workoutSet.ExerciseID],
+				workoutSet.Weight,
+				workoutSet.Reps,
+				workoutSet.Seconds,
+				workoutSet.Distance,
+			),
+			Label:   labels[workoutSet.ExerciseID],
+			Records: strings.Join(records, ", "),
+		})
+	}
 
-			splitModel.TotalGoodRatings += splitExerciseModel.GoodRatings
-			splitModel.TotalBadRatings += splitExerciseModel.BadRatings
-			splitModel.Exercises = append(splitModel.Exercises, splitExerciseModel)
+	// The remaining sets follow the turns the workout will take.
+	for {
+		next := -1
+		for turn := 1; turn <= len(group); turn++ {
+			i := (current + turn) % len(group)
+			if !skipped[group[i].ID] && setCounts[group[i].ID] < group[i].Sets {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
 		}
 
-		splitModels = append(splitModels, splitModel)
+		setCounts[group[next].ID]++
+		sets = append(sets, model.ExerciseSetModel{
+			Status: dto.SetUncompleted,
+			Type:   dto.SetWorking,
+			Label:  labels[group[next].ID],
+		})
+		current = next
 	}
 
-	return splitModels, nil
+	return model.ExerciseSetsModel{
+		WorkoutID:  activeWorkoutSet.WorkoutID,
+		Items:      sets,
+		CanUndo:    canUndo,
+		Rest:       GetRestTimerModel(activeWorkoutSet, htmx),
+		ActiveType: activeWorkoutSet.SetType,
+		Htmx:       htmx,
+	}, nil
+}
+
+// GetRestTimerModel counts down the rest before the active set, the end time comes from the set so the
+// timer keeps going when the page is reloaded.
+func GetRestTimerModel(activeWorkoutSet dto.WorkoutSet, htmx bool) model.RestTimerModel {
+	endsAt := activeWorkoutSet.RestEndsAt.Time
+	return model.RestTimerModel{
+		WorkoutID: activeWorkoutSet.WorkoutID,
+		Resting:   activeWorkoutSet.IsResting(),
+		EndsAt:    endsAt.UnixMilli(),
+		Remaining: utils.FmtDuration(max(time.Until(endsAt), 0)),
+		Htmx:      htmx,
+	}
 }
 
 func (s *WorkoutService) GetAvailableExercises(splitId int64, workoutId int64) ([]model.CardViewModel, error) {
@@ -192,6 +382,7 @@ func (s *WorkoutService) GetAvailableExercises(splitId int64, workoutId int64) (
 		return nil, err
 	}
 
+	labels := GetExerciseGroupLabels(exercises)
 	cards := []model.CardViewModel{}
 	for _, exercise := range exercises {
 		cards = append(cards, model.CardViewModel{
@@ -201,6 +392,7 @@ func (s *WorkoutService) GetAvailableExercises(splitId int64, workoutId int64) (
 			WorkoutID:   workoutId,
 			ImageSrc:    exercise.GetImageURL(),
 			Disabled:    exercise.HasWorkoutSet,
+			Group:       labels[exercise.ID],
 		})
 	}
 
@@ -225,8 +417,27 @@ func (s *WorkoutService) GetSplitCards(userId int64) ([]model.CardViewModel, err
 	return cards, nil
 }
 
-func GetWorkoutMetaData(workout dto.Workout) model.WorkoutMetadataModel {
-	workoutStartString := workout.StartedAt.Format("15:04 2006-01-02")
+// CompleteWorkout marks the workout as completed and runs the progression engine for its exercises. A set
+// that was started but not logged is dropped, the logged sets are kept when a workout is finished early.
+func (s *WorkoutService) CompleteWorkout(userId int64, workoutId int64) error {
+	if err := dto.DeleteActiveWorkoutSet(workoutId, s.DB); err != nil {
+		return err
+	}
+
+	if err := dto.CompleteWorkout(workoutId, s.DB); err != nil {
+		return err
+	}
+
+	if err := s.EvaluateProgression(userId, workoutId); err != nil {
+		log.Printf("Error evaluating progression: %s", err.Error())
+	}
+
+	return nil
+}
+
+// GetWorkoutMetaData describes when a workout started, with the start shown in the time zone of the user.
+func GetWorkoutMetaData(workout dto.Workout, location *time.Location) model.WorkoutMetadataModel {
+	workoutStartString := workout.StartedAt.In(location).Format("15:04 2006-01-02")
 	workoutDuration := time.Now().Sub(workout.StartedAt)
 	workoutDurationString := utils.FmtDuration(workoutDuration)
 	startedAt := workout.StartedAt.UnixMilli()
@@ -249,14 +460,14 @@ func (s *WorkoutService) GetPickExerciseModel(userId int64, workoutId int64) (mo
 		return model.PickExerciseModel{}, err
 	}
 
-	hasRemainingExercises := false
-	for _, exercise := range exercises {
-		if !exercise.Disabled {
-			hasRemainingExercises = true
+	var nextExercise *model.CardViewModel
+	for i := range exercises {
+		if !exercises[i].Disabled {
+			nextExercise = &exercises[i]
 			break
 		}
 	}
-	if !hasRemainingExercises {
+	if nextExercise == nil {
 		return model.PickExerciseModel{}, ErrorNoExercises
 	}
 
@@ -265,13 +476,20 @@ func (s *WorkoutService) GetPickExerciseModel(userId int64, workoutId int64) (mo
 		return model.PickExerciseModel{}, err
 	}
 
-	metadata := GetWorkoutMetaData(workout)
+	canUndo, err := dto.CanUndo(workout.ID, s.DB)
+	if err != nil {
+		return model.PickExerciseModel{}, err
+	}
+
+	metadata := GetWorkoutMetaData(workout, GetUserLocation(userId, s.DB))
 	return model.PickExerciseModel{
 		Title:            "Dumbell - Workout",
 		Exercises:        exercises,
+		NextExercise:     *nextExercise,
 		ActiveWorkout:    activeWorkoutData,
 		WorkoutStart:     metadata.WorkoutStart,
 		WorkoutDuration:  metadata.WorkoutDuration,
 		WorkoutStartedAt: metadata.WorkoutStartedAt,
+		CanUndo:          canUndo,
 	}, nil
 }
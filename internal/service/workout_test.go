@@ -0,0 +1,167 @@
+package service
+
+import (
+	"database/sql"
+	"dumbbell/internal/dto"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+const (
+	seedSplits            = 4
+	seedExercisesPerSplit = 6
+	seedSetsPerExercise   = 4
+	// Days between the seeded workouts, going back from now.
+	seedWorkoutInterval = 2
+)
+
+// seedHistory creates a user with a few splits of exercises tagged with muscle groups and a history of the
+// given number of completed workouts, each with a warm-up and working sets of every exercise of its split.
+func seedHistory(tb testing.TB, workoutCount int, db *sql.DB) int64 {
+	tb.Helper()
+
+	user, err := dto.CreateUser(fmt.Sprintf("history-%d@example.com", workoutCount), "password", db)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	image, err := dto.CreateImage(dto.ImageTypeJpeg, []byte{}, db)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	splitExercises := map[int64][]int64{}
+	splitIds := []int64{}
+	for i := 0; i < seedSplits; i++ {
+		split, err := dto.CreateSplit(user.ID, fmt.Sprintf("Split %d", i+1), "", db)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		splitIds = append(splitIds, split.ID)
+
+		for j := 0; j < seedExercisesPerSplit; j++ {
+			exercise, err := dto.CreateExercise(user.ID, &image.ID, fmt.Sprintf("Exercise %d-%d", i+1, j+1), "", "", dto.MeasurementWeightReps, db)
+			if err != nil {
+				tb.Fatal(err)
+			}
+			if _, err = dto.AddSplitExercise(user.ID, split.ID, exercise.ID, 50, 60, 6, 10, 0, 0, 0, 0, seedSetsPerExercise, 90, db); err != nil {
+				tb.Fatal(err)
+			}
+
+			primary := dto.MuscleGroups[(i*seedExercisesPerSplit+j)%len(dto.MuscleGroups)]
+			secondary := dto.MuscleGroups[(i*seedExercisesPerSplit+j+1)%len(dto.MuscleGroups)]
+			if err = dto.SetExerciseMuscleGroups(user.ID, exercise.ID, []dto.MuscleGroup{primary}, []dto.MuscleGroup{secondary}, db); err != nil {
+				tb.Fatal(err)
+			}
+			splitExercises[split.ID] = append(splitExercises[split.ID], exercise.ID)
+		}
+	}
+
+	random := rand.New(rand.NewSource(1))
+	start := time.Now().AddDate(0, 0, -(workoutCount-1)*seedWorkoutInterval)
+	workouts := []dto.ImportedWorkout{}
+	for i := 0; i < workoutCount; i++ {
+		splitId := splitIds[i%len(splitIds)]
+		startedAt := start.AddDate(0, 0, i*seedWorkoutInterval)
+		workout := dto.ImportedWorkout{
+			Workout: dto.Workout{
+				SplitID:     splitId,
+				StartedAt:   startedAt,
+				CompletedAt: sql.NullTime{Time: startedAt.Add(time.Hour), Valid: true},
+			},
+			Sets: []dto.WorkoutSet{},
+		}
+
+		for position, exerciseId := range splitExercises[splitId] {
+			for setNumber := 1; setNumber <= seedSetsPerExercise; setNumber++ {
+				completedAt := startedAt.Add(time.Duration(position*seedSetsPerExercise+setNumber) * 2 * time.Minute)
+				workoutSet := dto.WorkoutSet{
+					SetNumber:   int64(setNumber),
+					ExerciseID:  exerciseId,
+					StartedAt:   completedAt.Add(-time.Minute),
+					CompletedAt: sql.NullTime{Time: completedAt, Valid: true},
+					SetRating:   dto.SetGood,
+					SetType:     dto.SetWorking,
+					Weight:      50 + float64(i/10)*2.5,
+					Reps:        int64(6 + random.Intn(5)),
+				}
+				if setNumber == 1 {
+					workoutSet.SetType = dto.SetWarmup
+					workoutSet.Weight /= 2
+				} else if random.Intn(4) == 0 {
+					workoutSet.SetRating = dto.SetBad
+				}
+				workout.Sets = append(workout.Sets, workoutSet)
+			}
+		}
+
+		workouts = append(workouts, workout)
+	}
+
+	if err = dto.ImportWorkouts(user.ID, dto.ImportedRows{}, workouts, db); err != nil {
+		tb.Fatal(err)
+	}
+
+	return user.ID
+}
+
+// loadDashboard loads everything the dashboard shows for the user, like the home page does.
+func loadDashboard(tb testing.TB, workoutService *WorkoutService, userId int64) {
+	if _, err := workoutService.GetSplitCards(userId); err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := workoutService.GetLatestWorkoutSets(userId); err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := workoutService.GetTrainingCalendar(userId, 0); err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := workoutService.GetMuscleBalanceModel(userId); err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := workoutService.GetWorkoutSplits(userId); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// BenchmarkDashboard loads the dashboard for histories of growing size, it is run with
+// go test -bench Dashboard ./internal/service
+func BenchmarkDashboard(b *testing.B) {
+	database := newTestDB(b)
+	workoutService := NewWorkoutService(database)
+
+	for _, workoutCount := range []int{100, 1000, 5000} {
+		userId := seedHistory(b, workoutCount, database)
+
+		// The whole history is on the dashboard before it is timed.
+		calendar, err := workoutService.GetTrainingCalendar(userId, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if calendar.CurrentStreak == 0 || calendar.LongestStreak == 0 {
+			b.Fatalf("%d workouts: got a current streak of %d and a longest of %d weeks", workoutCount, calendar.CurrentStreak, calendar.LongestStreak)
+		}
+		workoutSplits, err := workoutService.GetWorkoutSplits(userId)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(workoutSplits) != seedSplits {
+			b.Fatalf("%d workouts: got %d splits, want %d", workoutCount, len(workoutSplits), seedSplits)
+		}
+		muscleBalance, err := workoutService.GetMuscleBalanceModel(userId)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if !muscleBalance.HasMuscleGroups {
+			b.Fatalf("%d workouts: the muscle groups are missing from the dashboard", workoutCount)
+		}
+
+		b.Run(fmt.Sprintf("workouts=%d", workoutCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				loadDashboard(b, workoutService, userId)
+			}
+		})
+	}
+}
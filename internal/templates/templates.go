@@ -1,10 +1,14 @@
 package templates
 
 import (
+	"dumbbell/internal/dto"
 	"dumbbell/internal/environment"
+	"dumbbell/internal/utils"
+	files "dumbbell/templates"
 	"html/template"
 	"io"
 	"reflect"
+	"time"
 )
 
 var templateFunctions = template.FuncMap{
@@ -17,11 +21,20 @@ var templateFunctions = template.FuncMap{
 	"isDev": func() bool {
 		return environment.GetEnvironment() == environment.Development
 	},
+	"setTypes": func() []dto.SetType {
+		return dto.SetTypes
+	},
+	"measurements": func() []dto.Measurement {
+		return dto.Measurements
+	},
+	"formatSeconds": func(seconds int64) string {
+		return utils.FmtDuration(time.Duration(seconds) * time.Second)
+	},
 }
 
-var Page = template.Must(template.Must(template.New("pageTemplates").Funcs(templateFunctions).ParseGlob("templates/pages/*.html")).ParseGlob("templates/partials/*.html"))
-var Htmx = template.Must(template.Must(template.New("htmxTemplates").Funcs(templateFunctions).ParseGlob("templates/htmx/*.html")).ParseGlob("templates/partials/*.html"))
-var Partials = template.Must(template.New("partials").Funcs(templateFunctions).ParseGlob("templates/partials/*.html"))
+var Page = template.Must(template.New("pageTemplates").Funcs(templateFunctions).ParseFS(files.FS, "pages/*.html", "partials/*.html"))
+var Htmx = template.Must(template.New("htmxTemplates").Funcs(templateFunctions).ParseFS(files.FS, "htmx/*.html", "partials/*.html"))
+var Partials = template.Must(template.New("partials").Funcs(templateFunctions).ParseFS(files.FS, "partials/*.html"))
 var StartWorkout = template.Must(Partials.New("startWorkout").Parse(`
 	<title>{{ .Title }}</title>
 	<div hx-swap-oob="delete:#page-header"></div>
@@ -35,6 +48,15 @@ var PickWorkout = template.Must(Partials.New("pickWorkout").Parse(`
 `))
 var NextExercise = template.Must(Partials.New("nextExercise").Parse(`
 	{{ template "exerciseSets" . }}
+	{{ template "exerciseRest" .Rest }}
+	{{ template "exerciseSetType" . }}
+	{{ template "exerciseActions" . }}
+`))
+var RestTimer = template.Must(Partials.New("restTimer").Parse(`
+	{{ template "exerciseRest" . }}
+`))
+var ExerciseTargets = template.Must(Partials.New("exerciseTargetsResponse").Parse(`
+	{{ template "exerciseTargets" . }}
 `))
 var Home = template.Must(Partials.New("home").Parse(`
 	<title>{{ .Title }}</title>
@@ -52,16 +74,89 @@ var Signup = template.Must(Partials.New("signup").Parse(`
 	<div hx-swap-oob="delete:#page-header"></div>
 	{{ template "signupContainer" . }}
 `))
+var ForgotPassword = template.Must(Partials.New("forgotPassword").Parse(`
+	<title>{{ .Title }}</title>
+	<div hx-swap-oob="delete:#page-header"></div>
+	{{ template "forgotPasswordContainer" . }}
+`))
+var ResetPassword = template.Must(Partials.New("resetPassword").Parse(`
+	<title>{{ .Title }}</title>
+	<div hx-swap-oob="delete:#page-header"></div>
+	{{ template "resetPasswordContainer" . }}
+`))
 var Settings = template.Must(Partials.New("settings").Parse(`
 	<title>{{ .Title }}</title>
 	<div hx-swap-oob="delete:#page-header"></div>
 	<div hx-swap-oob="afterbegin:body">{{ template "header" .Header }}</div>
 	{{ template "settingsContainer" . }}
 `))
+var History = template.Must(Partials.New("history").Parse(`
+	<title>{{ .Title }}</title>
+	<div hx-swap-oob="delete:#page-header"></div>
+	<div hx-swap-oob="afterbegin:body">{{ template "header" .Header }}</div>
+	{{ template "historyContainer" . }}
+`))
+var HistoryWorkout = template.Must(Partials.New("historyWorkout").Parse(`
+	<title>{{ .Title }}</title>
+	<div hx-swap-oob="delete:#page-header"></div>
+	<div hx-swap-oob="afterbegin:body">{{ template "header" .Header }}</div>
+	{{ template "historyWorkoutContainer" . }}
+`))
+var WorkoutSummary = template.Must(Partials.New("workoutSummary").Parse(`
+	<title>{{ .Title }}</title>
+	<div hx-swap-oob="delete:#page-header"></div>
+	<div hx-swap-oob="afterbegin:body">{{ template "header" .Header }}</div>
+	{{ template "workoutSummaryContainer" . }}
+`))
+var ExerciseProgress = template.Must(Partials.New("exerciseProgress").Parse(`
+	<title>{{ .Title }}</title>
+	<div hx-swap-oob="delete:#page-header"></div>
+	<div hx-swap-oob="afterbegin:body">{{ template "header" .Header }}</div>
+	{{ template "exerciseProgressContainer" . }}
+`))
+var PersonalRecords = template.Must(Partials.New("personalRecords").Parse(`
+	<title>{{ .Title }}</title>
+	<div hx-swap-oob="delete:#page-header"></div>
+	<div hx-swap-oob="afterbegin:body">{{ template "header" .Header }}</div>
+	{{ template "personalRecordsContainer" . }}
+`))
+var MuscleReport = template.Must(Partials.New("muscleReport").Parse(`
+	<title>{{ .Title }}</title>
+	<div hx-swap-oob="delete:#page-header"></div>
+	<div hx-swap-oob="afterbegin:body">{{ template "header" .Header }}</div>
+	{{ template "muscleReportContainer" . }}
+`))
+var MuscleReportContainer = template.Must(Partials.New("muscleReportContainerResponse").Parse(`
+	{{ template "muscleReportContainer" . }}
+`))
+var HistorySetEdit = template.Must(Partials.New("historySetEditResponse").Parse(`
+	{{ template "historySetEditRow" . }}
+`))
+var ApiTokenCreated = template.Must(Partials.New("apiTokenCreatedResponse").Parse(`
+	{{ template "apiTokenCreated" . }}
+`))
+var CsvImportResult = template.Must(Partials.New("csvImportResultResponse").Parse(`
+	{{ template "csvImportResult" . }}
+`))
+var LibraryExerciseRow = template.Must(Partials.New("libraryExerciseRowResponse").Parse(`
+	{{ if .IsNew }}
+		<table hx-swap-oob="beforeend:#library-exercise-rows">
+			<tbody>
+				{{ template "libraryExerciseRow" . }}
+			</tbody>
+		</table>
+	{{ else }}
+		{{ template "libraryExerciseRow" . }}
+	{{ end }}
+`))
 var AlertBanner = template.Must(Partials.New("userCredentialsError").Parse(`
 	{{ template "alertBanner" . }}
 `))
 
+var SuccessBanner = template.Must(Partials.New("successMessage").Parse(`
+	{{ template "successBanner" . }}
+`))
+
 func ExecutePageTemplate(wr io.Writer, templateName string, data any) error {
 	return Page.ExecuteTemplate(wr, templateName, data)
 }
@@ -5,9 +5,40 @@ import (
 	"log"
 	"math"
 	"strconv"
+	"sync"
 	"time"
 )
 
+var locations sync.Map
+
+// LoadLocation returns the time zone with the IANA name, loaded once and cached after that. An empty or
+// unknown name is the time zone of the server.
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location)
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("LoadLocation Error: %s", err.Error())
+		return time.Local
+	}
+	locations.Store(name, location)
+	return location
+}
+
+// IsValidTimezone reports whether name is an IANA time zone, "Local" depends on the server and is not one.
+func IsValidTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
 func MustParseInt64(valueString string) int64 {
 	value, err := strconv.ParseInt(valueString, 10, 64)
 	if err != nil {
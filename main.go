@@ -1,10 +1,17 @@
 package main
 
 import (
+	"dumbbell/internal/db"
+	"dumbbell/internal/dto"
 	"dumbbell/internal/server"
+	"dumbbell/internal/service"
+	"dumbbell/internal/utils"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"text/tabwriter"
+	_ "time/tzdata"
 )
 
 var Version = "0.0.1"
@@ -18,9 +25,175 @@ func main() {
 		return
 	}
 
+	if flag.Arg(0) == "migrate" {
+		if err := migrate(flag.Arg(1)); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	if flag.Arg(0) == "import-csv" {
+		if err := importCsv(flag.Args()[1:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	if flag.Arg(0) == "rebuild-records" {
+		if err := rebuildRecords(flag.Args()[1:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	srv, err := server.NewServer()
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 	log.Fatal(srv.ListenAndServe())
 }
+
+func migrate(direction string) error {
+	database, err := db.Open()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	switch direction {
+	case "up":
+		migrated, err := db.MigrateUp(database)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", len(migrated))
+	case "down":
+		migration, err := db.MigrateDown(database)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back %04d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := db.GetMigrationStatus(database)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt.Valid {
+				appliedAt = status.AppliedAt.Time.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return writer.Flush()
+	default:
+		return fmt.Errorf("Usage: dumbbell migrate up|down|status")
+	}
+
+	return nil
+}
+
+func rebuildRecords(args []string) error {
+	flags := flag.NewFlagSet("rebuild-records", flag.ExitOnError)
+	email := flags.String("user", "", "email of the user to rebuild the personal records of")
+	flags.Parse(args)
+
+	if *email == "" {
+		return fmt.Errorf("Usage: dumbbell rebuild-records -user email")
+	}
+
+	database, err := db.NewDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	user, err := dto.GetUserByEmail(*email, database)
+	if err != nil {
+		return fmt.Errorf("No user with email %s", *email)
+	}
+
+	if err = service.NewWorkoutService(database).UpdateAllPersonalRecords(user.ID); err != nil {
+		return err
+	}
+	fmt.Printf("Rebuilt the personal records of %s\n", *email)
+	return nil
+}
+
+func importCsv(args []string) error {
+	flags := flag.NewFlagSet("import-csv", flag.ExitOnError)
+	email := flags.String("user", "", "email of the user to import the workouts for")
+	dryRun := flags.Bool("dry-run", false, "only print what would be imported")
+	timezone := flags.String("timezone", "", "time zone of the times in the file, the time zone of the user when empty")
+	flags.Parse(args)
+
+	if *email == "" || flags.NArg() != 1 {
+		return fmt.Errorf("Usage: dumbbell import-csv -user email [-dry-run] [-timezone zone] file.csv")
+	}
+
+	if *timezone != "" && !utils.IsValidTimezone(*timezone) {
+		return fmt.Errorf("Unknown time zone %s", *timezone)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	database, err := db.NewDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	user, err := dto.GetUserByEmail(*email, database)
+	if err != nil {
+		return fmt.Errorf("No user with email %s", *email)
+	}
+
+	location := user.Location()
+	if *timezone != "" {
+		location = utils.LoadLocation(*timezone)
+	}
+
+	result, err := service.NewExportService(database).ImportCSV(user.ID, file, location, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "DATE\tWORKOUT\tSPLIT\tEXERCISES\tSETS")
+	for _, workout := range result.Workouts {
+		split := workout.SplitName
+		if workout.Duplicate {
+			split = "already imported"
+		} else if workout.NewSplit {
+			split += " (new)"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%d\n", workout.Date, workout.Name, split, workout.ExerciseCount, workout.SetCount)
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	for _, name := range result.NewSplits {
+		fmt.Printf("New split: %s\n", name)
+	}
+	for _, name := range result.NewExercises {
+		fmt.Printf("New exercise: %s\n", name)
+	}
+	for _, rowError := range result.Errors {
+		fmt.Printf("Line %d: %s\n", rowError.Line, rowError.Message)
+	}
+
+	if result.DryRun {
+		fmt.Printf("Dry run, %d workout(s) would be imported, %d already imported\n", len(result.Workouts)-result.DuplicateCount, result.DuplicateCount)
+	} else {
+		fmt.Printf("Imported %d workout(s), skipped %d already imported\n", result.ImportedCount, result.DuplicateCount)
+	}
+	return nil
+}
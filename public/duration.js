@@ -1,6 +1,35 @@
 const SECOND = 1000;
 const MINUTE = 60;
 const HOUR = MINUTE * 60;
+
+/**
+ * Formats a duration in seconds like utils.FmtDuration on the server.
+ *
+ * @param {number} duration
+ * @returns {string}
+ */
+function formatDuration(duration) {
+  const hours = Math.floor(duration / HOUR);
+  duration -= hours * HOUR;
+
+  const minutes = Math.floor(duration / MINUTE) % MINUTE;
+  duration -= minutes * MINUTE;
+
+  const seconds = Math.floor(duration % MINUTE);
+
+  let formattedDuration = "";
+  if (hours > 0) {
+    formattedDuration += `${hours.toString().padStart(2, "0")}h `;
+  }
+
+  if (minutes > 0) {
+    formattedDuration += `${minutes.toString().padStart(2, "0")}m `;
+  }
+  formattedDuration += `${seconds.toString().padStart(2, "0")}s`;
+
+  return formattedDuration;
+}
+
 /**
  *
  * @param {string} elementSelector
@@ -16,27 +45,9 @@ function initDurationCounter(elementSelector) {
 
     const durationStart = counterElement.dataset.start;
     const updateWorkoutDuration = () => {
-      let duration = (Date.now() - durationStart) / SECOND;
-
-      const hours = Math.floor(duration / HOUR);
-      duration -= hours * HOUR;
-
-      const minutes = Math.floor(duration / MINUTE) % MINUTE;
-      duration -= minutes * MINUTE;
-
-      const seconds = Math.floor(duration % MINUTE);
-
-      let formattedDuration = "";
-      if (hours > 0) {
-        formattedDuration += `${hours.toString().padStart(2, "0")}h `;
-      }
-
-      if (minutes > 0) {
-        formattedDuration += `${minutes.toString().padStart(2, "0")}m `;
-      }
-      formattedDuration += `${seconds.toString().padStart(2, "0")}s`;
-
-      counterElement.textContent = formattedDuration;
+      counterElement.textContent = formatDuration(
+        (Date.now() - durationStart) / SECOND,
+      );
     };
     const intervalId = setInterval(updateWorkoutDuration, SECOND);
 
@@ -0,0 +1,18 @@
+/**
+ * Shows the fields inside the container that are used for what an exercise
+ * is measured in. Every field has data-measurements with the measurements it
+ * is used by, the inputs of the other fields are disabled so they are not
+ * sent with the form.
+ *
+ * @param {HTMLElement} container
+ * @param {string} measurement
+ */
+function showMeasurementFields(container, measurement) {
+  for (const field of container.querySelectorAll("[data-measurements]")) {
+    const used = field.dataset.measurements.split(" ").includes(measurement);
+    field.classList.toggle("hidden", !used);
+    for (const input of field.querySelectorAll("input")) {
+      input.disabled = !used;
+    }
+  }
+}
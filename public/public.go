@@ -0,0 +1,9 @@
+// Package public holds the static files that the server also needs itself.
+package public
+
+import _ "embed"
+
+// PlaceholderImage is the image of exercises that are created without one.
+//
+//go:embed images/dumbbell.png
+var PlaceholderImage []byte
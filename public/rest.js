@@ -0,0 +1,41 @@
+/**
+ * Counts down every element with data-rest-ends-at, in unix milliseconds, and
+ * counts up again once the rest is over so the overtime is visible. The
+ * element gets data-rest-over when the countdown reaches zero.
+ *
+ * @param {HTMLElement} content
+ */
+function initRestTimer(content) {
+  const elements = Array.from(content.querySelectorAll("[data-rest-ends-at]"));
+  if (content.matches("[data-rest-ends-at]")) {
+    elements.push(content);
+  }
+
+  for (const element of elements) {
+    const endsAt = Number(element.dataset.restEndsAt);
+    const updateRestTimer = () => {
+      const remaining = (endsAt - Date.now()) / SECOND;
+      if (remaining > 0) {
+        element.textContent = formatDuration(Math.ceil(remaining));
+        return;
+      }
+
+      if (!("restOver" in element.dataset)) {
+        element.dataset.restOver = "";
+        if (navigator.vibrate) {
+          navigator.vibrate(200);
+        }
+      }
+      element.textContent = `+${formatDuration(-remaining)}`;
+    };
+
+    updateRestTimer();
+    const intervalId = setInterval(updateRestTimer, SECOND / 4);
+
+    element.addEventListener("htmx:beforeCleanupElement", function () {
+      clearInterval(intervalId);
+    });
+  }
+}
+
+htmx.onLoad(initRestTimer);
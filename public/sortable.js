@@ -0,0 +1,31 @@
+/**
+ * Makes the children of every element with data-sortable draggable by their
+ * data-sortable-handle. Sortable dispatches an "end" event on the element
+ * when a drag is dropped, which htmx can trigger on.
+ *
+ * @param {HTMLElement} content
+ */
+function initSortable(content) {
+  if (typeof Sortable === "undefined") {
+    console.warn("Sortable is not loaded");
+    return;
+  }
+
+  const elements = Array.from(content.querySelectorAll("[data-sortable]"));
+  if (content.matches("[data-sortable]")) {
+    elements.push(content);
+  }
+
+  for (const element of elements) {
+    if (element.sortable) {
+      continue;
+    }
+
+    element.sortable = new Sortable(element, {
+      handle: "[data-sortable-handle]",
+      animation: 150,
+    });
+  }
+}
+
+htmx.onLoad(initSortable);
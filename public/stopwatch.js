@@ -0,0 +1,44 @@
+/**
+ * Times a set with every button with data-stopwatch, a selector of the input
+ * that gets the time in seconds. The first click starts the stopwatch from
+ * zero and the second one stops it, the input can still be corrected by hand.
+ *
+ * @param {HTMLElement} content
+ */
+function initStopwatch(content) {
+  const buttons = Array.from(content.querySelectorAll("[data-stopwatch]"));
+  if (content.matches("[data-stopwatch]")) {
+    buttons.push(content);
+  }
+
+  for (const button of buttons) {
+    const input = document.querySelector(button.dataset.stopwatch);
+    let intervalId = null;
+
+    const stopStopwatch = () => {
+      clearInterval(intervalId);
+      intervalId = null;
+      button.textContent = "Start";
+    };
+
+    button.addEventListener("click", function () {
+      if (intervalId !== null) {
+        stopStopwatch();
+        return;
+      }
+
+      const startedAt = Date.now();
+      const updateStopwatch = () => {
+        input.value = Math.floor((Date.now() - startedAt) / SECOND);
+      };
+
+      button.textContent = "Stop";
+      updateStopwatch();
+      intervalId = setInterval(updateStopwatch, SECOND / 4);
+    });
+
+    button.addEventListener("htmx:beforeCleanupElement", stopStopwatch);
+  }
+}
+
+htmx.onLoad(initStopwatch);
@@ -2,8 +2,8 @@
 
 set -e
 
-echo "Create database"
-sqlite3 ./db/database.db < ./db/schema.sql
+echo "Migrate database"
+go run main.go migrate up
 
 echo "Set permissions"
-chmod a+rw ./db/database.db
\ No newline at end of file
+chmod a+rw ./db/database.db
@@ -14,4 +14,7 @@ echo "Seed images table"
 sqlite-utils insert database.db images seed/images.json --truncate
 
 echo "Seed exercises table"
-sqlite-utils insert database.db exercises seed/exercises.json --truncate
\ No newline at end of file
+sqlite-utils insert database.db exercises seed/exercises.json --truncate
+
+echo "Seed split exercises table"
+sqlite-utils insert database.db split_exercises seed/split_exercises.json --truncate
\ No newline at end of file
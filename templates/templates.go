@@ -0,0 +1,7 @@
+// Package templates holds the HTML templates of the pages, the htmx responses and the partials they share.
+package templates
+
+import "embed"
+
+//go:embed pages/*.html htmx/*.html partials/*.html
+var FS embed.FS